@@ -1,6 +1,15 @@
 package args
 
-import sdk_args "github.com/newrelic/infra-integrations-sdk/v3/args"
+import (
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	sdk_args "github.com/newrelic/infra-integrations-sdk/v3/args"
+)
 
 type ArgumentList struct {
 	sdk_args.DefaultArgumentList
@@ -16,7 +25,12 @@ type ArgumentList struct {
 	RemoteMonitoring                     bool   `default:"false" help:"Indicates if the monitored entity is remote. Set to true if unsure."`
 	ExtendedMetrics                      bool   `default:"false" help:"Enable collection of extended metrics."`
 	ExtendedInnodbMetrics                bool   `default:"false" help:"Enable collection of extended InnoDB metrics."`
-	ExtendedMyIsamMetrics                bool   `default:"false" help:"Enable collection of extended MyISAM metrics."`
+	ExtendedInnodbStatusMetrics          bool   `default:"false" help:"Enable collection of InnoDB engine status metrics, from SHOW ENGINE INNODB STATUS and information_schema.INNODB_METRICS."`
+	ExtendedMyIsamMetrics                bool   `default:"false" help:"Enable collection of extended MyISAM metrics (and Aria metrics on MariaDB)."`
+	ExtendedWsrepMetrics                 bool   `default:"false" help:"Enable collection of Galera (wsrep) cluster metrics. Enabled automatically when wsrep_on is set."`
+	CustomMetricsConfig                  string `default:"" help:"Path to a YAML file with custom queries whose results are reported as metrics."`
+	TargetsConfig                        string `default:"" help:"Path to a YAML file listing MySQL instances to monitor concurrently, each one with its own arguments."`
+	MaxConcurrentTargets                 int    `default:"10" help:"Maximum number of instances of the targets config monitored at the same time."`
 	OldPasswords                         bool   `default:"false" help:"Allow the use of old passwords: https://dev.mysql.com/doc/refman/5.6/en/server-system-variables.html#sysvar_old_passwords"`
 	ShowVersion                          bool   `default:"false" help:"Display build information and exit."`
 	EnableQueryMonitoring                bool   `default:"false" help:"Enable collection of detailed query performance metrics."`
@@ -24,4 +38,75 @@ type ArgumentList struct {
 	QueryMonitoringResponseTimeThreshold int    `default:"1" help:"Threshold in milliseconds for query response time to fetch individual query performance metrics."`
 	QueryMonitoringCountThreshold        int    `default:"20" help:"Query count limit for fetching grouped slow and individual query performance metrics."`
 	ExcludedPerformanceDatabases         string `default:"[]" help:"A JSON array that lists databases to be excluded from performance metrics collection. System databases are always excluded."`
+	ExcludedPerformanceUsers             string `default:"[]" help:"A JSON array that lists users to be excluded from account metrics collection. MySQL internal accounts are always excluded."`
+	QueryMonitoringRedactionPolicy       string `default:"none" help:"Redaction of the query text reported by query performance monitoring: none (report the text as it is), literals (replace every literal) or sensitive (mask emails, card numbers and the redacted columns)."`
+	QueryMonitoringRedactedColumns       string `default:"[]" help:"A JSON array of regular expressions matching the columns whose values are masked in the query text, e.g. [\"password\", \".*_token\"]."`
+	QueryMonitoringReportSampleText      bool   `default:"false" help:"Report the text of the individual query samples in query_sample_text. Its literals are always replaced, whatever QueryMonitoringRedactionPolicy."`
+	QueryMonitoringExplainStatementTypes string `default:"[\"SELECT\",\"WITH\"]" help:"A JSON array that lists the statement types whose execution plans are fetched, among SELECT, WITH, UPDATE, DELETE, INSERT and REPLACE. INSERT and REPLACE are only explained when they insert the result of a SELECT."`
+	QueryMonitoringAnalyzeDigests        string `default:"[]" help:"A JSON array of query digests (query_id) whose queries are executed with EXPLAIN ANALYZE, within a read-only transaction, to report their actual execution figures."`
+	QueryMonitoringAnalyzeTimeLimit      int    `default:"500" help:"Maximum execution time in milliseconds of each EXPLAIN ANALYZE statement."`
+	QueryMonitoringTrxAgeThreshold       int    `default:"60" help:"Threshold in seconds of the age of the open transactions reported as long-running."`
+	QueryMonitoringTrxIdleThreshold      int    `default:"30" help:"Threshold in seconds of the idle time of the sessions reported as idle in transaction."`
+	QueryMonitoringWaitEventsMode        string `default:"sample" help:"Source of the wait events: sample (the current and recent waits of the running statements) or delta (the time waited on each event during the interval, from the wait event summaries)."`
+}
+
+var camel = regexp.MustCompile("(^[^A-Z]*|[A-Z]*)([A-Z][^A-Z]+|$)")
+
+// argumentName returns the name of the flag of an argument, as the integration SDK defines it, e.g. enable_tls for EnableTLS.
+func argumentName(fieldName string) string {
+	var words []string
+	for _, sub := range camel.FindAllStringSubmatch(fieldName, -1) {
+		if sub[1] != "" {
+			words = append(words, sub[1])
+		}
+		if sub[2] != "" {
+			words = append(words, sub[2])
+		}
+	}
+	return strings.ToLower(strings.Join(words, "_"))
+}
+
+/*
+WithOverrides returns a copy of the arguments where the given values replace the ones of the arguments with the same name.
+Names are matched case-insensitively against the flag names, so the environment variable names used in the config file
+(e.g. HOSTNAME or ENABLE_TLS) are accepted. The SDK default arguments (verbose, metrics, inventory...) can't be overridden.
+*/
+func (args ArgumentList) WithOverrides(overrides map[string]string) (ArgumentList, error) {
+	overridden := args
+	flags := flag.NewFlagSet("overrides", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	fields := reflect.ValueOf(&overridden).Elem()
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Type().Field(i)
+		if field.Anonymous {
+			continue
+		}
+		name := argumentName(field.Name)
+		switch value := fields.Field(i).Addr().Interface().(type) {
+		case *string:
+			flags.StringVar(value, name, *value, "")
+		case *int:
+			flags.IntVar(value, name, *value, "")
+		case *bool:
+			flags.BoolVar(value, name, *value, "")
+		}
+	}
+
+	for name, value := range overrides {
+		if err := flags.Set(strings.ToLower(name), value); err != nil {
+			return args, fmt.Errorf("invalid value for %s: %w", name, err)
+		}
+	}
+	return overridden, nil
+}
+
+// SDKCommandLine returns the SDK default arguments as command line flags, e.g. -pretty=true, in the way the SDK parses them.
+func (args ArgumentList) SDKCommandLine() []string {
+	fields := reflect.ValueOf(args.DefaultArgumentList)
+	commandLine := make([]string, 0, fields.NumField())
+	for i := 0; i < fields.NumField(); i++ {
+		commandLine = append(commandLine, fmt.Sprintf("-%s=%v", argumentName(fields.Type().Field(i).Name), fields.Field(i).Interface()))
+	}
+	return commandLine
 }
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	infrautils "github.com/newrelic/nri-mysql/src/infrautils"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultCustomQuerySampleName = "MysqlCustomQuerySample"
+
+/*
+customMetricsConfig is the content of the file given in CUSTOM_METRICS_CONFIG, e.g.:
+
+	queries:
+	  - query: SELECT queue, COUNT(*) AS pending, SUM(retries) AS retries FROM jobs GROUP BY queue
+	    database: app
+	    sample_name: MysqlJobQueueSample
+	    identifying_columns: [queue]
+	    columns:
+	      - name: pending
+	        metric_name: jobs.pending
+	        metric_type: gauge
+	      - name: retries
+	        metric_name: jobs.retriesPerSecond
+	        metric_type: prate
+*/
+type customMetricsConfig struct {
+	Queries []customQuery `yaml:"queries"`
+}
+
+// customQuery is a SQL statement whose result rows are reported as samples of its own event type.
+type customQuery struct {
+	Query              string         `yaml:"query"`
+	Database           string         `yaml:"database"`
+	SampleName         string         `yaml:"sample_name"`
+	IdentifyingColumns []string       `yaml:"identifying_columns"`
+	Columns            []customColumn `yaml:"columns"`
+}
+
+// customColumn maps a result column to a metric. The metric name defaults to the column name.
+type customColumn struct {
+	Name       string `yaml:"name"`
+	MetricName string `yaml:"metric_name"`
+	MetricType string `yaml:"metric_type"`
+}
+
+func (c customColumn) metricName() string {
+	if c.MetricName != "" {
+		return c.MetricName
+	}
+	return c.Name
+}
+
+// loadCustomMetricsConfig reads and validates the custom queries file.
+func loadCustomMetricsConfig(path string) (*customMetricsConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading custom metrics config %s: %w", path, err)
+	}
+
+	var config customMetricsConfig
+	if err = yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("error parsing custom metrics config %s: %w", path, err)
+	}
+
+	for i := range config.Queries {
+		if err = config.Queries[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid custom query #%d in %s: %w", i+1, path, err)
+		}
+	}
+	return &config, nil
+}
+
+func (q *customQuery) validate() error {
+	if q.Query == "" {
+		return fmt.Errorf("missing query")
+	}
+	if q.SampleName == "" {
+		q.SampleName = defaultCustomQuerySampleName
+	}
+	if len(q.Columns) == 0 {
+		return fmt.Errorf("no columns defined for `%s`", q.Query)
+	}
+	for _, column := range q.Columns {
+		if column.Name == "" {
+			return fmt.Errorf("column without name for `%s`", q.Query)
+		}
+		if _, err := metric.SourceTypeForName(column.MetricType); err != nil {
+			return fmt.Errorf("column %s of `%s`: %w", column.Name, q.Query, err)
+		}
+	}
+	return nil
+}
+
+// metricsDefinition builds the definition consumed by populatePartialMetrics. The types have already been validated.
+func (q customQuery) metricsDefinition() map[string][]interface{} {
+	definition := make(map[string][]interface{}, len(q.Columns))
+	for _, column := range q.Columns {
+		sourceType, _ := metric.SourceTypeForName(column.MetricType)
+		definition[column.metricName()] = []interface{}{column.Name, sourceType}
+	}
+	return definition
+}
+
+// identifyingAttributes returns the attributes which tell apart the samples of the different rows.
+// They are named as the metric of their column, if it is mapped.
+func (q customQuery) identifyingAttributes(row map[string]interface{}) []attribute.Attribute {
+	attributes := make([]attribute.Attribute, 0, len(q.IdentifyingColumns))
+	for _, name := range q.IdentifyingColumns {
+		attributeName := name
+		for _, column := range q.Columns {
+			if column.Name == name {
+				attributeName = column.metricName()
+				break
+			}
+		}
+		value, ok := row[name]
+		if !ok {
+			log.Warn("Identifying column %s not found in the results of `%s`", name, q.Query)
+			continue
+		}
+		attributes = append(attributes, attribute.Attr(attributeName, fmt.Sprint(value)))
+	}
+	return attributes
+}
+
+// attributeColumnsAsStrings returns a copy of the row where the columns reported as attributes are strings,
+// since numeric looking values are parsed as numbers when the rows are read.
+func (q customQuery) attributeColumnsAsStrings(row map[string]interface{}) map[string]interface{} {
+	converted := make(map[string]interface{}, len(row))
+	for key, value := range row {
+		converted[key] = value
+	}
+	for _, column := range q.Columns {
+		sourceType, _ := metric.SourceTypeForName(column.MetricType)
+		if value, ok := converted[column.Name]; ok && sourceType == metric.ATTRIBUTE {
+			converted[column.Name] = fmt.Sprint(value)
+		}
+	}
+	return converted
+}
+
+/*
+populateCustomMetrics runs every custom query and reports a sample per result row.
+Queries targeting a database other than the configured one are run on a connection to that database,
+which is opened once and shared among them. A failing query is logged and does not prevent the others from being reported.
+*/
+func populateCustomMetrics(e *integration.Entity, db dataSource, config *customMetricsConfig, server dbServer, args arguments.ArgumentList, openDB func(database string) (dataSource, error)) {
+	connections := map[string]dataSource{"": db, args.Database: db}
+	defer func() {
+		for database, conn := range connections {
+			if conn != db {
+				log.Debug("Closing connection to database %s", database)
+				conn.close()
+			}
+		}
+	}()
+
+	for _, query := range config.Queries {
+		conn, ok := connections[query.Database]
+		if !ok {
+			var err error
+			conn, err = openDB(query.Database)
+			if err != nil {
+				log.Error("Error connecting to database %s for custom query `%s`: %v", query.Database, query.Query, err)
+				continue
+			}
+			connections[query.Database] = conn
+		}
+
+		rows, err := conn.queryRows(query.Query)
+		if err != nil {
+			log.Error("Error running custom query: %v", err)
+			continue
+		}
+
+		definition := query.metricsDefinition()
+		for _, row := range rows {
+			ms := infrautils.MetricSet(
+				e,
+				query.SampleName,
+				args.Hostname,
+				args.Port,
+				args.RemoteMonitoring,
+				query.identifyingAttributes(row)...,
+			)
+			populatePartialMetrics(ms, query.attributeColumnsAsStrings(row), definition, server)
+		}
+	}
+}
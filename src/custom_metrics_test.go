@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/stretchr/testify/assert"
+)
+
+type customQueryDB struct {
+	rows   map[string][]map[string]interface{}
+	closed bool
+}
+
+func (d *customQueryDB) close() { d.closed = true }
+func (d *customQueryDB) query(string) (map[string]interface{}, error) {
+	return nil, errors.New("unexpected query")
+}
+func (d *customQueryDB) queryRows(query string) ([]map[string]interface{}, error) {
+	rows, ok := d.rows[query]
+	if !ok {
+		return nil, errors.New("unknown query")
+	}
+	return rows, nil
+}
+
+func writeCustomMetricsConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "custom-queries.yml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadCustomMetricsConfig(t *testing.T) {
+	path := writeCustomMetricsConfig(t, `
+queries:
+  - query: SELECT queue, COUNT(*) AS pending FROM jobs GROUP BY queue
+    database: app
+    identifying_columns: [queue]
+    columns:
+      - name: pending
+        metric_name: jobs.pending
+        metric_type: gauge
+      - name: processed
+        metric_type: PRATE
+`)
+	config, err := loadCustomMetricsConfig(path)
+	assert.NoError(t, err)
+	assert.Len(t, config.Queries, 1)
+
+	query := config.Queries[0]
+	assert.Equal(t, "app", query.Database)
+	assert.Equal(t, defaultCustomQuerySampleName, query.SampleName)
+	assert.Equal(t, map[string][]interface{}{
+		"jobs.pending": {"pending", metric.GAUGE},
+		"processed":    {"processed", metric.PRATE},
+	}, query.metricsDefinition())
+}
+
+func TestLoadCustomMetricsConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{"missing query", "queries:\n  - columns: [{name: a, metric_type: gauge}]\n"},
+		{"missing columns", "queries:\n  - query: SELECT 1\n"},
+		{"unknown metric type", "queries:\n  - query: SELECT 1 AS a\n    columns: [{name: a, metric_type: histogram}]\n"},
+		{"invalid yaml", "queries: [\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := loadCustomMetricsConfig(writeCustomMetricsConfig(t, tc.content))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := loadCustomMetricsConfig(filepath.Join(t.TempDir(), "missing.yml"))
+	assert.Error(t, err)
+}
+
+func TestPopulateCustomMetrics(t *testing.T) {
+	i, err := integration.New("test", "1.0.0")
+	assert.NoError(t, err)
+	e := i.LocalEntity()
+
+	queueQuery := "SELECT queue, priority, COUNT(*) AS pending FROM jobs GROUP BY queue, priority"
+	db := &customQueryDB{rows: map[string][]map[string]interface{}{
+		queueQuery: {
+			{"queue": "mail", "priority": 1, "pending": 3},
+			{"queue": "mail", "priority": 2, "pending": 7},
+		},
+	}}
+	reportingDB := &customQueryDB{rows: map[string][]map[string]interface{}{
+		"SELECT COUNT(*) AS orders FROM orders": {{"orders": 42}},
+	}}
+	openedDatabases := []string{}
+	openDB := func(database string) (dataSource, error) {
+		openedDatabases = append(openedDatabases, database)
+		if database == "reporting" {
+			return reportingDB, nil
+		}
+		return nil, errors.New("access denied")
+	}
+
+	config := &customMetricsConfig{Queries: []customQuery{
+		{
+			Query:              queueQuery,
+			SampleName:         "MysqlJobQueueSample",
+			IdentifyingColumns: []string{"queue", "priority"},
+			Columns: []customColumn{
+				{Name: "pending", MetricName: "jobs.pending", MetricType: "gauge"},
+				{Name: "priority", MetricName: "jobs.priority", MetricType: "attribute"},
+			},
+		},
+		{Query: "SELECT 1 AS failing", SampleName: "MysqlFailingSample", Columns: []customColumn{{Name: "failing", MetricType: "gauge"}}},
+		{Query: "SELECT 1 AS other", Database: "forbidden", Columns: []customColumn{{Name: "other", MetricType: "gauge"}}},
+		{Query: "SELECT COUNT(*) AS orders FROM orders", Database: "reporting", SampleName: defaultCustomQuerySampleName, Columns: []customColumn{{Name: "orders", MetricType: "gauge"}}},
+	}}
+	populateCustomMetrics(e, db, config, mysqlServer("8.0.36"), args, openDB)
+
+	assert.Equal(t, []string{"forbidden", "reporting"}, openedDatabases)
+	assert.True(t, reportingDB.closed)
+	assert.False(t, db.closed)
+
+	assert.Len(t, e.Metrics, 3)
+	pendingByPriority := map[interface{}]interface{}{}
+	for _, ms := range e.Metrics {
+		switch ms.Metrics["event_type"] {
+		case "MysqlJobQueueSample":
+			assert.Equal(t, "mail", ms.Metrics["queue"])
+			pendingByPriority[ms.Metrics["jobs.priority"]] = ms.Metrics["jobs.pending"]
+		case defaultCustomQuerySampleName:
+			assert.Equal(t, float64(42), ms.Metrics["orders"])
+		default:
+			t.Errorf("unexpected sample %v", ms.Metrics["event_type"])
+		}
+	}
+	assert.Equal(t, map[interface{}]interface{}{"1": float64(3), "2": float64(7)}, pendingByPriority)
+}
@@ -11,6 +11,7 @@ import (
 type dataSource interface {
 	close()
 	query(string) (map[string]interface{}, error)
+	queryRows(string) ([]map[string]interface{}, error)
 }
 
 type database struct {
@@ -94,3 +95,52 @@ func (db *database) query(query string) (map[string]interface{}, error) {
 
 	return rawData, nil
 }
+
+/*
+queryRows executes provided as an argument query and returns every row of the output.
+Each row is parsed to a map where each column name is a key, and corresponding value is a map value.
+It is used for queries which may return more than one multi-column row, e.g. SHOW REPLICA STATUS
+on a multi-source replica, which reports one row per replication channel.
+*/
+func (db *database) queryRows(query string) ([]map[string]interface{}, error) {
+	log.Debug("executing query: " + query)
+	rows, err := db.source.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("error executing `%s`: %v", query, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			log.Warn(fmt.Sprintf("error closing rows: %v", err))
+		}
+	}()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("error getting columns from query: %v", err)
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	scanArgs := make([]interface{}, len(values))
+	for i := range values {
+		scanArgs[i] = &values[i]
+	}
+
+	var rawRows []map[string]interface{}
+	for rowIndex := 0; rows.Next(); rowIndex++ {
+		err = rows.Scan(scanArgs...)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning rows[%d]: %v", rowIndex, err)
+		}
+
+		rawRow := make(map[string]interface{}, len(columns))
+		for i, value := range values {
+			rawRow[columns[i]] = asValue(string(value))
+		}
+		rawRows = append(rawRows, rawRow)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %v", err)
+	}
+
+	return rawRows, nil
+}
@@ -45,6 +45,8 @@ var defaultMetricsBase = map[string][]interface{}{
 	"software.edition":                            {"version_comment", metric.ATTRIBUTE},
 	"software.version":                            {"version", metric.ATTRIBUTE},
 	"cluster.nodeType":                            {"node_type", metric.ATTRIBUTE},
+	"software.flavor":                             {"server_flavor", metric.ATTRIBUTE},
+	"software.flavorVersion":                      {"server_flavor_version", metric.ATTRIBUTE},
 	// If a cluster instance is not a slave, then the metric cluster.slaveRunning will be removed.
 	"cluster.slaveRunning": {slaveRunningAsNumber, metric.GAUGE},
 }
@@ -56,12 +58,26 @@ var defaultMetricsBelowVersion8 = map[string][]interface{}{
 	"db.qCacheHitRatio":           {qCacheHitRatio, metric.GAUGE},
 }
 
-func slaveRunningAsNumber(metrics map[string]interface{}, dbVersion string) (int, bool) {
+// slaveRunningAsNumber reports 1 only when the IO and SQL threads are running for every replication channel.
+func slaveRunningAsNumber(metrics map[string]interface{}, server dbServer) (int, bool) {
+	if replicaChannels, ok := metrics[replicaChannelsKey].([]map[string]interface{}); ok && len(replicaChannels) > 0 {
+		for _, channel := range replicaChannels {
+			running, ok := slaveRunningAsNumber(channel, server)
+			if !ok {
+				return 0, false
+			}
+			if running == 0 {
+				return 0, true
+			}
+		}
+		return 1, true
+	}
+
 	var prefix string
-	if isDBVersionLessThan8Point4(dbVersion) {
-		prefix = "Slave"
-	} else {
+	if server.usesReplicaTerminology() {
 		prefix = "Replica"
+	} else {
+		prefix = "Slave"
 	}
 	slaveIORunning, okIO := metrics[prefix+"_IO_Running"].(string)
 	slaveSQLRunning, okSQL := metrics[prefix+"_SQL_Running"].(string)
@@ -74,8 +90,9 @@ func slaveRunningAsNumber(metrics map[string]interface{}, dbVersion string) (int
 	return 0, true
 }
 
+// qCacheUtilization is computed from the current number of free and total blocks, so it already reflects the
+// current state of the query cache and not its lifetime.
 func qCacheUtilization(metrics map[string]interface{}) (float64, bool) {
-	// TODO compute the value within the interval
 	qCacheFreeBlocks, ok1 := metrics["Qcache_free_blocks"].(int)
 	qCacheTotalBlocks, ok2 := metrics["Qcache_total_blocks"].(int)
 
@@ -89,9 +106,10 @@ func qCacheUtilization(metrics map[string]interface{}) (float64, bool) {
 	return 0, false
 }
 
+// qCacheHitRatio is the ratio of the queries within the interval which were served by the query cache.
 func qCacheHitRatio(metrics map[string]interface{}) (float64, bool) {
-	qCacheHits, ok1 := metrics["Qcache_hits"].(int)
-	queries, ok2 := metrics["Queries"].(int)
+	qCacheHits, ok1 := intervalDelta(metrics, "Qcache_hits")
+	queries, ok2 := intervalDelta(metrics, "Queries")
 
 	if queries == 0 {
 		return 0, true
@@ -103,9 +121,9 @@ func qCacheHitRatio(metrics map[string]interface{}) (float64, bool) {
 	return 0, false
 }
 
-func getDefaultMetrics(dbVersion string) map[string][]interface{} {
-	if isDBVersionLessThan8(dbVersion) {
+func getDefaultMetrics(server dbServer) map[string][]interface{} {
+	if server.hasQueryCache() {
 		return mergeMaps(defaultMetricsBase, defaultMetricsBelowVersion8)
 	}
-	return defaultMetricsBase
+	return mergeMaps(defaultMetricsBase, nil)
 }
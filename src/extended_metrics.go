@@ -41,10 +41,15 @@ var extendedMetricsBelowVersion8 = map[string][]interface{}{
 	"db.qCacheTotalBlocks":             {"Qcache_total_blocks", metric.GAUGE},
 }
 
+// extendedMetricsForMariaDB replaces the MySQL only status variables with their MariaDB counterparts
+var extendedMetricsForMariaDB = map[string][]interface{}{
+	"db.maxExecutionTimeExceededPerSecond": {"Max_statement_time_exceeded", metric.PRATE},
+}
+
+// threadCacheMissRate is the ratio of the connections within the interval which needed a new thread.
 func threadCacheMissRate(metrics map[string]interface{}) (float64, bool) {
-	// TODO compute the value within the interval
-	threadsCreated, ok1 := metrics["Threads_created"].(int)
-	connections, ok2 := metrics["Connections"].(int)
+	threadsCreated, ok1 := intervalDelta(metrics, "Threads_created")
+	connections, ok2 := intervalDelta(metrics, "Connections")
 
 	if connections == 0 {
 		return 0, true
@@ -54,9 +59,12 @@ func threadCacheMissRate(metrics map[string]interface{}) (float64, bool) {
 	return 0, false
 }
 
-func getExtendedMetrics(dbVersion string) map[string][]interface{} {
-	if isDBVersionLessThan8(dbVersion) {
+func getExtendedMetrics(server dbServer) map[string][]interface{} {
+	if server.isMariaDB() {
+		return mergeMaps(mergeMaps(extendedMetricsBase, extendedMetricsBelowVersion8), extendedMetricsForMariaDB)
+	}
+	if server.hasQueryCache() {
 		return mergeMaps(extendedMetricsBase, extendedMetricsBelowVersion8)
 	}
-	return extendedMetricsBase
+	return mergeMaps(extendedMetricsBase, nil)
 }
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	infrautils "github.com/newrelic/nri-mysql/src/infrautils"
+)
+
+const (
+	/*
+		groupReplicationMembersQuery lists the members of the replication group together with their
+		certification and applier statistics. MEMBER_ROLE and the applier queue were added in MySQL 8.0.2.
+	*/
+	groupReplicationMembersQuery = `SELECT m.MEMBER_ID, m.MEMBER_HOST, m.MEMBER_STATE, m.MEMBER_ROLE,
+		s.COUNT_TRANSACTIONS_IN_QUEUE, s.COUNT_TRANSACTIONS_CHECKED, s.COUNT_CONFLICTS_DETECTED,
+		s.COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE,
+		IF(m.MEMBER_ID = @@server_uuid, 'Yes', 'No') AS IS_LOCAL_MEMBER
+		FROM performance_schema.replication_group_members m
+		LEFT JOIN performance_schema.replication_group_member_stats s ON s.MEMBER_ID = m.MEMBER_ID`
+	groupReplicationMembersQueryBelowVersion8 = `SELECT m.MEMBER_ID, m.MEMBER_HOST, m.MEMBER_STATE,
+		s.COUNT_TRANSACTIONS_IN_QUEUE, s.COUNT_TRANSACTIONS_CHECKED, s.COUNT_CONFLICTS_DETECTED,
+		IF(m.MEMBER_ID = @@server_uuid, 'Yes', 'No') AS IS_LOCAL_MEMBER
+		FROM performance_schema.replication_group_members m
+		LEFT JOIN performance_schema.replication_group_member_stats s ON s.MEMBER_ID = m.MEMBER_ID`
+
+	// groupReplicationMembersKey holds every member of the replication group the instance belongs to
+	groupReplicationMembersKey = "group_replication_members"
+
+	// groupReplicationChannelPrefix identifies the internal channels used by Group Replication in SHOW REPLICA STATUS
+	groupReplicationChannelPrefix = "group_replication_"
+
+	groupReplicationPrimaryRole   = "PRIMARY"
+	groupReplicationSecondaryRole = "SECONDARY"
+	groupReplicationOfflineState  = "OFFLINE"
+)
+
+var groupReplicationMemberMetrics = map[string][]interface{}{
+	"cluster.memberHost":                   {"MEMBER_HOST", metric.ATTRIBUTE},
+	"cluster.memberState":                  {"MEMBER_STATE", metric.ATTRIBUTE},
+	"cluster.memberRole":                   {"MEMBER_ROLE", metric.ATTRIBUTE},
+	"cluster.isLocalMember":                {"IS_LOCAL_MEMBER", metric.ATTRIBUTE},
+	"cluster.certificationQueueSize":       {"COUNT_TRANSACTIONS_IN_QUEUE", metric.GAUGE},
+	"cluster.applierQueueSize":             {"COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE", metric.GAUGE},
+	"cluster.conflictsDetectedPerSecond":   {"COUNT_CONFLICTS_DETECTED", metric.PRATE},
+	"cluster.transactionsCheckedPerSecond": {"COUNT_TRANSACTIONS_CHECKED", metric.PRATE},
+}
+
+func getGroupReplicationMembersQuery(server dbServer) string {
+	if isDBVersionLessThan8(server.mysqlVersion) {
+		return groupReplicationMembersQueryBelowVersion8
+	}
+	return groupReplicationMembersQuery
+}
+
+// excludeGroupReplicationChannels removes the channels managed by Group Replication,
+// so that only the asynchronous replication channels are reported as replica channels.
+func excludeGroupReplicationChannels(replicaChannels []map[string]interface{}) []map[string]interface{} {
+	asyncChannels := make([]map[string]interface{}, 0, len(replicaChannels))
+	for _, channel := range replicaChannels {
+		if name, ok := channel["Channel_Name"].(string); ok && strings.HasPrefix(name, groupReplicationChannelPrefix) {
+			continue
+		}
+		asyncChannels = append(asyncChannels, channel)
+	}
+	return asyncChannels
+}
+
+/*
+getGroupReplicationMembers returns the members of the replication group the instance belongs to, and the role
+of the local member. No members are returned when Group Replication is not installed or the local member is offline.
+*/
+func getGroupReplicationMembers(db dataSource, metrics map[string]interface{}, server dbServer) ([]map[string]interface{}, string) {
+	if !server.supportsGroupReplication() {
+		return nil, ""
+	}
+
+	members, err := db.queryRows(getGroupReplicationMembersQuery(server))
+	if err != nil {
+		log.Debug("Can't get group replication members: %v", err)
+		return nil, ""
+	}
+
+	localMemberRole := ""
+	for _, member := range members {
+		// Before MySQL 8.0.2 the role is derived from the primary member of a single-primary group
+		if _, ok := member["MEMBER_ROLE"]; !ok {
+			member["MEMBER_ROLE"] = groupReplicationRoleBelowVersion8(member, metrics)
+		}
+		if member["IS_LOCAL_MEMBER"] == "Yes" && member["MEMBER_STATE"] != groupReplicationOfflineState {
+			localMemberRole = fmt.Sprint(member["MEMBER_ROLE"])
+		}
+	}
+
+	if localMemberRole == "" {
+		return nil, ""
+	}
+	return members, localMemberRole
+}
+
+// groupReplicationRoleBelowVersion8 derives the role of a member from the group_replication_primary_member status variable.
+// The variable is empty for groups running in multi-primary mode, where every member is a primary.
+func groupReplicationRoleBelowVersion8(member map[string]interface{}, metrics map[string]interface{}) string {
+	primaryMember, _ := metrics["group_replication_primary_member"].(string)
+	if primaryMember == "" || primaryMember == member["MEMBER_ID"] {
+		return groupReplicationPrimaryRole
+	}
+	return groupReplicationSecondaryRole
+}
+
+// groupReplicationNodeType translates the role of a group member to the value reported as cluster.nodeType.
+func groupReplicationNodeType(memberRole string) string {
+	if strings.EqualFold(memberRole, groupReplicationPrimaryRole) {
+		return "primary"
+	}
+	return "secondary"
+}
+
+// populateGroupReplicationMetrics reports a MysqlGroupReplicationMemberSample for every member of the replication group.
+func populateGroupReplicationMetrics(e *integration.Entity, rawMetrics map[string]interface{}, server dbServer, args arguments.ArgumentList) {
+	members, ok := rawMetrics[groupReplicationMembersKey].([]map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for _, member := range members {
+		ms := infrautils.MetricSet(
+			e,
+			"MysqlGroupReplicationMemberSample",
+			args.Hostname,
+			args.Port,
+			args.RemoteMonitoring,
+			attribute.Attr("cluster.memberId", fmt.Sprint(member["MEMBER_ID"])),
+		)
+		populatePartialMetrics(ms, member, groupReplicationMemberMetrics, server)
+	}
+}
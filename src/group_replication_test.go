@@ -0,0 +1,115 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExcludeGroupReplicationChannels(t *testing.T) {
+	channels := []map[string]interface{}{
+		{"Channel_Name": "group_replication_applier"},
+		{"Channel_Name": "source_1"},
+		{"Channel_Name": "group_replication_recovery"},
+		{"Channel_Name": ""},
+	}
+
+	actual := excludeGroupReplicationChannels(channels)
+	assert.Equal(t, []map[string]interface{}{
+		{"Channel_Name": "source_1"},
+		{"Channel_Name": ""},
+	}, actual)
+}
+
+func TestGetRawDataForGroupReplicationMembers(t *testing.T) {
+	tests := []struct {
+		name             string
+		groupMembers     []map[string]interface{}
+		metrics          map[string]interface{}
+		expectedNodeType string
+	}{
+		{
+			name: "Primary member",
+			groupMembers: []map[string]interface{}{
+				{"MEMBER_ID": "uuid-1", "MEMBER_STATE": "ONLINE", "IS_LOCAL_MEMBER": "Yes"},
+				{"MEMBER_ID": "uuid-2", "MEMBER_STATE": "ONLINE", "IS_LOCAL_MEMBER": "No"},
+			},
+			metrics:          map[string]interface{}{"group_replication_primary_member": "uuid-1"},
+			expectedNodeType: "primary",
+		},
+		{
+			name: "Secondary member",
+			groupMembers: []map[string]interface{}{
+				{"MEMBER_ID": "uuid-1", "MEMBER_STATE": "ONLINE", "IS_LOCAL_MEMBER": "No"},
+				{"MEMBER_ID": "uuid-2", "MEMBER_STATE": "ONLINE", "IS_LOCAL_MEMBER": "Yes"},
+			},
+			metrics:          map[string]interface{}{"group_replication_primary_member": "uuid-1"},
+			expectedNodeType: "secondary",
+		},
+		{
+			name: "Multi-primary member",
+			groupMembers: []map[string]interface{}{
+				{"MEMBER_ID": "uuid-1", "MEMBER_STATE": "ONLINE", "IS_LOCAL_MEMBER": "No"},
+				{"MEMBER_ID": "uuid-2", "MEMBER_STATE": "ONLINE", "IS_LOCAL_MEMBER": "Yes"},
+			},
+			metrics:          map[string]interface{}{"group_replication_primary_member": ""},
+			expectedNodeType: "primary",
+		},
+		{
+			name: "Group replication not running",
+			groupMembers: []map[string]interface{}{
+				{"MEMBER_ID": "", "MEMBER_STATE": "OFFLINE", "IS_LOCAL_MEMBER": "No"},
+			},
+			metrics:          map[string]interface{}{},
+			expectedNodeType: "master",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			database := testdb{
+				inventory:    map[string]interface{}{},
+				metrics:      test.metrics,
+				replica:      map[string]interface{}{},
+				version:      map[string]interface{}{"version": "5.7.35"},
+				groupMembers: test.groupMembers,
+			}
+			_, metrics, _, err := getRawData(database)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedNodeType, metrics["node_type"])
+		})
+	}
+}
+
+func TestPopulateGroupReplicationMetrics(t *testing.T) {
+	i, err := integration.New("test", "1.0.0")
+	assert.NoError(t, err)
+	e := i.LocalEntity()
+
+	rawMetrics := map[string]interface{}{
+		groupReplicationMembersKey: []map[string]interface{}{
+			{
+				"MEMBER_ID":                   "uuid-1",
+				"MEMBER_HOST":                 "mysql-1",
+				"MEMBER_STATE":                "ONLINE",
+				"MEMBER_ROLE":                 "PRIMARY",
+				"IS_LOCAL_MEMBER":             "Yes",
+				"COUNT_TRANSACTIONS_IN_QUEUE": 3,
+				"COUNT_TRANSACTIONS_REMOTE_IN_APPLIER_QUEUE": 7,
+				"COUNT_TRANSACTIONS_CHECKED":                 100,
+				"COUNT_CONFLICTS_DETECTED":                   2,
+			},
+		},
+	}
+	populateGroupReplicationMetrics(e, rawMetrics, mysqlServer("8.0.40"), args)
+
+	assert.Len(t, e.Metrics, 1)
+	ms := e.Metrics[0]
+	assert.Equal(t, "MysqlGroupReplicationMemberSample", ms.Metrics["event_type"])
+	assert.Equal(t, "uuid-1", ms.Metrics["cluster.memberId"])
+	assert.Equal(t, "PRIMARY", ms.Metrics["cluster.memberRole"])
+	assert.Equal(t, "ONLINE", ms.Metrics["cluster.memberState"])
+	assert.Equal(t, float64(3), ms.Metrics["cluster.certificationQueueSize"])
+	assert.Equal(t, float64(7), ms.Metrics["cluster.applierQueueSize"])
+}
@@ -25,18 +25,23 @@ func CreateNodeEntity(
 }
 
 // MetricSet creates a new metric set with the given attributes.
-func MetricSet(e *integration.Entity, eventType, hostname string, port int, remoteMonitoring bool) *metric.Set {
+// Additional attributes identify a metric set among others of the same event type, e.g. a replication channel.
+func MetricSet(e *integration.Entity, eventType, hostname string, port int, remoteMonitoring bool, attributes ...attribute.Attribute) *metric.Set {
 	if remoteMonitoring {
 		return e.NewMetricSet(
 			eventType,
-			attribute.Attr("hostname", hostname),
-			attribute.Attr("port", strconv.Itoa(port)),
+			append([]attribute.Attribute{
+				attribute.Attr("hostname", hostname),
+				attribute.Attr("port", strconv.Itoa(port)),
+			}, attributes...)...,
 		)
 	}
 
 	return e.NewMetricSet(
 		eventType,
-		attribute.Attr("port", strconv.Itoa(port)),
+		append([]attribute.Attribute{
+			attribute.Attr("port", strconv.Itoa(port)),
+		}, attributes...)...,
 	)
 }
 
@@ -0,0 +1,29 @@
+package infrautils
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/constants"
+)
+
+// NewStore returns a store which keeps values between executions for the instance given in the arguments.
+// Each instance and name has its own file, so instances monitored at the same time don't overwrite each other's values.
+func NewStore(args arguments.ArgumentList, name string) (persist.Storer, error) {
+	return NewStoreWithTTL(args, name, args.CacheTTL)
+}
+
+// NewStoreWithTTL returns a store like NewStore, whose values are valid for ttl instead of the cache TTL of the arguments.
+func NewStoreWithTTL(args arguments.ArgumentList, name string, ttl time.Duration) (persist.Storer, error) {
+	instance := fmt.Sprint(args.Hostname, ":", args.Port, args.Socket)
+	fileName := fmt.Sprintf("%s-%s-%x", constants.IntegrationName, name, sha256.Sum256([]byte(instance)))
+	store, err := persist.NewFileStore(persist.TmpPath(args.TempDir, fileName), log.NewStdErr(args.Verbose), ttl)
+	if err != nil {
+		return nil, fmt.Errorf("can't create %s store for %s: %w", name, instance, err)
+	}
+	return store, nil
+}
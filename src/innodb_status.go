@@ -0,0 +1,169 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+const (
+	innodbStatusQuery = "SHOW ENGINE INNODB STATUS"
+	// innodbMetricsQuery lists the counters of information_schema.INNODB_METRICS, which are only updated while enabled.
+	innodbMetricsQuery = "SELECT NAME, COUNT FROM information_schema.INNODB_METRICS WHERE STATUS = 'enabled'"
+
+	// innodbStatusPrefix and innodbMetricsPrefix keep the values of both sources apart from the status variables
+	innodbStatusPrefix  = "innodb_status."
+	innodbMetricsPrefix = "innodb_metrics."
+)
+
+var (
+	historyListLengthPattern    = regexp.MustCompile(`(?m)^History list length (\d+)`)
+	logSequenceNumberPattern    = regexp.MustCompile(`(?m)^Log sequence number\s+(\d+)`)
+	lastCheckpointPattern       = regexp.MustCompile(`(?m)^Last checkpoint at\s+(\d+)`)
+	reservationCountPattern     = regexp.MustCompile(`(?m)^OS WAIT ARRAY INFO: reservation count (\d+)`)
+	semaphoreOSWaitsPattern     = regexp.MustCompile(`(?m)^(?:Mutex spin waits|RW-shared spins|RW-excl spins|RW-sx spins) .*OS waits (\d+)`)
+	semaphoreWaitPattern        = regexp.MustCompile(`(?m)^--Thread \d+ has waited at `)
+	pendingAioPattern           = regexp.MustCompile(`(?m)^Pending normal aio reads:(.*?), aio writes:(.*?),?\s*$`)
+	adaptiveHashSearchesPattern = regexp.MustCompile(`(?m)^([\d.]+) hash searches/s, ([\d.]+) non-hash searches/s`)
+	aioSlotsPattern             = regexp.MustCompile(`\d+`)
+)
+
+/*
+getInnodbStatusMetrics returns the values parsed from SHOW ENGINE INNODB STATUS together with the enabled counters of
+information_schema.INNODB_METRICS. Either source may be unavailable, e.g. without the PROCESS privilege, in which case
+only the values of the other one are returned.
+*/
+func getInnodbStatusMetrics(db dataSource) map[string]interface{} {
+	metrics := make(map[string]interface{})
+
+	status, err := db.query(innodbStatusQuery)
+	if err != nil {
+		log.Warn("Can't get the InnoDB engine status, not enough privileges (must grant PROCESS): %v", err)
+	} else if text, ok := status["Status"].(string); ok {
+		for key, value := range parseInnodbStatus(text) {
+			metrics[innodbStatusPrefix+key] = value
+		}
+	}
+
+	counters, err := db.query(innodbMetricsQuery)
+	if err != nil {
+		log.Warn("Can't get the InnoDB metrics: %v", err)
+	}
+	for name, value := range counters {
+		metrics[innodbMetricsPrefix+name] = value
+	}
+
+	return metrics
+}
+
+/*
+parseInnodbStatus extracts the values of the sections of the SHOW ENGINE INNODB STATUS text which are not reported
+as status variables. The values missing from the text, which differ among versions and flavors, are left out.
+*/
+func parseInnodbStatus(text string) map[string]interface{} {
+	values := make(map[string]interface{})
+
+	if length, ok := matchInt(historyListLengthPattern, text); ok {
+		values["history_list_length"] = length
+	}
+
+	lsn, ok1 := matchInt(logSequenceNumberPattern, text)
+	checkpoint, ok2 := matchInt(lastCheckpointPattern, text)
+	if ok1 && ok2 {
+		values["checkpoint_age"] = lsn - checkpoint
+	}
+
+	if count, ok := matchInt(reservationCountPattern, text); ok {
+		values["os_wait_reservation_count"] = count
+	}
+	if matches := semaphoreOSWaitsPattern.FindAllStringSubmatch(text, -1); len(matches) > 0 {
+		osWaits := 0
+		for _, match := range matches {
+			waits, _ := strconv.Atoi(match[1])
+			osWaits += waits
+		}
+		values["semaphore_os_waits"] = osWaits
+	}
+	if strings.Contains(text, "\nSEMAPHORES\n") {
+		values["semaphore_waiting_threads"] = len(semaphoreWaitPattern.FindAllString(text, -1))
+	}
+
+	if match := pendingAioPattern.FindStringSubmatch(text); match != nil {
+		values["pending_normal_aio_reads"] = pendingAioRequests(match[1])
+		values["pending_normal_aio_writes"] = pendingAioRequests(match[2])
+	}
+
+	if match := adaptiveHashSearchesPattern.FindStringSubmatch(text); match != nil {
+		hashSearches, err1 := strconv.ParseFloat(match[1], 64)
+		nonHashSearches, err2 := strconv.ParseFloat(match[2], 64)
+		if err1 == nil && err2 == nil {
+			values["hash_searches_per_second"] = hashSearches
+			values["non_hash_searches_per_second"] = nonHashSearches
+		}
+	}
+
+	return values
+}
+
+func matchInt(pattern *regexp.Regexp, text string) (int, bool) {
+	match := pattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, false
+	}
+	value, err := strconv.Atoi(match[1])
+	return value, err == nil
+}
+
+/*
+pendingAioRequests returns the pending requests of the aio threads, given either as their total followed by the
+requests of each thread, e.g. `2 [0, 2] `, or, since MySQL 8.0, only as the requests of each thread, e.g. `[0, 2] `.
+*/
+func pendingAioRequests(text string) int {
+	if total, _, found := strings.Cut(text, "["); found && strings.TrimSpace(total) != "" {
+		value, _ := strconv.Atoi(strings.TrimSpace(total))
+		return value
+	}
+	requests := 0
+	for _, slot := range aioSlotsPattern.FindAllString(text, -1) {
+		value, _ := strconv.Atoi(slot)
+		requests += value
+	}
+	return requests
+}
+
+// historyListLength is taken from the engine status, or from the trx_rseg_history_len counter when it is unavailable.
+func historyListLength(metrics map[string]interface{}) (float64, bool) {
+	return firstInnodbValue(metrics, innodbStatusPrefix+"history_list_length", innodbMetricsPrefix+"trx_rseg_history_len")
+}
+
+// checkpointAge is taken from the engine status, or from the log_lsn_checkpoint_age counter when it is unavailable.
+func checkpointAge(metrics map[string]interface{}) (float64, bool) {
+	return firstInnodbValue(metrics, innodbStatusPrefix+"checkpoint_age", innodbMetricsPrefix+"log_lsn_checkpoint_age")
+}
+
+/*
+adaptiveHashIndexHitRatio is the ratio of the searches served by the adaptive hash index, from the per second averages
+of the engine status. No ratio is reported while there are no searches.
+*/
+func adaptiveHashIndexHitRatio(metrics map[string]interface{}) (float64, bool) {
+	hashSearches, ok1 := metrics[innodbStatusPrefix+"hash_searches_per_second"].(float64)
+	nonHashSearches, ok2 := metrics[innodbStatusPrefix+"non_hash_searches_per_second"].(float64)
+	if !ok1 || !ok2 || hashSearches+nonHashSearches == 0 {
+		return 0, false
+	}
+	return hashSearches / (hashSearches + nonHashSearches), true
+}
+
+func firstInnodbValue(metrics map[string]interface{}, keys ...string) (float64, bool) {
+	for _, key := range keys {
+		switch value := metrics[key].(type) {
+		case int:
+			return float64(value), true
+		case float64:
+			return value, true
+		}
+	}
+	return 0, false
+}
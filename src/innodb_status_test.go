@@ -0,0 +1,99 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/stretchr/testify/assert"
+)
+
+const innodbStatusText = `
+=====================================
+2024-01-15 10:24:01 0x7f8b2c0a1700 INNODB MONITOR OUTPUT
+=====================================
+----------
+SEMAPHORES
+----------
+OS WAIT ARRAY INFO: reservation count 1520
+--Thread 140234567890432 has waited at buf0flu.cc line 1234 for 2 seconds the semaphore:
+RW-shared spins 10, rounds 20, OS waits 7
+RW-excl spins 3, rounds 40, OS waits 5
+RW-sx spins 0, rounds 0, OS waits 0
+Spin rounds per wait: 2.00 RW-shared, 13.33 RW-excl, 0.00 RW-sx
+------------
+TRANSACTIONS
+------------
+Trx id counter 12350
+Purge done for trx's n:o < 12340 undo n:o < 0 state: running but idle
+History list length 42
+--------
+FILE I/O
+--------
+Pending normal aio reads: [0, 3, 1, 0] , aio writes: [2, 0, 0, 0] ,
+ ibuf aio reads:, log i/o's:, sync i/o's:
+-------------------------------------
+INSERT BUFFER AND ADAPTIVE HASH INDEX
+-------------------------------------
+Hash table size 34679, node heap has 2 buffer(s)
+150.00 hash searches/s, 50.00 non-hash searches/s
+---
+LOG
+---
+Log sequence number          19802342
+Log buffer assigned up to    19802342
+Log flushed up to            19802342
+Last checkpoint at           19790000
+`
+
+func TestParseInnodbStatus(t *testing.T) {
+	values := parseInnodbStatus(innodbStatusText)
+
+	assert.Equal(t, 42, values["history_list_length"])
+	assert.Equal(t, 12342, values["checkpoint_age"])
+	assert.Equal(t, 1520, values["os_wait_reservation_count"])
+	assert.Equal(t, 12, values["semaphore_os_waits"])
+	assert.Equal(t, 1, values["semaphore_waiting_threads"])
+	assert.Equal(t, 4, values["pending_normal_aio_reads"])
+	assert.Equal(t, 2, values["pending_normal_aio_writes"])
+	assert.Equal(t, 150.0, values["hash_searches_per_second"])
+	assert.Equal(t, 50.0, values["non_hash_searches_per_second"])
+
+	// MySQL 5.7 reports the total of the pending requests before the requests of each thread
+	values = parseInnodbStatus("Pending normal aio reads: 5 [2, 3] , aio writes: 0 [0, 0] ,\n")
+	assert.Equal(t, 5, values["pending_normal_aio_reads"])
+	assert.Equal(t, 0, values["pending_normal_aio_writes"])
+	assert.NotContains(t, values, "history_list_length")
+	assert.NotContains(t, values, "semaphore_waiting_threads")
+}
+
+func TestGetInnodbStatusMetrics(t *testing.T) {
+	database := testdb{
+		innodbStatus: map[string]interface{}{"Type": "InnoDB", "Name": "", "Status": innodbStatusText},
+		innodbCounts: map[string]interface{}{"lock_deadlocks": 3, "trx_rseg_history_len": 40},
+	}
+
+	metrics := getInnodbStatusMetrics(database)
+	assert.Equal(t, 42, metrics["innodb_status.history_list_length"])
+	assert.Equal(t, 3, metrics["innodb_metrics.lock_deadlocks"])
+	assert.Equal(t, 40, metrics["innodb_metrics.trx_rseg_history_len"])
+}
+
+func TestPopulateInnodbStatusMetrics(t *testing.T) {
+	rawMetrics := map[string]interface{}{
+		"innodb_status.checkpoint_age":               12342,
+		"innodb_status.pending_normal_aio_reads":     4,
+		"innodb_status.semaphore_waiting_threads":    1,
+		"innodb_status.hash_searches_per_second":     150.0,
+		"innodb_status.non_hash_searches_per_second": 50.0,
+		// The history list length is taken from INNODB_METRICS when the engine status doesn't report it
+		"innodb_metrics.trx_rseg_history_len": 40,
+	}
+	ms := metric.NewSet("MysqlSample", nil)
+	populatePartialMetrics(ms, rawMetrics, innodbStatusMetrics, mysqlServer("8.0.36"))
+
+	assert.Equal(t, float64(40), ms.Metrics["db.innodb.historyListLength"])
+	assert.Equal(t, float64(12342), ms.Metrics["db.innodb.checkpointAgeBytes"])
+	assert.Equal(t, float64(4), ms.Metrics["db.innodb.pendingNormalAioReads"])
+	assert.Equal(t, float64(1), ms.Metrics["db.innodb.semaphoreWaitingThreads"])
+	assert.Equal(t, 0.75, ms.Metrics["db.innodb.adaptiveHashIndexHitRatio"])
+}
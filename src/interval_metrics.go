@@ -0,0 +1,67 @@
+package main
+
+import (
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+)
+
+const (
+	previousSampleKey   = "previous_sample"
+	previousSampleStore = "interval"
+)
+
+// intervalCounters are the status counters kept between executions to compute the derived metrics within the interval.
+var intervalCounters = []string{
+	"Uptime",
+	"Threads_created",
+	"Connections",
+	"Qcache_hits",
+	"Queries",
+}
+
+/*
+withPreviousSample adds the counters of the previous execution, kept in the store, to the raw metrics and stores
+the current ones for the next execution. The previous counters are not added when the server has been restarted
+since the previous execution, i.e. its Uptime has decreased, so that the derived metrics are computed since the restart.
+*/
+func withPreviousSample(store persist.Storer, rawMetrics map[string]interface{}) {
+	var previous map[string]int
+	if _, err := store.Get(previousSampleKey, &previous); err != nil {
+		log.Debug("No previous sample to compute the interval metrics: %v", err)
+	} else if uptime, ok := rawMetrics["Uptime"].(int); ok && uptime < previous["Uptime"] {
+		log.Debug("Uptime decreased from %d to %d, the server has been restarted since the previous sample", previous["Uptime"], uptime)
+	} else {
+		rawMetrics[previousSampleKey] = previous
+	}
+
+	current := make(map[string]int, len(intervalCounters))
+	for _, name := range intervalCounters {
+		if value, ok := rawMetrics[name].(int); ok {
+			current[name] = value
+		}
+	}
+	store.Set(previousSampleKey, current)
+	if err := store.Save(); err != nil {
+		log.Warn("Error saving the sample for the next interval: %v", err)
+	}
+}
+
+/*
+intervalDelta returns the increase of a counter since the previous sample. Without a previous sample, e.g. in the first
+execution or after a restart, it returns the counter itself, which is its increase since the server started.
+*/
+func intervalDelta(metrics map[string]interface{}, name string) (int, bool) {
+	current, ok := metrics[name].(int)
+	if !ok {
+		return 0, false
+	}
+	previousSample, ok := metrics[previousSampleKey].(map[string]int)
+	if !ok {
+		return current, true
+	}
+	previous, ok := previousSample[name]
+	if !ok || previous > current {
+		return current, true
+	}
+	return current - previous, true
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDerivedRatiosWithinTheInterval(t *testing.T) {
+	store := persist.NewInMemoryStore()
+
+	first := map[string]interface{}{"Uptime": 1000, "Threads_created": 100, "Connections": 1000, "Qcache_hits": 900, "Queries": 1000}
+	withPreviousSample(store, first)
+	assert.NotContains(t, first, previousSampleKey)
+
+	// Without a previous sample the ratios are computed since the server started
+	missRate, ok := threadCacheMissRate(first)
+	assert.True(t, ok)
+	assert.Equal(t, 0.1, missRate)
+	hitRatio, ok := qCacheHitRatio(first)
+	assert.True(t, ok)
+	assert.Equal(t, 0.9, hitRatio)
+
+	second := map[string]interface{}{"Uptime": 1030, "Threads_created": 150, "Connections": 1100, "Qcache_hits": 910, "Queries": 1100}
+	withPreviousSample(store, second)
+
+	missRate, ok = threadCacheMissRate(second)
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, missRate)
+	hitRatio, ok = qCacheHitRatio(second)
+	assert.True(t, ok)
+	assert.Equal(t, 0.1, hitRatio)
+
+	// No connections within the interval
+	third := map[string]interface{}{"Uptime": 1060, "Threads_created": 150, "Connections": 1100, "Qcache_hits": 910, "Queries": 1100}
+	withPreviousSample(store, third)
+	missRate, ok = threadCacheMissRate(third)
+	assert.True(t, ok)
+	assert.Equal(t, float64(0), missRate)
+}
+
+func TestDerivedRatiosAfterARestart(t *testing.T) {
+	store := persist.NewInMemoryStore()
+	withPreviousSample(store, map[string]interface{}{"Uptime": 5000, "Threads_created": 10, "Connections": 5000})
+
+	restarted := map[string]interface{}{"Uptime": 20, "Threads_created": 4, "Connections": 8}
+	withPreviousSample(store, restarted)
+	assert.NotContains(t, restarted, previousSampleKey)
+
+	missRate, ok := threadCacheMissRate(restarted)
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, missRate)
+
+	next := map[string]interface{}{"Uptime": 50, "Threads_created": 5, "Connections": 18}
+	withPreviousSample(store, next)
+	missRate, ok = threadCacheMissRate(next)
+	assert.True(t, ok)
+	assert.Equal(t, 0.1, missRate)
+}
+
+func TestIntervalDelta(t *testing.T) {
+	metrics := map[string]interface{}{
+		"Queries":         100,
+		"Connections":     5,
+		previousSampleKey: map[string]int{"Queries": 40, "Connections": 10},
+	}
+
+	delta, ok := intervalDelta(metrics, "Queries")
+	assert.True(t, ok)
+	assert.Equal(t, 60, delta)
+
+	// A counter which has been reset is reported since it was reset
+	delta, ok = intervalDelta(metrics, "Connections")
+	assert.True(t, ok)
+	assert.Equal(t, 5, delta)
+
+	_, ok = intervalDelta(metrics, "Threads_created")
+	assert.False(t, ok)
+}
+
+func TestPreviousSampleIsKeptBetweenExecutions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "interval.json")
+	store, err := persist.NewFileStore(path, log.NewStdErr(false), time.Minute)
+	assert.NoError(t, err)
+	withPreviousSample(store, map[string]interface{}{"Uptime": 100, "Queries": 1000})
+
+	store, err = persist.NewFileStore(path, log.NewStdErr(false), time.Minute)
+	assert.NoError(t, err)
+	metrics := map[string]interface{}{"Uptime": 130, "Queries": 1600}
+	withPreviousSample(store, metrics)
+
+	delta, ok := intervalDelta(metrics, "Queries")
+	assert.True(t, ok)
+	assert.Equal(t, 600, delta)
+}
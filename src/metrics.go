@@ -36,6 +36,35 @@ var innodbMetrics = map[string][]interface{}{
 	"db.innodb.rowsUpdatedPerSecond":                {"Innodb_rows_updated", metric.PRATE},
 }
 
+// innodbChangeBufferMetricsForMariaDB are only reported by MariaDB 10.x, as MariaDB 11.0 removed the change buffer
+var innodbChangeBufferMetricsForMariaDB = map[string][]interface{}{
+	"db.innodb.changeBufferFreeListPages":    {"Innodb_ibuf_free_list", metric.GAUGE},
+	"db.innodb.changeBufferMergesPerSecond":  {"Innodb_ibuf_merges", metric.PRATE},
+	"db.innodb.changeBufferSegmentSizePages": {"Innodb_ibuf_segment_size", metric.GAUGE},
+	"db.innodb.changeBufferSizePages":        {"Innodb_ibuf_size", metric.GAUGE},
+}
+
+func getInnodbMetrics(server dbServer) map[string][]interface{} {
+	if server.hasChangeBufferStatus() {
+		return mergeMaps(innodbMetrics, innodbChangeBufferMetricsForMariaDB)
+	}
+	return innodbMetrics
+}
+
+// innodbStatusMetrics are parsed from SHOW ENGINE INNODB STATUS and read from the counters of information_schema.INNODB_METRICS
+var innodbStatusMetrics = map[string][]interface{}{
+	"db.innodb.historyListLength":           {historyListLength, metric.GAUGE},
+	"db.innodb.checkpointAgeBytes":          {checkpointAge, metric.GAUGE},
+	"db.innodb.osWaitReservationsPerSecond": {"innodb_status.os_wait_reservation_count", metric.PRATE},
+	"db.innodb.semaphoreOsWaitsPerSecond":   {"innodb_status.semaphore_os_waits", metric.PRATE},
+	"db.innodb.semaphoreWaitingThreads":     {"innodb_status.semaphore_waiting_threads", metric.GAUGE},
+	"db.innodb.pendingNormalAioReads":       {"innodb_status.pending_normal_aio_reads", metric.GAUGE},
+	"db.innodb.pendingNormalAioWrites":      {"innodb_status.pending_normal_aio_writes", metric.GAUGE},
+	"db.innodb.adaptiveHashIndexHitRatio":   {adaptiveHashIndexHitRatio, metric.GAUGE},
+	"db.innodb.deadlocksPerSecond":          {"innodb_metrics.lock_deadlocks", metric.PRATE},
+	"db.innodb.lockTimeoutsPerSecond":       {"innodb_metrics.lock_timeouts", metric.PRATE},
+}
+
 var myisamMetrics = map[string][]interface{}{
 	"db.myisam.keyBlocksNotFlushed":       {"Key_blocks_not_flushed", metric.GAUGE},
 	"db.myisam.keyCacheUtilization":       {keyCacheUtilization, metric.GAUGE},
@@ -45,6 +74,20 @@ var myisamMetrics = map[string][]interface{}{
 	"db.myisam.keyWritesPerSecond":        {"Key_writes", metric.PRATE},
 }
 
+// ariaMetrics are reported by MariaDB, where Aria is the crash-safe replacement of MyISAM used for internal temporary tables
+var ariaMetrics = map[string][]interface{}{
+	"db.aria.pagecacheBlocksNotFlushed":       {"Aria_pagecache_blocks_not_flushed", metric.GAUGE},
+	"db.aria.pagecacheBlocksUnused":           {"Aria_pagecache_blocks_unused", metric.GAUGE},
+	"db.aria.pagecacheBlocksUsed":             {"Aria_pagecache_blocks_used", metric.GAUGE},
+	"db.aria.pagecacheReadRequestsPerSecond":  {"Aria_pagecache_read_requests", metric.PRATE},
+	"db.aria.pagecacheReadsPerSecond":         {"Aria_pagecache_reads", metric.PRATE},
+	"db.aria.pagecacheWriteRequestsPerSecond": {"Aria_pagecache_write_requests", metric.PRATE},
+	"db.aria.pagecacheWritesPerSecond":        {"Aria_pagecache_writes", metric.PRATE},
+	"db.aria.transactionLogSyncsPerSecond":    {"Aria_transaction_log_syncs", metric.PRATE},
+}
+
+// keyCacheUtilization is computed from the current number of unused blocks, so it already reflects the
+// current state of the key cache and not its lifetime.
 func keyCacheUtilization(metrics map[string]interface{}) (float64, bool) {
 	keyBlocksUnused, ok1 := metrics["Key_blocks_unused"].(int)
 	keyCacheBlockSize, ok2 := metrics["key_cache_block_size"].(int)
@@ -10,6 +10,7 @@ import (
 	"github.com/newrelic/infra-integrations-sdk/v3/data/inventory"
 	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	arguments "github.com/newrelic/nri-mysql/src/args"
 )
 
 const (
@@ -21,10 +22,15 @@ const (
 		Ref - https://dev.mysql.com/doc/relnotes/mysql/8.4/en/news-8-4-0.html#:~:text=SQL%20statements%20removed
 	*/
 	replicaQueryForVersion8Point4AndAbove = "SHOW REPLICA STATUS"
-	dbVersionQuery                        = "SELECT VERSION() as version;"
+	// MariaDB reports every connection of a multi-source replica only with SHOW ALL SLAVES STATUS
+	replicaQueryForMariaDB = "SHOW ALL SLAVES STATUS"
+	dbVersionQuery         = "SELECT VERSION() as version;"
 
 	dbMajorVersionThreshold = 8
 	dbMinorVersionThreshold = 4
+
+	// replicaChannelsKey holds every row returned by the replica query, one per replication channel
+	replicaChannelsKey = "replica_channels"
 )
 
 var errVersionNotFound = errors.New("version not found in versionQueryResult")
@@ -59,11 +65,15 @@ func isDBVersionLessThan8Point4(dbVersion string) bool {
 	return majorVersion < dbMajorVersionThreshold || (majorVersion == dbMajorVersionThreshold && minorVersion < dbMinorVersionThreshold)
 }
 
-func getReplicaQuery(dbVersion string) string {
-	if isDBVersionLessThan8Point4(dbVersion) {
+func getReplicaQuery(server dbServer) string {
+	switch {
+	case server.isMariaDB():
+		return replicaQueryForMariaDB
+	case server.usesReplicaTerminology():
+		return replicaQueryForVersion8Point4AndAbove
+	default:
 		return replicaQueryBelowVersion8Point4
 	}
-	return replicaQueryForVersion8Point4AndAbove
 }
 
 // Try to convert a string to its type or return the string if not possible
@@ -82,38 +92,56 @@ func asValue(value string) interface{} {
 	return value
 }
 
-func getRawData(db dataSource) (map[string]interface{}, map[string]interface{}, string, error) {
-
-	dbVersion := checkDBServerAndGetDBVersion(db)
-
+func getRawData(db dataSource) (map[string]interface{}, map[string]interface{}, dbServer, error) {
 	inventory, err := db.query(inventoryQuery)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("error querying inventory: %w", err)
+		return nil, nil, dbServer{}, fmt.Errorf("error querying inventory: %w", err)
 	}
+
+	server := getDBServer(db, inventory)
+
 	metrics, err := db.query(metricsQuery)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("error querying metrics: %w", err)
+		return nil, nil, dbServer{}, fmt.Errorf("error querying metrics: %w", err)
 	}
 
-	replicaQuery := getReplicaQuery(dbVersion)
-	switch replication, err := db.query(replicaQuery); {
+	replicaQuery := getReplicaQuery(server)
+	replicaChannels, err := db.queryRows(replicaQuery)
+	replicaChannels = excludeGroupReplicationChannels(replicaChannels)
+	switch {
 	case err != nil:
 		log.Warn("Can't get node type, not enough privileges (must grant REPLICATION CLIENT)")
-	case len(replication) == 0:
+	case len(replicaChannels) == 0:
 		metrics["node_type"] = "master"
 	default:
 		metrics["node_type"] = "slave"
-		for key := range replication {
-			metrics[key] = replication[key]
+		// The first channel is merged into the MysqlSample to keep reporting the single-source replication metrics
+		for key := range replicaChannels[0] {
+			metrics[key] = replicaChannels[0][key]
 		}
+		metrics[replicaChannelsKey] = replicaChannels
+	}
+
+	// Members of a replication group report their role in the group instead of the asynchronous replication role
+	if members, memberRole := getGroupReplicationMembers(db, metrics, server); len(members) > 0 {
+		metrics["node_type"] = groupReplicationNodeType(memberRole)
+		metrics[groupReplicationMembersKey] = members
+	}
+
+	// Nodes of a Galera based cluster replicate synchronously through wsrep instead of the binary log
+	if isWsrepEnabled(inventory) {
+		metrics["node_type"] = "galera"
 	}
 
 	metrics["key_cache_block_size"] = inventory["key_cache_block_size"]
 	metrics["key_buffer_size"] = inventory["key_buffer_size"]
 	metrics["version_comment"] = inventory["version_comment"]
 	metrics["version"] = inventory["version"]
+	metrics["wsrep_on"] = inventory["wsrep_on"]
+	metrics["server_flavor"] = string(server.flavor)
+	metrics["server_flavor_version"] = server.version
 
-	return inventory, metrics, dbVersion, nil
+	return inventory, metrics, server, nil
 }
 
 func populateInventory(inventory *inventory.Inventory, rawData map[string]interface{}) {
@@ -125,32 +153,41 @@ func populateInventory(inventory *inventory.Inventory, rawData map[string]interf
 	}
 }
 
-func populateMetrics(sample *metric.Set, rawMetrics map[string]interface{}, dbVersion string) {
-	defaultMetrics := getDefaultMetrics(dbVersion)
+func populateMetrics(sample *metric.Set, rawMetrics map[string]interface{}, server dbServer, args arguments.ArgumentList) {
+	defaultMetrics := getDefaultMetrics(server)
 	if rawMetrics["node_type"] != "slave" {
 		delete(defaultMetrics, "cluster.slaveRunning")
 	}
-	populatePartialMetrics(sample, rawMetrics, defaultMetrics, dbVersion)
+	populatePartialMetrics(sample, rawMetrics, defaultMetrics, server)
 
 	if args.ExtendedMetrics {
-		extendedMetrics := getExtendedMetrics(dbVersion)
+		extendedMetrics := getExtendedMetrics(server)
 		if rawMetrics["node_type"] == "slave" {
-			slaveMetrics := getSlaveMetrics(dbVersion)
+			slaveMetrics := getSlaveMetrics(server)
 			for key := range slaveMetrics {
 				extendedMetrics[key] = slaveMetrics[key]
 			}
 		}
-		populatePartialMetrics(sample, rawMetrics, extendedMetrics, dbVersion)
+		populatePartialMetrics(sample, rawMetrics, extendedMetrics, server)
 	}
 	if args.ExtendedInnodbMetrics {
-		populatePartialMetrics(sample, rawMetrics, innodbMetrics, dbVersion)
+		populatePartialMetrics(sample, rawMetrics, getInnodbMetrics(server), server)
+	}
+	if args.ExtendedInnodbStatusMetrics {
+		populatePartialMetrics(sample, rawMetrics, innodbStatusMetrics, server)
 	}
 	if args.ExtendedMyIsamMetrics {
-		populatePartialMetrics(sample, rawMetrics, myisamMetrics, dbVersion)
+		populatePartialMetrics(sample, rawMetrics, myisamMetrics, server)
+		if server.isMariaDB() {
+			populatePartialMetrics(sample, rawMetrics, ariaMetrics, server)
+		}
+	}
+	if args.ExtendedWsrepMetrics || isWsrepEnabled(rawMetrics) {
+		populatePartialMetrics(sample, rawMetrics, wsrepMetrics, server)
 	}
 }
 
-func populatePartialMetrics(ms *metric.Set, metrics map[string]interface{}, metricsDefinition map[string][]interface{}, dbVersion string) {
+func populatePartialMetrics(ms *metric.Set, metrics map[string]interface{}, metricsDefinition map[string][]interface{}, server dbServer) {
 	for metricName, metricConf := range metricsDefinition {
 		rawSource := metricConf[0]
 		metricType := metricConf[1].(metric.SourceType)
@@ -163,8 +200,8 @@ func populatePartialMetrics(ms *metric.Set, metrics map[string]interface{}, metr
 			rawMetric, ok = metrics[source]
 		case func(map[string]interface{}) (float64, bool):
 			rawMetric, ok = source(metrics)
-		case func(map[string]interface{}, string) (int, bool):
-			rawMetric, ok = source(metrics, dbVersion)
+		case func(map[string]interface{}, dbServer) (int, bool):
+			rawMetric, ok = source(metrics, server)
 		default:
 			log.Warn("Invalid raw source metric for %s", metricName)
 			continue
@@ -203,38 +240,6 @@ func getRawDBVersion(db dataSource) (string, error) {
 	}
 }
 
-// The func checks if the DB server is MariaDB
-// If true it returns DBVersion as 5.7.0
-// otherwise it returns the DB version by querying `SELECT VERSION()`
-func checkDBServerAndGetDBVersion(db dataSource) string {
-	/*
-		Note: The default DB version is 5.7.0 as the earlier codebase was using
-			  replicaQueryBelowVersion8Point4 to populate the metrics irrespective of the version
-	*/
-	defaultDBVersion := "5.7.0"
-	rawDBversion, err := getRawDBVersion(db)
-	if err != nil {
-		log.Warn(err.Error())
-		log.Warn("Assuming the mysql version to be less than 8.4")
-		return defaultDBVersion
-	}
-
-	if isMariaDBServer(rawDBversion) {
-		log.Warn("Detected the db server is MariaDB - %s.", rawDBversion)
-		// returning dbVersion as 5.7.0 because the replicaQuery for MariaDB should be `SHOW SLAVE STATUS`
-		return defaultDBVersion
-	}
-
-	sanitizedDBVersion, err := extractSanitizedDBVersion(rawDBversion)
-	log.Debug("sanitized version: %v", sanitizedDBVersion)
-	if err != nil {
-		log.Warn(err.Error())
-		log.Warn("Assuming the mysql version to be less than 8.4")
-		return defaultDBVersion
-	}
-	return sanitizedDBVersion
-}
-
 // extractSanitizedDBVersion uses a regular expression to extract a version string up to major.minor.patch
 func extractSanitizedDBVersion(version string) (string, error) {
 	reg := regexp.MustCompile(`^(?P<major>\d+)(?:\.(?P<minor>\d+))?(?:\.(?P<patch>\d+))?`)
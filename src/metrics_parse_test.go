@@ -72,7 +72,7 @@ func TestGetReplicaQuery(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.dbVersion, func(t *testing.T) {
-			actual := getReplicaQuery(test.dbVersion)
+			actual := getReplicaQuery(mysqlServer(test.dbVersion))
 			assert.Equal(t, test.expected, actual)
 			if actual != test.expected {
 				assert.Equal(t, test.expected, actual, "For version %s, expected %v, but got %v", test.dbVersion, test.expected, actual)
@@ -119,8 +119,8 @@ func TestGetRawDataWithoutDBVersion(t *testing.T) {
 		replica: map[string]interface{}{},
 		version: map[string]interface{}{},
 	}
-	inventory, metrics, dbVersion, err := getRawData(database)
-	assert.Equal(t, "5.7.0", dbVersion)
+	inventory, metrics, server, err := getRawData(database)
+	assert.Equal(t, "5.7.0", server.version)
 	if err != nil {
 		t.Error()
 	}
@@ -130,7 +130,7 @@ func TestGetRawDataWithoutDBVersion(t *testing.T) {
 	if inventory == nil {
 		t.Error()
 	}
-	if dbVersion == "" {
+	if server.version == "" {
 		t.Error()
 	}
 }
@@ -212,55 +212,50 @@ func TestGetRawDBVersion(t *testing.T) {
 	}
 }
 
-func TestCheckDBServerAndGetDBVersion(t *testing.T) {
-	tests := []struct {
-		name     string
-		mockRows *sqlmock.Rows
-		expected string
-	}{
-		{
-			name:     "Successful dbVersion query",
-			mockRows: sqlmock.NewRows([]string{"version"}).AddRow("8.0.40-0ubuntu0.22.04.1"),
-			expected: "8.0.40",
-		},
-		{
-			name:     "dbVersion query output with mariadb",
-			mockRows: sqlmock.NewRows([]string{"version"}).AddRow("11.3.2-MariaDB-log"),
-			expected: "5.7.0",
-		},
-		{
-			name:     "Error exec dbVersion query",
-			mockRows: nil,
-			expected: "5.7.0",
-		},
-		{
-			name:     "Version not found in result",
-			mockRows: sqlmock.NewRows([]string{""}).AddRow(nil),
-			expected: "5.7.0",
-		},
-		{
-			name:     "Invalid dbVersion output",
-			mockRows: sqlmock.NewRows([]string{"version"}).AddRow("invalid"),
-			expected: "5.7.0",
-		},
-	}
+func TestQueryRows(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
-			assert.NoError(t, err)
-			defer db.Close()
+	database := &database{source: db}
+	mock.ExpectQuery(replicaQueryForVersion8Point4AndAbove).WillReturnRows(
+		sqlmock.NewRows([]string{"Channel_Name", "Replica_IO_Running", "Seconds_Behind_Source"}).
+			AddRow("source_1", "Yes", "0").
+			AddRow("source_2", "No", "15"),
+	)
 
-			assert.NoError(t, err)
-			database := &database{source: db}
-			if test.mockRows != nil {
-				mock.ExpectQuery(dbVersionQuery).WillReturnRows(test.mockRows)
-			} else {
-				mock.ExpectQuery(dbVersionQuery).WillReturnError(assert.AnError)
-			}
+	rows, err := database.queryRows(replicaQueryForVersion8Point4AndAbove)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"Channel_Name": "source_1", "Replica_IO_Running": "Yes", "Seconds_Behind_Source": 0},
+		{"Channel_Name": "source_2", "Replica_IO_Running": "No", "Seconds_Behind_Source": 15},
+	}, rows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
 
-			actual := checkDBServerAndGetDBVersion(database)
-			assert.Equal(t, test.expected, actual)
-		})
-	}
+func TestGetRawDataWithMultipleReplicaChannels(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	database := &database{source: db}
+	mock.ExpectQuery(inventoryQuery).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("version", "8.4.3"))
+	mock.ExpectQuery(dbVersionQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow("8.4.3"))
+	mock.ExpectQuery(metricsQuery).WillReturnRows(sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Uptime", "100"))
+	mock.ExpectQuery(replicaQueryForVersion8Point4AndAbove).WillReturnRows(
+		sqlmock.NewRows([]string{"Channel_Name", "Replica_IO_Running", "Replica_SQL_Running"}).
+			AddRow("source_1", "Yes", "Yes").
+			AddRow("source_2", "Yes", "No").
+			AddRow("source_3", "Yes", "Yes"),
+	)
+
+	_, metrics, server, err := getRawData(database)
+	assert.NoError(t, err)
+	assert.Equal(t, "slave", metrics["node_type"])
+	assert.Equal(t, "source_1", metrics["Channel_Name"])
+	assert.Len(t, metrics[replicaChannelsKey], 3)
+
+	slaveRunning, ok := slaveRunningAsNumber(metrics, server)
+	assert.True(t, ok)
+	assert.Equal(t, 0, slaveRunning)
 }
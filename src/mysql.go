@@ -46,21 +46,52 @@ func main() {
 
 	log.SetupLogging(args.Verbose)
 
+	if args.TargetsConfig != "" {
+		targets, err := loadTargets(args.TargetsConfig, args)
+		infrautils.FatalIfErr(err)
+		collectTargets(targets, args.MaxConcurrentTargets)
+		return
+	}
+
+	infrautils.FatalIfErr(collect(i, args))
+}
+
+// collect reports the inventory, metrics and query performance data of the instance given in the arguments.
+func collect(i *integration.Integration, args arguments.ArgumentList) error {
 	e, err := infrautils.CreateNodeEntity(i, args.RemoteMonitoring, args.Hostname, args.Port)
-	infrautils.FatalIfErr(err)
+	if err != nil {
+		return err
+	}
 
 	db, err := openSQLDB(dbutils.GenerateDSN(args, ""))
-	infrautils.FatalIfErr(err)
+	if err != nil {
+		return err
+	}
 	defer db.close()
 
-	rawInventory, rawMetrics, dbVersion, err := getRawData(db)
-	infrautils.FatalIfErr(err)
+	rawInventory, rawMetrics, server, err := getRawData(db)
+	if err != nil {
+		return err
+	}
 
 	if args.HasInventory() {
 		populateInventory(e.Inventory, rawInventory)
 	}
 
 	if args.HasMetrics() {
+		if args.ExtendedInnodbStatusMetrics {
+			for key, value := range getInnodbStatusMetrics(db) {
+				rawMetrics[key] = value
+			}
+		}
+
+		store, err := infrautils.NewStore(args, previousSampleStore)
+		if err != nil {
+			log.Warn("Derived metrics will be computed since the server started: %v", err)
+		} else {
+			withPreviousSample(store, rawMetrics)
+		}
+
 		ms := infrautils.MetricSet(
 			e,
 			"MysqlSample",
@@ -68,11 +99,27 @@ func main() {
 			args.Port,
 			args.RemoteMonitoring,
 		)
-		populateMetrics(ms, rawMetrics, dbVersion)
+		populateMetrics(ms, rawMetrics, server, args)
+		populateReplicaChannelMetrics(e, rawMetrics, server, args)
+		populateGroupReplicationMetrics(e, rawMetrics, server, args)
+
+		if args.CustomMetricsConfig != "" {
+			customMetrics, err := loadCustomMetricsConfig(args.CustomMetricsConfig)
+			if err != nil {
+				log.Error(err.Error())
+			} else {
+				populateCustomMetrics(e, db, customMetrics, server, args, func(database string) (dataSource, error) {
+					return openSQLDB(dbutils.GenerateDSN(args, database))
+				})
+			}
+		}
+	}
+	if err = i.Publish(); err != nil {
+		return err
 	}
-	infrautils.FatalIfErr(i.Publish())
 
 	if args.EnableQueryMonitoring {
-		queryperformancemonitoring.PopulateQueryPerformanceMetrics(args, e, i)
+		return queryperformancemonitoring.PopulateQueryPerformanceMetrics(args, e, i)
 	}
+	return nil
 }
@@ -66,8 +66,8 @@ func TestPopulatePartialMetrics(t *testing.T) {
 	}
 
 	var ms = metric.NewSet("eventType", nil)
-	dbVersion := "5.6.0"
-	populatePartialMetrics(ms, rawMetrics, metricDefinition, dbVersion)
+	server := mysqlServer("5.6.0")
+	populatePartialMetrics(ms, rawMetrics, metricDefinition, server)
 
 	assert.Equal(t, 1., ms.Metrics["rawMetric1"])
 	assert.Equal(t, 2., ms.Metrics["rawMetric2"])
@@ -93,11 +93,19 @@ func TestPopulateInventory(t *testing.T) {
 	}
 }
 
+// mysqlServer returns a MySQL community server of the given version.
+func mysqlServer(version string) dbServer {
+	return dbServer{flavor: flavorMySQL, version: version, mysqlVersion: version}
+}
+
 type testdb struct {
-	inventory map[string]interface{}
-	metrics   map[string]interface{}
-	replica   map[string]interface{}
-	version   map[string]interface{}
+	inventory    map[string]interface{}
+	metrics      map[string]interface{}
+	replica      map[string]interface{}
+	version      map[string]interface{}
+	groupMembers []map[string]interface{}
+	innodbStatus map[string]interface{}
+	innodbCounts map[string]interface{}
 }
 
 func (d testdb) close() {}
@@ -114,6 +122,21 @@ func (d testdb) query(query string) (map[string]interface{}, error) {
 	if query == dbVersionQuery {
 		return d.version, nil
 	}
+	if query == innodbStatusQuery {
+		return d.innodbStatus, nil
+	}
+	if query == innodbMetricsQuery {
+		return d.innodbCounts, nil
+	}
+	return nil, nil
+}
+func (d testdb) queryRows(query string) ([]map[string]interface{}, error) {
+	if (query == replicaQueryBelowVersion8Point4 || query == replicaQueryForMariaDB) && len(d.replica) > 0 {
+		return []map[string]interface{}{d.replica}, nil
+	}
+	if query == groupReplicationMembersQuery || query == groupReplicationMembersQueryBelowVersion8 {
+		return d.groupMembers, nil
+	}
 	return nil, nil
 }
 
@@ -131,7 +154,7 @@ func TestGetRawData(t *testing.T) {
 			"version": "5.6.3",
 		},
 	}
-	inventory, metrics, dbVersion, err := getRawData(database)
+	inventory, metrics, server, err := getRawData(database)
 	if err != nil {
 		t.Error()
 	}
@@ -141,7 +164,7 @@ func TestGetRawData(t *testing.T) {
 	if inventory == nil {
 		t.Error()
 	}
-	if dbVersion == "" {
+	if server.version == "" {
 		t.Error()
 	}
 }
@@ -160,10 +183,10 @@ func TestPopulateMetricsWithZeroValuesInData(t *testing.T) {
 		"Key_buffer_size":      0,
 	}
 	ms := metric.NewSet("eventType", nil)
-	dbVersion := "5.6.0"
-	populatePartialMetrics(ms, rawMetrics, getDefaultMetrics(dbVersion), dbVersion)
-	populatePartialMetrics(ms, rawMetrics, getExtendedMetrics(dbVersion), dbVersion)
-	populatePartialMetrics(ms, rawMetrics, myisamMetrics, dbVersion)
+	server := mysqlServer("5.6.0")
+	populatePartialMetrics(ms, rawMetrics, getDefaultMetrics(server), server)
+	populatePartialMetrics(ms, rawMetrics, getExtendedMetrics(server), server)
+	populatePartialMetrics(ms, rawMetrics, myisamMetrics, server)
 
 	testMetrics := []string{"db.qCacheUtilization", "db.qCacheHitRatio", "db.threadCacheMissRate", "db.myisam.keyCacheUtilization"}
 
@@ -194,8 +217,8 @@ func TestPopulateMetricsOfTypePRATE(t *testing.T) {
 	rawMetrics := map[string]interface{}{
 		"Created_tmp_files": 4500,
 	}
-	dbVersion := "5.6.0"
-	populatePartialMetrics(ms, rawMetrics, getExtendedMetrics(dbVersion), dbVersion)
+	server := mysqlServer("5.6.0")
+	populatePartialMetrics(ms, rawMetrics, getExtendedMetrics(server), server)
 	//  db.createdTmpFilesPerSecond metric will be zero because there is no older value for this metric to calculate the PRATE.
 	assert.Equal(t, float64(0), ms.Metrics["db.createdTmpFilesPerSecond"])
 }
@@ -22,6 +22,12 @@ const (
 	*/
 	ExplainQueryFormat = "EXPLAIN FORMAT=JSON %s"
 
+	/*
+		ExplainAnalyzeQueryFormat is a format string used to generate EXPLAIN ANALYZE queries in TREE format, the only one
+		reporting the actual execution figures. Unlike EXPLAIN, EXPLAIN ANALYZE executes the query.
+	*/
+	ExplainAnalyzeQueryFormat = "EXPLAIN ANALYZE FORMAT=TREE %s"
+
 	/*
 		QueryPlanTimeoutDuration sets the timeout for fetching query execution plans.
 		This prevents indefinite waits when a query plan retrieval takes too long, ensuring system responsiveness.
@@ -53,6 +59,60 @@ const (
 		With a configuration interval set at 30 seconds, processing these results can consume significant time and resources.
 	*/
 
+	// DefaultAnalyzeTimeLimit(ms) defines the default maximum execution time of each EXPLAIN ANALYZE statement.
+	DefaultAnalyzeTimeLimit = 500
+
+	/*
+		MaxAnalyzeTimeLimit(ms) limits the execution time of each EXPLAIN ANALYZE statement, which runs the explained query
+		on the monitored server. A few allow-listed queries running for this long still fit in the collection interval.
+	*/
+	MaxAnalyzeTimeLimit = 5000
+
+	// DefaultTrxAgeThreshold(sec) defines the default age from which the open transactions are reported as long-running.
+	DefaultTrxAgeThreshold = 60
+
+	// DefaultTrxIdleThreshold(sec) defines the default idle time from which the sessions with an open transaction are reported as idle in transaction.
+	DefaultTrxIdleThreshold = 30
+
+	// WaitEventsModeSample reports the waits of the running statements, found in the current and recent wait events of the sessions.
+	WaitEventsModeSample = "sample"
+
+	/*
+		WaitEventsModeDelta reports the time waited on each wait event during the interval, as the difference between the
+		counters of the wait event summaries and the ones kept by the previous execution.
+	*/
+	WaitEventsModeDelta = "delta"
+
+	/*
+		IndexAdvisorMinRowsExamined is the number of rows examined per scan of a table from which its full scans, unused
+		possible keys and low filtered ratios are worth an index recommendation. Scanning smaller tables is cheap.
+	*/
+	IndexAdvisorMinRowsExamined = 1000
+
+	// IndexAdvisorLowFilteredPercentage is the percentage of the examined rows of a table kept by its conditions, below which an index may avoid examining them.
+	IndexAdvisorLowFilteredPercentage = 10.0
+
+	// IndexAdvisorMaxIndexColumns limits the number of columns of the recommended composite indexes.
+	IndexAdvisorMaxIndexColumns = 3
+
+	/*
+		IndexRecommendationTTL is the time an index recommendation is not reported again for the same query and table.
+		Once it expires, a recommendation still applying is reported again, so it doesn't fade out of the dashboards.
+	*/
+	IndexRecommendationTTL = 24 * time.Hour
+
+	/*
+		DeadlockTimestampTTL is the time the timestamp of the latest reported deadlock is kept. The latest deadlock stays in the
+		InnoDB status until another one is detected or the server restarts, so it is kept for long to not report it twice.
+	*/
+	DeadlockTimestampTTL = 365 * 24 * time.Hour
+
+	/*
+		QueryPlanFingerprintTTL is the time the last known plan of a digest is kept. A digest may not be sampled for days, so its
+		plan is kept for long to still detect a change when it runs again, instead of taking the new plan as the first one.
+	*/
+	QueryPlanFingerprintTTL = 30 * 24 * time.Hour
+
 	// DefaultQueryCountThreshold defines the default query count limit for fetching grouped slow, wait events and blocking sessions query performance metrics. */
 	DefaultQueryCountThreshold = 20
 
@@ -70,12 +130,6 @@ const (
 	*/
 	IndividualQueryCountThreshold = 10
 
-	/*
-		MinVersionParts defines the minimum number of parts expected when parsing a version string.
-		This ensures version strings are formatted correctly and allows for proper version comparison.
-	*/
-	MinVersionParts = 2
-
 	/*
 		EssentialConsumersCount defines the number of essential consumers that must be enabled
 		in the performance schema to ensure that the necessary performance data is available.
@@ -108,3 +162,26 @@ Excluding these databases by default helps to prevent accidental modifications
 and focuses system operations only on user-defined databases.
 */
 var DefaultExcludedDatabases = []string{"", "mysql", "information_schema", "performance_schema", "sys"}
+
+/*
+DefaultExcludedUsers defines a list of user names that are excluded by default from the account metrics.
+These are the reserved accounts MySQL creates for its internal use, which applications never connect with.
+
+  - "mysql.session": Used internally by plugins to access the server.
+  - "mysql.sys": Used as the DEFINER of the sys schema objects.
+  - "mysql.infoschema": Used as the DEFINER of the information_schema views.
+*/
+var DefaultExcludedUsers = []string{"mysql.session", "mysql.sys", "mysql.infoschema"}
+
+/*
+SupportedExplainStatementTypes defines the statement types whose execution plans can be fetched.
+Plans are fetched within a read-only transaction, so explaining a data-modifying statement never executes it.
+
+  - "SELECT" and "WITH": Queries, including the ones with common table expressions.
+  - "UPDATE" and "DELETE": Single and multiple-table data modifications.
+  - "INSERT" and "REPLACE": Only when they insert the result of a SELECT, as inserting VALUES has a trivial plan.
+*/
+var SupportedExplainStatementTypes = []string{"SELECT", "WITH", "UPDATE", "DELETE", "INSERT", "REPLACE"}
+
+// DefaultExplainStatementTypes defines the statement types whose execution plans are fetched by default. Explaining data-modifying statements is opt-in.
+var DefaultExplainStatementTypes = []string{"SELECT", "WITH"}
@@ -0,0 +1,139 @@
+package performancemetricscollectors
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	infrautils "github.com/newrelic/nri-mysql/src/infrautils"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
+)
+
+const accountStoreName = "accounts"
+
+/*
+PopulateAccountMetrics reports the current connections per account (user@host), and the connections and statements of
+the accounts during the interval, for the accounts with the most current connections.
+*/
+func PopulateAccountMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedUsers []string) {
+	store, err := infrautils.NewStore(args, accountStoreName)
+	if err != nil {
+		log.Warn("Account metrics are not reported: %v", err)
+		return
+	}
+	populateAccountMetrics(db, i, args, excludedUsers, store)
+}
+
+func populateAccountMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedUsers []string, store persist.Storer) {
+	// Prepare the SQL query with the provided parameters
+	query, inputArgs, err := sqlx.In(utils.AccountsQuery, excludedUsers)
+	if err != nil {
+		log.Error("Failed to prepare account metrics query: %v", err)
+		return
+	}
+
+	accountCounters, err := utils.CollectMetrics[utils.AccountCounters](db, query, inputArgs...)
+	if err != nil {
+		log.Error("Error collecting account metrics: %v", err)
+		return
+	}
+
+	// The connections and the statements of each account, the statement time being in picoseconds
+	current := make(map[string]counters, len(accountCounters))
+	for _, row := range accountCounters {
+		current[accountKey(row)] = newCounters(row)
+	}
+	previous, intervalSec, ok := swapIntervalCounters(store, current)
+	if !ok {
+		return
+	}
+
+	queryCountThreshold := validator.GetValidQueryCountThreshold(args.QueryMonitoringCountThreshold)
+	metrics := accountDeltas(accountCounters, current, previous, queryCountThreshold, intervalSec, time.Now().UTC().Format(time.RFC3339))
+	if len(metrics) == 0 {
+		return
+	}
+
+	// Set the account metrics in the integration entity and ingest them
+	if err = setAccountMetrics(metrics, i, args); err != nil {
+		log.Error("Error setting account metrics: %v", err)
+		return
+	}
+}
+
+// accountKey identifies an account by its user and host, as they are shown by the server.
+func accountKey(row utils.AccountCounters) string {
+	return fmt.Sprintf("'%s'@'%s'", row.User, row.Host)
+}
+
+/*
+accountDeltas returns the deltas of the accounts with the most current connections, then with the most connections
+during the interval, which are the ones causing a connection storm.
+*/
+func accountDeltas(accountCounters []utils.AccountCounters, current, previous map[string]counters,
+	limit int, intervalSec int64, collectionTimestamp string) []utils.AccountMetrics {
+	metrics := make([]utils.AccountMetrics, 0, len(accountCounters))
+	for _, row := range accountCounters {
+		key := accountKey(row)
+		previousCounter, found := previous[key]
+		delta := current[key].delta(previousCounter, found)
+
+		avgStatementLatencyMs := 0.0
+		if delta["statement_count"] > 0 {
+			avgStatementLatencyMs = roundMs(float64(delta["statement_timer"]) / float64(delta["statement_count"]) / picosecondsPerMillisecond)
+		}
+		metrics = append(metrics, utils.AccountMetrics{
+			User:                    row.User,
+			Host:                    row.Host,
+			CurrentConnections:      row.CurrentConnections,
+			TotalConnections:        delta["total_connections"],
+			UserCurrentConnections:  row.UserCurrentConnections,
+			UserTotalConnections:    delta["user_total_connections"],
+			HostCurrentConnections:  row.HostCurrentConnections,
+			HostTotalConnections:    delta["host_total_connections"],
+			StatementCount:          delta["statement_count"],
+			StatementErrorCount:     delta["statement_error_count"],
+			TotalStatementLatencyMs: roundMs(float64(delta["statement_timer"]) / picosecondsPerMillisecond),
+			AvgStatementLatencyMs:   avgStatementLatencyMs,
+			RowsExamined:            delta["rows_examined"],
+			IntervalSec:             intervalSec,
+			CollectionTimestamp:     collectionTimestamp,
+		})
+	}
+
+	// The accounts are sorted from the most connections, and by name for the same connections
+	slices.SortFunc(metrics, func(a, b utils.AccountMetrics) int {
+		if order := cmp.Compare(b.CurrentConnections, a.CurrentConnections); order != 0 {
+			return order
+		}
+		if order := cmp.Compare(b.TotalConnections, a.TotalConnections); order != 0 {
+			return order
+		}
+		if order := strings.Compare(a.User, b.User); order != 0 {
+			return order
+		}
+		return strings.Compare(a.Host, b.Host)
+	})
+	if len(metrics) > limit {
+		metrics = metrics[:limit]
+	}
+	return metrics
+}
+
+// setAccountMetrics sets the account metrics into the integration entity.
+func setAccountMetrics(metrics []utils.AccountMetrics, i *integration.Integration, args arguments.ArgumentList) error {
+	metricList := make([]interface{}, 0, len(metrics))
+	for _, metricData := range metrics {
+		metricList = append(metricList, metricData)
+	}
+
+	return utils.IngestMetric(metricList, "MysqlAccountSample", i, args)
+}
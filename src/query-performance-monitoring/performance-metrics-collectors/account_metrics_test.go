@@ -0,0 +1,88 @@
+package performancemetricscollectors
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPopulateAccountMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	dataSource := &DataSource{DB: sqlx.NewDb(db, "sqlmock")}
+	store := persist.NewInMemoryStore()
+	args := arguments.ArgumentList{QueryMonitoringCountThreshold: 2}
+	excludedUsers := []string{"mysql.sys", "monitoring"}
+	preparedQuery, preparedArgs, err := sqlx.In(utils.AccountsQuery, excludedUsers)
+	require.NoError(t, err)
+	columns := []string{"user", "host", "current_connections", "total_connections", "user_current_connections", "user_total_connections",
+		"host_current_connections", "host_total_connections", "statement_count", "statement_error_count", "statement_timer", "rows_examined"}
+
+	// The first execution only keeps the counters
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+	mock.ExpectQuery(regexp.QuoteMeta(preparedQuery)).WithArgs(convertToDriverValue(preparedArgs)...).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("app", "10.0.0.1", 150, 9000, 170, 12000, 160, 9500, 250000, 12, 5000000000000, 1000000).
+			AddRow("reporting", "10.0.0.2", 2, 40, 2, 40, 3, 41, 100, 0, 2000000000000, 500000).
+			AddRow("batch", "10.0.0.3", 1, 10, 1, 10, 1, 10, 50, 0, 100000000000, 1000))
+	populateAccountMetrics(dataSource, i, args, excludedUsers, store)
+	assert.Empty(t, e.Metrics)
+
+	i, err = integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e = i.LocalEntity()
+	mock.ExpectQuery(regexp.QuoteMeta(preparedQuery)).WithArgs(convertToDriverValue(preparedArgs)...).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("app", "10.0.0.1", 300, 9400, 320, 12400, 310, 9900, 252000, 15, 5004000000000, 1200000).
+			AddRow("reporting", "10.0.0.2", 2, 40, 2, 40, 3, 41, 110, 0, 2001000000000, 510000).
+			AddRow("batch", "10.0.0.3", 1, 10, 1, 10, 1, 10, 50, 0, 100000000000, 1000))
+	populateAccountMetrics(dataSource, i, args, excludedUsers, store)
+
+	// Only the two accounts with the most connections are reported
+	require.Len(t, e.Metrics, 2)
+	app := e.Metrics[0].Metrics
+	assert.Equal(t, "MysqlAccountSample", app["event_type"])
+	assert.Equal(t, "app", app["user"])
+	assert.Equal(t, "10.0.0.1", app["host"])
+	assert.InDelta(t, 300.0, app["current_connections"], 0.001)
+	assert.InDelta(t, 400.0, app["total_connections"], 0.001)
+	assert.InDelta(t, 320.0, app["user_current_connections"], 0.001)
+	assert.InDelta(t, 400.0, app["user_total_connections"], 0.001)
+	assert.InDelta(t, 2000.0, app["statement_count"], 0.001)
+	assert.InDelta(t, 3.0, app["statement_error_count"], 0.001)
+	assert.InDelta(t, 4.0, app["total_statement_latency_ms"], 0.001)
+	assert.InDelta(t, 0.002, app["avg_statement_latency_ms"], 0.0001)
+	assert.InDelta(t, 200000.0, app["rows_examined"], 0.001)
+	assert.Equal(t, "reporting", e.Metrics[1].Metrics["user"])
+	assert.InDelta(t, 10.0, e.Metrics[1].Metrics["statement_count"], 0.001)
+	assert.InDelta(t, 0.0, e.Metrics[1].Metrics["total_connections"], 0.001)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPopulateAccountMetricsQueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	dataSource := &DataSource{DB: sqlx.NewDb(db, "sqlmock")}
+	i, err := integration.New("test-integration", "1.0.0")
+	require.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.accounts")).WillReturnError(errQuery)
+
+	populateAccountMetrics(dataSource, i, arguments.ArgumentList{}, []string{"mysql.sys"}, persist.NewInMemoryStore())
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Empty(t, i.Entities)
+}
@@ -0,0 +1,171 @@
+package performancemetricscollectors
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+)
+
+// blockingChainNode is the position of a session in the lock-wait graph: the root blocker of its longest chain and its depth in it.
+type blockingChainNode struct {
+	root  string
+	depth int64
+	cycle bool
+}
+
+/*
+blockingGraph is the lock-wait graph built from the blocked/blocking pairs, where each session points to the sessions
+it waits for. Sessions are identified by their processlist id.
+*/
+type blockingGraph struct {
+	blockers map[string][]string
+	nodes    map[string]blockingChainNode
+	visiting map[string]bool
+}
+
+func newBlockingGraph(metrics []utils.BlockingSessionMetrics) *blockingGraph {
+	graph := &blockingGraph{blockers: map[string][]string{}, nodes: map[string]blockingChainNode{}, visiting: map[string]bool{}}
+	for _, pair := range metrics {
+		if pair.BlockedPID == nil || pair.BlockingPID == nil {
+			continue
+		}
+		if !slices.Contains(graph.blockers[*pair.BlockedPID], *pair.BlockingPID) {
+			graph.blockers[*pair.BlockedPID] = append(graph.blockers[*pair.BlockedPID], *pair.BlockingPID)
+		}
+	}
+	// The sessions and their blockers are visited in order, so the same graph always resolves to the same chains
+	for _, blockers := range graph.blockers {
+		slices.Sort(blockers)
+	}
+	for _, pid := range graph.blockedSessions() {
+		graph.resolve(pid)
+	}
+	return graph
+}
+
+// blockedSessions returns the sessions waiting for another one, in order.
+func (g *blockingGraph) blockedSessions() []string {
+	pids := make([]string, 0, len(g.blockers))
+	for pid := range g.blockers {
+		pids = append(pids, pid)
+	}
+	slices.Sort(pids)
+	return pids
+}
+
+/*
+resolve returns the root blocker of the longest chain of a session and its depth in it, the root blockers being the
+sessions which don't wait for any other one. When a chain loops back to a session, the cycle is broken at it, so
+the session becomes the root of the cycle.
+*/
+func (g *blockingGraph) resolve(pid string) blockingChainNode {
+	if node, ok := g.nodes[pid]; ok {
+		return node
+	}
+	if g.visiting[pid] {
+		return blockingChainNode{root: pid, cycle: true}
+	}
+	g.visiting[pid] = true
+	defer delete(g.visiting, pid)
+
+	node := blockingChainNode{root: pid}
+	for _, blocker := range g.blockers[pid] {
+		blockerNode := g.resolve(blocker)
+		if blockerNode.cycle && blockerNode.root == pid {
+			// The cycle closes on this session
+			node.cycle = true
+			continue
+		}
+		if blockerNode.depth+1 > node.depth {
+			node = blockingChainNode{root: blockerNode.root, depth: blockerNode.depth + 1, cycle: blockerNode.cycle || node.cycle}
+		}
+	}
+	g.nodes[pid] = node
+	return node
+}
+
+// setBlockingChains sets the root blocker of the chain of each pair, and the depth of the blocked session in the chain.
+func setBlockingChains(metrics []utils.BlockingSessionMetrics, graph *blockingGraph) {
+	for index := range metrics {
+		pair := &metrics[index]
+		if pair.BlockedPID == nil || pair.BlockingPID == nil {
+			continue
+		}
+		blockingNode := graph.resolve(*pair.BlockingPID)
+		depth := blockingNode.depth + 1
+		cycle := fmt.Sprintf("%t", blockingNode.cycle)
+		pair.RootBlockingPID = &blockingNode.root
+		pair.BlockingChainDepth = &depth
+		pair.BlockingCycle = &cycle
+	}
+}
+
+/*
+blockingChains summarizes the sessions waiting for each root blocker: the ones waiting for it directly, all of the
+ones waiting for it directly or transitively, the depth of the longest chain and the total time the sessions have
+been blocked. Each blocked session is counted once, under the root of its longest chain.
+*/
+func blockingChains(metrics []utils.BlockingSessionMetrics, graph *blockingGraph) []utils.BlockingChainMetrics {
+	blockedTimes := map[string]float64{}
+	roots := map[string]*utils.BlockingChainMetrics{}
+	var rootOrder []string
+	for _, pair := range metrics {
+		if pair.BlockedPID == nil || pair.BlockingPID == nil {
+			continue
+		}
+		if pair.BlockedQueryTimeMs != nil {
+			blockedTimes[*pair.BlockedPID] = max(blockedTimes[*pair.BlockedPID], *pair.BlockedQueryTimeMs)
+		}
+
+		root := graph.resolve(*pair.BlockedPID).root
+		if _, ok := roots[root]; !ok {
+			roots[root] = &utils.BlockingChainMetrics{RootBlockingPID: &root, BlockingCycle: "false", CollectionTimestamp: pair.CollectionTimestamp}
+			rootOrder = append(rootOrder, root)
+		}
+		// The details of the root blocker are taken from a pair where it is the blocking session
+		if *pair.BlockingPID == root && roots[root].RootBlockingThreadID == nil {
+			roots[root].RootBlockingThreadID = pair.BlockingThreadID
+			roots[root].RootBlockingHost = pair.BlockingHost
+			roots[root].RootBlockingStatus = pair.BlockingStatus
+			roots[root].RootBlockingQueryID = pair.BlockingQueryID
+			roots[root].RootBlockingQuery = pair.BlockingQuery
+		}
+	}
+
+	for _, pid := range graph.blockedSessions() {
+		node := graph.resolve(pid)
+		chain := roots[node.root]
+		if node.cycle {
+			chain.BlockingCycle = "true"
+		}
+		if pid == node.root {
+			// The root of a cycle also waits for the sessions of the cycle
+			continue
+		}
+		chain.TotalBlockedSessions++
+		chain.MaxChainDepth = max(chain.MaxChainDepth, node.depth)
+		chain.TotalBlockedTimeMs += blockedTimes[pid]
+		if slices.Contains(graph.blockers[pid], node.root) {
+			chain.DirectlyBlockedSessions++
+		}
+	}
+
+	chains := make([]utils.BlockingChainMetrics, 0, len(rootOrder))
+	for _, root := range rootOrder {
+		chains = append(chains, *roots[root])
+	}
+	return chains
+}
+
+// setBlockingChainMetrics sets the blocking chain metrics into the integration entity.
+func setBlockingChainMetrics(metrics []utils.BlockingChainMetrics, i *integration.Integration, args arguments.ArgumentList) error {
+	metricList := make([]interface{}, 0, len(metrics))
+	for _, metricData := range metrics {
+		metricList = append(metricList, metricData)
+	}
+
+	return utils.IngestMetric(metricList, "MysqlBlockingChainSample", i, args)
+}
@@ -0,0 +1,92 @@
+package performancemetricscollectors
+
+import (
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func blockingPair(blockedPID, blockingPID string, blockedTimeMs float64) utils.BlockingSessionMetrics {
+	return utils.BlockingSessionMetrics{
+		BlockedPID:         ptr(blockedPID),
+		BlockingPID:        ptr(blockingPID),
+		BlockingQuery:      ptr("UPDATE orders SET status = 'paid' WHERE id = " + blockingPID),
+		BlockedQueryTimeMs: ptr(blockedTimeMs),
+	}
+}
+
+func TestBlockingChains(t *testing.T) {
+	// 1 blocks 2 and 3, 2 blocks 4, which also waits for 3, and 5 blocks 6
+	metrics := []utils.BlockingSessionMetrics{
+		blockingPair("2", "1", 1000),
+		blockingPair("3", "1", 800),
+		blockingPair("4", "2", 300),
+		blockingPair("4", "3", 300),
+		blockingPair("6", "5", 50),
+	}
+	graph := newBlockingGraph(metrics)
+	setBlockingChains(metrics, graph)
+
+	assert.Equal(t, "1", *metrics[0].RootBlockingPID)
+	assert.Equal(t, int64(1), *metrics[0].BlockingChainDepth)
+	assert.Equal(t, "1", *metrics[2].RootBlockingPID)
+	assert.Equal(t, int64(2), *metrics[2].BlockingChainDepth)
+	assert.Equal(t, "false", *metrics[2].BlockingCycle)
+	assert.Equal(t, "5", *metrics[4].RootBlockingPID)
+
+	chains := blockingChains(metrics, graph)
+	require.Len(t, chains, 2)
+	assert.Equal(t, "1", *chains[0].RootBlockingPID)
+	assert.Equal(t, int64(2), chains[0].DirectlyBlockedSessions)
+	assert.Equal(t, int64(3), chains[0].TotalBlockedSessions)
+	assert.Equal(t, int64(2), chains[0].MaxChainDepth)
+	assert.Equal(t, 2100.0, chains[0].TotalBlockedTimeMs)
+	assert.Equal(t, "UPDATE orders SET status = 'paid' WHERE id = 1", *chains[0].RootBlockingQuery)
+	assert.Equal(t, "5", *chains[1].RootBlockingPID)
+	assert.Equal(t, int64(1), chains[1].TotalBlockedSessions)
+}
+
+func TestBlockingChainsWithCycle(t *testing.T) {
+	// 1 and 2 wait for each other, and 3 waits for 2
+	metrics := []utils.BlockingSessionMetrics{
+		blockingPair("1", "2", 100),
+		blockingPair("2", "1", 200),
+		blockingPair("3", "2", 50),
+	}
+	graph := newBlockingGraph(metrics)
+	setBlockingChains(metrics, graph)
+
+	for _, pair := range metrics {
+		assert.Equal(t, "1", *pair.RootBlockingPID)
+		assert.Equal(t, "true", *pair.BlockingCycle)
+	}
+	assert.Equal(t, int64(2), *metrics[2].BlockingChainDepth)
+
+	chains := blockingChains(metrics, graph)
+	require.Len(t, chains, 1)
+	assert.Equal(t, "1", *chains[0].RootBlockingPID)
+	assert.Equal(t, "true", chains[0].BlockingCycle)
+	assert.Equal(t, int64(1), chains[0].DirectlyBlockedSessions)
+	assert.Equal(t, int64(2), chains[0].TotalBlockedSessions)
+	assert.Equal(t, int64(2), chains[0].MaxChainDepth)
+	assert.Equal(t, 250.0, chains[0].TotalBlockedTimeMs)
+}
+
+func TestSetBlockingChainMetrics(t *testing.T) {
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+
+	metrics := []utils.BlockingSessionMetrics{blockingPair("2", "1", 1000)}
+	err = setBlockingChainMetrics(blockingChains(metrics, newBlockingGraph(metrics)), i, arguments.ArgumentList{})
+	assert.NoError(t, err)
+	require.Len(t, e.Metrics, 1)
+	assert.Equal(t, "MysqlBlockingChainSample", e.Metrics[0].Metrics["event_type"])
+	assert.Equal(t, "1", e.Metrics[0].Metrics["root_blocking_pid"])
+	assert.Equal(t, float64(1), e.Metrics[0].Metrics["total_blocked_sessions"])
+	assert.Equal(t, "UPDATE orders SET status = ? WHERE id = ?", e.Metrics[0].Metrics["root_blocking_query"])
+}
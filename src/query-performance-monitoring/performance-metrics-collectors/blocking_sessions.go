@@ -1,7 +1,9 @@
 package performancemetricscollectors
 
 import (
-	"github.com/jmoiron/sqlx"
+	"slices"
+	"strings"
+
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 	arguments "github.com/newrelic/nri-mysql/src/args"
@@ -9,20 +11,21 @@ import (
 	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
 )
 
-// PopulateBlockingSessionMetrics retrieves blocking session metrics from the database and populates them into the integration entity.
-func PopulateBlockingSessionMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string) {
-	// Get the query count threshold
-	queryCountThreshold := validator.GetValidQueryCountThreshold(args.QueryMonitoringCountThreshold)
-
-	// Prepare the SQL query with the provided parameters
-	query, inputArgs, err := sqlx.In(utils.BlockingSessionsQuery, excludedDatabases, queryCountThreshold)
-	if err != nil {
-		log.Error("Failed to prepare blocking sessions query: %v", err)
-		return
+/*
+PopulateBlockingSessionMetrics retrieves blocking session metrics from the database and populates them into the integration entity.
+The servers without performance_schema.data_lock_waits, MySQL 5.7 and MariaDB, report them from the InnoDB lock waits of information_schema.
+*/
+func PopulateBlockingSessionMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string, capabilities validator.Capabilities) {
+	blockingSessionsQuery := utils.BlockingSessionsQuery
+	switch {
+	case capabilities.MariaDB:
+		blockingSessionsQuery = utils.BlockingSessionsQueryForMariaDB
+	case !capabilities.DataLockWaits:
+		blockingSessionsQuery = utils.BlockingSessionsQueryBelowVersion8
 	}
 
-	// Collect the blocking session metrics
-	metrics, err := utils.CollectMetrics[utils.BlockingSessionMetrics](db, query, inputArgs...)
+	// Collect all of the blocked/blocking pairs, which make up the lock-wait graph
+	metrics, err := utils.CollectMetrics[utils.BlockingSessionMetrics](db, blockingSessionsQuery)
 	if err != nil {
 		log.Error("Error collecting blocking session metrics: %v", err)
 		return
@@ -33,14 +36,48 @@ func PopulateBlockingSessionMetrics(db utils.DataSource, i *integration.Integrat
 		return
 	}
 
+	// Build the lock-wait graph to find the root blocker of each chain, before any pair is left out
+	graph := newBlockingGraph(metrics)
+	setBlockingChains(metrics, graph)
+	chains := blockingChains(metrics, graph)
+
 	// Set the blocking query metrics in the integration entity and ingest them
-	err = setBlockingQueryMetrics(metrics, i, args)
+	sessions := reportedBlockingSessions(metrics, excludedDatabases, validator.GetValidQueryCountThreshold(args.QueryMonitoringCountThreshold))
+	if len(sessions) > 0 {
+		err = setBlockingQueryMetrics(sessions, i, args)
+		if err != nil {
+			log.Error("Error setting blocking session metrics: %v", err)
+			return
+		}
+	}
+
+	err = setBlockingChainMetrics(chains, i, args)
 	if err != nil {
-		log.Error("Error setting blocking session metrics: %v", err)
+		log.Error("Error setting blocking chain metrics: %v", err)
 		return
 	}
 }
 
+/*
+reportedBlockingSessions returns the pairs whose blocked session is in a database that isn't excluded, up to the limit.
+The pairs are kept in their order, from the oldest blocked transaction.
+*/
+func reportedBlockingSessions(metrics []utils.BlockingSessionMetrics, excludedDatabases []string, limit int) []utils.BlockingSessionMetrics {
+	sessions := make([]utils.BlockingSessionMetrics, 0, min(len(metrics), limit))
+	for _, pair := range metrics {
+		if len(sessions) == limit {
+			break
+		}
+		if pair.BlockedDB == nil || slices.ContainsFunc(excludedDatabases, func(excludedDatabase string) bool {
+			return strings.EqualFold(excludedDatabase, *pair.BlockedDB)
+		}) {
+			continue
+		}
+		sessions = append(sessions, pair)
+	}
+	return sessions
+}
+
 // setBlockingQueryMetrics sets the blocking session metrics into the integration entity.
 func setBlockingQueryMetrics(metrics []utils.BlockingSessionMetrics, i *integration.Integration, args arguments.ArgumentList) error {
 	metricList := make([]interface{}, 0, len(metrics))
@@ -2,7 +2,6 @@ package performancemetricscollectors
 
 import (
 	"context"
-	"database/sql/driver"
 	"errors"
 	"regexp"
 	"testing"
@@ -13,12 +12,16 @@ import (
 
 	arguments "github.com/newrelic/nri-mysql/src/args"
 	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 var (
 	errQuery = errors.New("query error")
+	// mysql8Capabilities are the capabilities of a recent MySQL 8.0 server
+	mysql8Capabilities = validator.Capabilities{Version: "8.0.36", PerformanceSchema: true, StatementCPUTime: true, LatencyHistograms: true,
+		CommonTableExpressions: true, DataLockWaits: true, ExplainAnalyze: true}
 )
 
 // ptr returns a pointer to the value passed in.
@@ -63,15 +66,15 @@ func TestPopulateBlockingSessionMetrics(t *testing.T) {
 	queryCountThreshold := 10
 
 	t.Run("ErrorCollectingMetrics", func(t *testing.T) {
-		testErrorCollectingMetrics(t, sqlxDB, mock, excludedDatabases, queryCountThreshold)
+		testErrorCollectingMetrics(t, sqlxDB, mock)
 	})
 
 	t.Run("NoMetricsCollected", func(t *testing.T) {
-		testNoMetricsCollected(t, sqlxDB, mock, excludedDatabases, queryCountThreshold)
+		testNoMetricsCollected(t, sqlxDB, mock)
 	})
 
 	t.Run("SuccessfulMetricsCollection", func(t *testing.T) {
-		testSuccessfulMetricsCollection(t, sqlxDB, mock, excludedDatabases, queryCountThreshold)
+		testSuccessfulMetricsCollection(t, sqlxDB, mock)
 	})
 
 	t.Run("PopulateBlockingSessionMetrics", func(t *testing.T) {
@@ -79,51 +82,29 @@ func TestPopulateBlockingSessionMetrics(t *testing.T) {
 	})
 }
 
-func testErrorCollectingMetrics(t *testing.T, sqlxDB *sqlx.DB, mock sqlmock.Sqlmock, excludedDatabases []string, queryCountThreshold int) {
-	query, inputArgs, err := sqlx.In(utils.BlockingSessionsQuery, excludedDatabases, queryCountThreshold)
-	assert.NoError(t, err)
-
-	query = sqlxDB.Rebind(query)
-
-	driverArgs := make([]driver.Value, len(inputArgs))
-	for i, v := range inputArgs {
-		driverArgs[i] = driver.Value(v)
-	}
-	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(driverArgs...).WillReturnError(errQuery)
+func testErrorCollectingMetrics(t *testing.T, sqlxDB *sqlx.DB, mock sqlmock.Sqlmock) {
+	query := utils.BlockingSessionsQuery
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnError(errQuery)
 
 	dataSource := &dbWrapper{DB: sqlxDB}
-	_, err = utils.CollectMetrics[utils.BlockingSessionMetrics](dataSource, query, inputArgs...)
+	_, err := utils.CollectMetrics[utils.BlockingSessionMetrics](dataSource, query)
 	assert.Error(t, err, "Expected error collecting metrics, got nil")
 }
 
-func testNoMetricsCollected(t *testing.T, sqlxDB *sqlx.DB, mock sqlmock.Sqlmock, excludedDatabases []string, queryCountThreshold int) {
-	query, inputArgs, err := sqlx.In(utils.BlockingSessionsQuery, excludedDatabases, queryCountThreshold)
-	assert.NoError(t, err)
-
-	query = sqlxDB.Rebind(query)
-	driverArgs := make([]driver.Value, len(inputArgs))
-	for i, v := range inputArgs {
-		driverArgs[i] = driver.Value(v)
-	}
-	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(driverArgs...).WillReturnRows(sqlmock.NewRows(nil))
+func testNoMetricsCollected(t *testing.T, sqlxDB *sqlx.DB, mock sqlmock.Sqlmock) {
+	query := utils.BlockingSessionsQuery
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(sqlmock.NewRows(nil))
 
 	dataSource := &dbWrapper{DB: sqlxDB}
-	metrics, err := utils.CollectMetrics[utils.BlockingSessionMetrics](dataSource, query, inputArgs...)
+	metrics, err := utils.CollectMetrics[utils.BlockingSessionMetrics](dataSource, query)
 	assert.NoError(t, err)
 	assert.Empty(t, metrics)
 }
 
-func testSuccessfulMetricsCollection(t *testing.T, sqlxDB *sqlx.DB, mock sqlmock.Sqlmock, excludedDatabases []string, queryCountThreshold int) {
-	query, inputArgs, err := sqlx.In(utils.BlockingSessionsQuery, excludedDatabases, queryCountThreshold)
-	assert.NoError(t, err)
-
-	query = sqlxDB.Rebind(query)
-	driverArgs := make([]driver.Value, len(inputArgs))
-	for i, v := range inputArgs {
-		driverArgs[i] = driver.Value(v)
-	}
+func testSuccessfulMetricsCollection(t *testing.T, sqlxDB *sqlx.DB, mock sqlmock.Sqlmock) {
+	query := utils.BlockingSessionsQuery
 
-	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(driverArgs...).WillReturnRows(sqlmock.NewRows([]string{
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(sqlmock.NewRows([]string{
 		"blocked_txn_id", "blocked_pid", "blocked_thread_id", "blocked_query_id", "blocked_query", "blocked_status", "blocked_host", "database_name", "blocking_txn_id", "blocking_pid", "blocking_thread_id", "blocking_status", "blocking_host", "blocking_query_id", "blocking_query",
 	}).AddRow(
 		"blocked_txn_id_1", "blocked_pid_1", 123, "blocked_query_id_1", "blocked_query_1", "blocked_status_1", "blocked_host_1", "database_name_1", "blocking_txn_id_1", "blocking_pid_1", 456, "blocking_status_1", "blocking_host_1", "blocking_query_id_1", "blocking_query_1",
@@ -132,22 +113,15 @@ func testSuccessfulMetricsCollection(t *testing.T, sqlxDB *sqlx.DB, mock sqlmock
 	))
 
 	dataSource := &dbWrapper{DB: sqlxDB}
-	metrics, err := utils.CollectMetrics[utils.BlockingSessionMetrics](dataSource, query, inputArgs...)
+	metrics, err := utils.CollectMetrics[utils.BlockingSessionMetrics](dataSource, query)
 	assert.NoError(t, err)
 	assert.Len(t, metrics, 2)
 }
 
 func testPopulateBlockingSessionMetrics(t *testing.T, sqlxDB *sqlx.DB, mock sqlmock.Sqlmock, excludedDatabases []string, queryCountThreshold int) {
-	query, inputArgs, err := sqlx.In(utils.BlockingSessionsQuery, excludedDatabases, queryCountThreshold)
-	assert.NoError(t, err)
-
-	query = sqlxDB.Rebind(query)
-	driverArgs := make([]driver.Value, len(inputArgs))
-	for i, v := range inputArgs {
-		driverArgs[i] = driver.Value(v)
-	}
+	query := utils.BlockingSessionsQuery
 
-	mock.ExpectQuery(regexp.QuoteMeta(query)).WithArgs(driverArgs...).WillReturnRows(sqlmock.NewRows([]string{
+	mock.ExpectQuery(regexp.QuoteMeta(query)).WillReturnRows(sqlmock.NewRows([]string{
 		"blocked_txn_id", "blocked_pid", "blocked_thread_id", "blocked_query_id", "blocked_query",
 		"blocked_status", "blocked_host", "database_name", "blocking_txn_id", "blocking_pid",
 		"blocking_thread_id", "blocking_status", "blocking_host", "blocking_query_id", "blocking_query",
@@ -165,7 +139,7 @@ func testPopulateBlockingSessionMetrics(t *testing.T, sqlxDB *sqlx.DB, mock sqlm
 	i, _ := integration.New("test", "1.0.0")
 	argList := arguments.ArgumentList{QueryMonitoringCountThreshold: queryCountThreshold}
 
-	PopulateBlockingSessionMetrics(dataSource, i, argList, excludedDatabases)
+	PopulateBlockingSessionMetrics(dataSource, i, argList, excludedDatabases, mysql8Capabilities)
 
 	assert.Len(t, i.LocalEntity().Metrics, 0)
 }
@@ -211,3 +185,81 @@ func TestSetBlockingQueryMetrics(t *testing.T) {
 	assert.Equal(t, "blocking_query_id", ms.Metrics["blocking_query_id"])
 	assert.Equal(t, "blocking_query", ms.Metrics["blocking_query"])
 }
+
+func TestPopulateBlockingSessionMetricsWithoutDataLockWaits(t *testing.T) {
+	tests := []struct {
+		version  string
+		query    string
+		expected float64
+	}{
+		{"5.7.44-log", "wp.TIME * 1000 AS blocked_query_time_ms", 5000},
+		{"10.6.16-MariaDB", "ROUND(wp.TIME_MS, 3) AS blocked_query_time_ms", 5012.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			i, err := integration.New("test", "1.0.0")
+			assert.NoError(t, err)
+			e := i.LocalEntity()
+			mock.ExpectQuery(regexp.QuoteMeta(tt.query)).
+				WillReturnRows(sqlmock.NewRows([]string{"blocked_txn_id", "blocked_pid", "blocked_query", "blocked_query_time_ms", "database_name", "blocking_txn_id", "blocking_pid", "blocking_query"}).
+					AddRow("421", "12", "UPDATE accounts SET balance = 0 WHERE id = 1", tt.expected, "bank", "420", "10", nil))
+			capabilities, err := validator.GetCapabilities(tt.version)
+			assert.NoError(t, err)
+			PopulateBlockingSessionMetrics(&DataSource{DB: sqlx.NewDb(db, "sqlmock")}, i, arguments.ArgumentList{QueryMonitoringCountThreshold: 20}, []string{"mysql"}, capabilities)
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+			assert.Len(t, e.Metrics, 1)
+			assert.Equal(t, "MysqlBlockingSessionSample", e.Metrics[0].Metrics["event_type"])
+			// The statement is reported without its literals, even though no redaction policy is configured
+			assert.Equal(t, "UPDATE accounts SET balance = ? WHERE id = ?", e.Metrics[0].Metrics["blocked_query"])
+			assert.Equal(t, tt.expected, e.Metrics[0].Metrics["blocked_query_time_ms"])
+			assert.Equal(t, "10", e.Metrics[0].Metrics["root_blocking_pid"])
+		})
+	}
+}
+
+func TestPopulateBlockingSessionMetricsChainsBeforeLimit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	i, err := integration.New("test", "1.0.0")
+	assert.NoError(t, err)
+	e := i.LocalEntity()
+	// 10 blocks 11 in an excluded database, which blocks 12, which blocks 13, and 21 waits without a default database
+	mock.ExpectQuery(regexp.QuoteMeta(utils.BlockingSessionsQuery)).
+		WillReturnRows(sqlmock.NewRows([]string{"blocked_pid", "database_name", "blocking_pid", "blocked_query_time_ms"}).
+			AddRow("11", "mysql", "10", 9000.0).
+			AddRow("21", nil, "20", 8000.0).
+			AddRow("12", "shop", "11", 7000.0).
+			AddRow("13", "shop", "12", 6000.0))
+	PopulateBlockingSessionMetrics(&DataSource{DB: sqlx.NewDb(db, "sqlmock")}, i, arguments.ArgumentList{QueryMonitoringCountThreshold: 1}, []string{"mysql"}, mysql8Capabilities)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Only one session is reported, but its chain is resolved up to the root blocker through the excluded database
+	assert.Len(t, e.Metrics, 1)
+	assert.Equal(t, "MysqlBlockingSessionSample", e.Metrics[0].Metrics["event_type"])
+	assert.Equal(t, "12", e.Metrics[0].Metrics["blocked_pid"])
+	assert.Equal(t, "10", e.Metrics[0].Metrics["root_blocking_pid"])
+	assert.Equal(t, float64(2), e.Metrics[0].Metrics["blocking_chain_depth"])
+}
+
+func TestReportedBlockingSessions(t *testing.T) {
+	metrics := []utils.BlockingSessionMetrics{
+		{BlockedPID: ptr("11"), BlockedDB: ptr("MySQL")},
+		{BlockedPID: ptr("21")},
+		{BlockedPID: ptr("12"), BlockedDB: ptr("shop")},
+		{BlockedPID: ptr("13"), BlockedDB: ptr("shop")},
+	}
+	sessions := reportedBlockingSessions(metrics, []string{"mysql"}, 20)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, "12", *sessions[0].BlockedPID)
+	assert.Equal(t, "13", *sessions[1].BlockedPID)
+	assert.Len(t, reportedBlockingSessions(metrics, []string{"mysql"}, 1), 1)
+	assert.Empty(t, reportedBlockingSessions(metrics, []string{"mysql"}, 0))
+}
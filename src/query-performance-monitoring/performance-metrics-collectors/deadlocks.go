@@ -0,0 +1,297 @@
+package performancemetricscollectors
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	infrautils "github.com/newrelic/nri-mysql/src/infrautils"
+	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/constants"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+)
+
+const (
+	deadlockStoreName    = "deadlocks"
+	latestDeadlockKey    = "latest_deadlock"
+	deadlockSectionTitle = "LATEST DETECTED DEADLOCK"
+	lockDescriptionsSep  = "; "
+
+	// Parts of the description of a transaction of the deadlock.
+	deadlockTrxSection     = "transaction"
+	deadlockHoldsSection   = "holds"
+	deadlockWaitingSection = "waiting"
+)
+
+// Patterns of the lines of the LATEST DETECTED DEADLOCK section of the InnoDB status.
+var (
+	sectionDividerPattern      = regexp.MustCompile(`^-+$`)
+	deadlockTimestampPattern   = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})`)
+	deadlockTransactionPattern = regexp.MustCompile(`^\*\*\* \((\d+)\) TRANSACTION:`)
+	deadlockHoldsPattern       = regexp.MustCompile(`^\*\*\* \((\d+)\) HOLDS THE LOCK\(S\):`)
+	deadlockWaitingPattern     = regexp.MustCompile(`^\*\*\* \((\d+)\) WAITING FOR THIS LOCK TO BE GRANTED:`)
+	deadlockVictimPattern      = regexp.MustCompile(`^\*\*\* WE ROLL BACK TRANSACTION \((\d+)\)`)
+	deadlockTrxPattern         = regexp.MustCompile(`^TRANSACTION (\S+), ACTIVE (\d+) sec`)
+	deadlockRowLocksPattern    = regexp.MustCompile(`(\d+) row lock\(s\)`)
+	deadlockThreadPattern      = regexp.MustCompile(`^MySQL thread id (\d+), OS thread handle \S+, query id \d+\s*(.*)$`)
+	deadlockLockPattern        = regexp.MustCompile("^(?:RECORD LOCKS .*?index (\\S+) of |TABLE LOCK )table (`[^`]*`\\.`[^`]*`|\\S+) trx id \\S+ (.+?)(?: waiting)?$")
+)
+
+// deadlockTransaction is one of the transactions of a deadlock, as described by the InnoDB status.
+type deadlockTransaction struct {
+	id            string
+	threadID      *uint64
+	user          string
+	host          string
+	activeTimeSec *int64
+	rowLocks      *int64
+	query         []string
+	locksHeld     []string
+	lockWaited    string
+	waitedTable   string
+	waitedIndex   string
+}
+
+// PopulateDeadlockMetrics reports the latest deadlock detected by InnoDB, unless it was already reported by a previous execution.
+func PopulateDeadlockMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList) {
+	// The latest reported deadlock is kept between executions, as it stays in the InnoDB status until the next one
+	store, err := infrautils.NewStoreWithTTL(args, deadlockStoreName, constants.DeadlockTimestampTTL)
+	if err != nil {
+		log.Warn("Deadlocks are not reported: %v", err)
+		return
+	}
+	populateDeadlockMetrics(db, i, args, store)
+}
+
+func populateDeadlockMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, store persist.Storer) {
+	status, err := utils.CollectMetrics[utils.InnoDBStatus](db, utils.InnoDBStatusQuery)
+	if err != nil {
+		log.Error("Error collecting InnoDB status: %v", err)
+		return
+	}
+	if len(status) == 0 {
+		return
+	}
+
+	deadlock, deadlockKey := parseLatestDeadlock(status[0].Status)
+	if deadlock == nil || !isNewDeadlock(store, deadlockKey) {
+		return
+	}
+
+	if err = setDeadlockMetrics(i, args, []utils.DeadlockMetrics{*deadlock}); err != nil {
+		log.Error("Error setting deadlock metrics: %v", err)
+		return
+	}
+	// The deadlock is only kept as reported once it is published
+	if err = store.Save(); err != nil {
+		log.Warn("Error saving the latest reported deadlock: %v", err)
+	}
+}
+
+// isNewDeadlock checks if the deadlock is not the latest one reported, and keeps it as the latest one otherwise.
+func isNewDeadlock(store persist.Storer, deadlockKey string) bool {
+	var latestDeadlock string
+	if _, err := store.Get(latestDeadlockKey, &latestDeadlock); err == nil && latestDeadlock == deadlockKey {
+		return false
+	}
+	store.Set(latestDeadlockKey, deadlockKey)
+	return true
+}
+
+/*
+parseLatestDeadlock parses the LATEST DETECTED DEADLOCK section of the InnoDB status. Along with the deadlock, it returns
+the first line of the section, with the time and the thread which detected it, which identifies the deadlock.
+Nothing is returned when no deadlock was detected since the server started.
+*/
+func parseLatestDeadlock(status string) (*utils.DeadlockMetrics, string) {
+	lines := deadlockSectionLines(status)
+	if len(lines) == 0 {
+		return nil, ""
+	}
+
+	deadlockKey := strings.TrimSpace(lines[0])
+	transactions := map[int]*deadlockTransaction{}
+	var current *deadlockTransaction
+	section := deadlockTrxSection
+	inQuery := false
+	victim := 0
+
+	for _, line := range lines[1:] {
+		if match := deadlockTransactionPattern.FindStringSubmatch(line); match != nil {
+			current = deadlockTransactionNumbered(transactions, match[1])
+			section, inQuery = deadlockTrxSection, false
+			continue
+		}
+		if match := deadlockHoldsPattern.FindStringSubmatch(line); match != nil {
+			current = deadlockTransactionNumbered(transactions, match[1])
+			section, inQuery = deadlockHoldsSection, false
+			continue
+		}
+		if match := deadlockWaitingPattern.FindStringSubmatch(line); match != nil {
+			current = deadlockTransactionNumbered(transactions, match[1])
+			section, inQuery = deadlockWaitingSection, false
+			continue
+		}
+		if match := deadlockVictimPattern.FindStringSubmatch(line); match != nil {
+			victim, _ = strconv.Atoi(match[1])
+			current = nil
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch section {
+		case deadlockHoldsSection:
+			if _, _, description := parseDeadlockLock(line); description != "" {
+				current.locksHeld = append(current.locksHeld, description)
+			}
+		case deadlockWaitingSection:
+			if table, index, description := parseDeadlockLock(line); description != "" && current.lockWaited == "" {
+				current.lockWaited, current.waitedTable, current.waitedIndex = description, table, index
+			}
+		default:
+			inQuery = parseDeadlockTransactionLine(current, line, inQuery)
+		}
+	}
+
+	deadlock := &utils.DeadlockMetrics{
+		VictimTransaction:   victim,
+		CollectionTimestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	if match := deadlockTimestampPattern.FindStringSubmatch(deadlockKey); match != nil {
+		deadlock.DeadlockTimestamp = match[1]
+	}
+	if trx, ok := transactions[victim]; ok {
+		deadlock.VictimTrxID = trx.id
+	}
+	if trx, ok := transactions[1]; ok {
+		deadlock.Trx1ID, deadlock.Trx1ThreadID, deadlock.Trx1User, deadlock.Trx1Host = trx.id, trx.threadID, trx.user, trx.host
+		deadlock.Trx1ActiveTimeSec, deadlock.Trx1RowLocks, deadlock.Trx1Query = trx.activeTimeSec, trx.rowLocks, strings.Join(trx.query, "\n")
+		deadlock.Trx1LocksHeld, deadlock.Trx1LockWaited = strings.Join(trx.locksHeld, lockDescriptionsSep), trx.lockWaited
+		deadlock.Trx1WaitedTable, deadlock.Trx1WaitedIndex = trx.waitedTable, trx.waitedIndex
+	}
+	if trx, ok := transactions[2]; ok {
+		deadlock.Trx2ID, deadlock.Trx2ThreadID, deadlock.Trx2User, deadlock.Trx2Host = trx.id, trx.threadID, trx.user, trx.host
+		deadlock.Trx2ActiveTimeSec, deadlock.Trx2RowLocks, deadlock.Trx2Query = trx.activeTimeSec, trx.rowLocks, strings.Join(trx.query, "\n")
+		deadlock.Trx2LocksHeld, deadlock.Trx2LockWaited = strings.Join(trx.locksHeld, lockDescriptionsSep), trx.lockWaited
+		deadlock.Trx2WaitedTable, deadlock.Trx2WaitedIndex = trx.waitedTable, trx.waitedIndex
+	}
+	return deadlock, deadlockKey
+}
+
+// deadlockSectionLines returns the lines of the LATEST DETECTED DEADLOCK section, without its title.
+func deadlockSectionLines(status string) []string {
+	lines := strings.Split(strings.ReplaceAll(status, "\r\n", "\n"), "\n")
+	for n, line := range lines {
+		if strings.TrimSpace(line) != deadlockSectionTitle {
+			continue
+		}
+		start := n + 1
+		if start < len(lines) && sectionDividerPattern.MatchString(strings.TrimSpace(lines[start])) {
+			start++
+		}
+		end := start
+		for end < len(lines) && !sectionDividerPattern.MatchString(strings.TrimSpace(lines[end])) {
+			end++
+		}
+		return lines[start:end]
+	}
+	return nil
+}
+
+func deadlockTransactionNumbered(transactions map[int]*deadlockTransaction, number string) *deadlockTransaction {
+	n, _ := strconv.Atoi(number)
+	if transactions[n] == nil {
+		transactions[n] = &deadlockTransaction{}
+	}
+	return transactions[n]
+}
+
+/*
+parseDeadlockTransactionLine parses a line of the description of a transaction, which is followed by the statement it was
+running, e.g.:
+
+	TRANSACTION 12345, ACTIVE 5 sec starting index read
+	LOCK WAIT 3 lock struct(s), heap size 1136, 2 row lock(s)
+	MySQL thread id 10, OS thread handle 140234567890432, query id 100 localhost 127.0.0.1 app updating
+	UPDATE accounts SET balance = balance - 100 WHERE id = 2
+
+It returns whether the following lines belong to the statement.
+*/
+func parseDeadlockTransactionLine(trx *deadlockTransaction, line string, inQuery bool) bool {
+	if inQuery {
+		if strings.TrimSpace(line) == "" {
+			return false
+		}
+		trx.query = append(trx.query, line)
+		return true
+	}
+
+	if match := deadlockTrxPattern.FindStringSubmatch(line); match != nil {
+		trx.id = match[1]
+		if active, err := strconv.ParseInt(match[2], 10, 64); err == nil {
+			trx.activeTimeSec = &active
+		}
+		return false
+	}
+	if match := deadlockThreadPattern.FindStringSubmatch(line); match != nil {
+		if threadID, err := strconv.ParseUint(match[1], 10, 64); err == nil {
+			trx.threadID = &threadID
+		}
+		// The host is followed by its IP address, when it was resolved, and by the user
+		fields := strings.Fields(match[2])
+		if len(fields) > 0 {
+			trx.host = fields[0]
+		}
+		userField := 1
+		if len(fields) > userField && net.ParseIP(fields[userField]) != nil {
+			userField++
+		}
+		if len(fields) > userField {
+			trx.user = fields[userField]
+		}
+		return true
+	}
+	if match := deadlockRowLocksPattern.FindStringSubmatch(line); match != nil {
+		if rowLocks, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+			trx.rowLocks = &rowLocks
+		}
+	}
+	return false
+}
+
+/*
+parseDeadlockLock parses the header of a lock held or waited for by a transaction, e.g.:
+
+	RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table `bank`.`accounts` trx id 12345 lock_mode X locks rec but not gap
+
+and returns its table, its index, and its description, e.g. "`bank`.`accounts` index PRIMARY: lock_mode X locks rec but not gap".
+The physical records following the header hold the values of the locked rows, so they are left out.
+*/
+func parseDeadlockLock(line string) (string, string, string) {
+	match := deadlockLockPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if match == nil {
+		return "", "", ""
+	}
+	description := match[2]
+	if match[1] != "" {
+		description += " index " + match[1]
+	}
+	return match[2], match[1], description + ": " + match[3]
+}
+
+// setDeadlockMetrics sets the deadlock metrics into the integration entity.
+func setDeadlockMetrics(i *integration.Integration, args arguments.ArgumentList, metrics []utils.DeadlockMetrics) error {
+	metricList := make([]interface{}, 0, len(metrics))
+	for _, metricData := range metrics {
+		metricList = append(metricList, metricData)
+	}
+
+	return utils.IngestMetric(metricList, "MysqlDeadlockSample", i, args)
+}
@@ -0,0 +1,127 @@
+package performancemetricscollectors
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const innoDBStatusWithDeadlock = `
+=====================================
+2024-01-15 10:24:01 0x7f8b2c0a1700 INNODB MONITOR OUTPUT
+=====================================
+------------------------
+LATEST DETECTED DEADLOCK
+------------------------
+2024-01-15 10:23:45 0x7f8b2c0e3700
+*** (1) TRANSACTION:
+TRANSACTION 12345, ACTIVE 5 sec starting index read
+mysql tables in use 1, locked 1
+LOCK WAIT 3 lock struct(s), heap size 1136, 2 row lock(s)
+MySQL thread id 10, OS thread handle 140234567890432, query id 100 localhost 127.0.0.1 app updating
+UPDATE accounts
+SET balance = balance - 100 WHERE id = 2
+
+*** (1) HOLDS THE LOCK(S):
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`bank`.`accounts`" + ` trx id 12345 lock_mode X locks rec but not gap
+Record lock, heap no 2 PHYSICAL RECORD: n_fields 4; compact format; info bits 0
+ 0: len 4; hex 80000001; asc     ;;
+
+*** (1) WAITING FOR THIS LOCK TO BE GRANTED:
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`bank`.`accounts`" + ` trx id 12345 lock_mode X locks rec but not gap waiting
+Record lock, heap no 3 PHYSICAL RECORD: n_fields 4; compact format; info bits 0
+
+*** (2) TRANSACTION:
+TRANSACTION 12346, ACTIVE 3 sec starting index read
+mysql tables in use 1, locked 1
+3 lock struct(s), heap size 1136, 2 row lock(s)
+MySQL thread id 11, OS thread handle 140234567890433, query id 101 app-host batch updating
+UPDATE accounts SET balance = balance + 100 WHERE id = 1
+
+*** (2) HOLDS THE LOCK(S):
+TABLE LOCK table ` + "`bank`.`accounts`" + ` trx id 12346 lock mode IX
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`bank`.`accounts`" + ` trx id 12346 lock_mode X locks rec but not gap
+
+*** (2) WAITING FOR THIS LOCK TO BE GRANTED:
+RECORD LOCKS space id 2 page no 4 n bits 72 index PRIMARY of table ` + "`bank`.`accounts`" + ` trx id 12346 lock_mode X locks rec but not gap waiting
+
+*** WE ROLL BACK TRANSACTION (2)
+------------
+TRANSACTIONS
+------------
+Trx id counter 12350
+`
+
+func TestParseLatestDeadlock(t *testing.T) {
+	deadlock, deadlockKey := parseLatestDeadlock(innoDBStatusWithDeadlock)
+	require.NotNil(t, deadlock)
+	assert.Equal(t, "2024-01-15 10:23:45 0x7f8b2c0e3700", deadlockKey)
+	assert.Equal(t, "2024-01-15 10:23:45", deadlock.DeadlockTimestamp)
+	assert.Equal(t, 2, deadlock.VictimTransaction)
+	assert.Equal(t, "12346", deadlock.VictimTrxID)
+
+	assert.Equal(t, "12345", deadlock.Trx1ID)
+	assert.Equal(t, uint64(10), *deadlock.Trx1ThreadID)
+	assert.Equal(t, "localhost", deadlock.Trx1Host)
+	assert.Equal(t, "app", deadlock.Trx1User)
+	assert.Equal(t, int64(5), *deadlock.Trx1ActiveTimeSec)
+	assert.Equal(t, int64(2), *deadlock.Trx1RowLocks)
+	assert.Equal(t, "UPDATE accounts\nSET balance = balance - 100 WHERE id = 2", deadlock.Trx1Query)
+	assert.Equal(t, "`bank`.`accounts` index PRIMARY: lock_mode X locks rec but not gap", deadlock.Trx1LocksHeld)
+	assert.Equal(t, "`bank`.`accounts` index PRIMARY: lock_mode X locks rec but not gap", deadlock.Trx1LockWaited)
+	assert.Equal(t, "`bank`.`accounts`", deadlock.Trx1WaitedTable)
+	assert.Equal(t, "PRIMARY", deadlock.Trx1WaitedIndex)
+
+	assert.Equal(t, "12346", deadlock.Trx2ID)
+	assert.Equal(t, "app-host", deadlock.Trx2Host)
+	assert.Equal(t, "batch", deadlock.Trx2User)
+	assert.Equal(t, "UPDATE accounts SET balance = balance + 100 WHERE id = 1", deadlock.Trx2Query)
+	assert.Equal(t, "`bank`.`accounts`: lock mode IX; `bank`.`accounts` index PRIMARY: lock_mode X locks rec but not gap", deadlock.Trx2LocksHeld)
+
+	// No deadlock was detected since the server started
+	deadlock, deadlockKey = parseLatestDeadlock("------------\nTRANSACTIONS\n------------\nTrx id counter 12350\n")
+	assert.Nil(t, deadlock)
+	assert.Empty(t, deadlockKey)
+}
+
+func TestPopulateDeadlockMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	dataSource := &DataSource{DB: sqlx.NewDb(db, "sqlmock")}
+	store := persist.NewInMemoryStore()
+
+	expectStatus := func() {
+		mock.ExpectQuery(regexp.QuoteMeta("SHOW ENGINE INNODB STATUS")).
+			WillReturnRows(sqlmock.NewRows([]string{"Type", "Name", "Status"}).AddRow("InnoDB", "", innoDBStatusWithDeadlock))
+	}
+
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+	expectStatus()
+	populateDeadlockMetrics(dataSource, i, arguments.ArgumentList{}, store)
+	require.Len(t, e.Metrics, 1)
+	assert.Equal(t, "MysqlDeadlockSample", e.Metrics[0].Metrics["event_type"])
+	// The statements of the InnoDB status are reported without their literals, even though no redaction policy is configured
+	assert.Equal(t, "UPDATE accounts SET balance = balance + ? WHERE id = ?", e.Metrics[0].Metrics["trx2_query"])
+
+	// The same deadlock is not reported twice
+	i, err = integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e = i.LocalEntity()
+	expectStatus()
+	populateDeadlockMetrics(dataSource, i, arguments.ArgumentList{}, store)
+	assert.Empty(t, e.Metrics)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW ENGINE INNODB STATUS")).WillReturnError(errQuery)
+	populateDeadlockMetrics(dataSource, i, arguments.ArgumentList{}, store)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
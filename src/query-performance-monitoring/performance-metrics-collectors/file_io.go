@@ -0,0 +1,256 @@
+package performancemetricscollectors
+
+import (
+	"cmp"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	infrautils "github.com/newrelic/nri-mysql/src/infrautils"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
+)
+
+const (
+	fileIOStoreName = "file_io"
+
+	// Scopes of the file I/O metrics.
+	fileIOScopeFile  = "file"
+	fileIOScopeEvent = "event"
+)
+
+var (
+	// tableFileExtensions are the extensions of the files of the tables, which are kept in the directory of their database.
+	tableFileExtensions = []string{".ibd", ".frm", ".MYD", ".MYI", ".CSV", ".CSM", ".ARZ", ".ARM"}
+	// partitionSuffixPattern matches the suffix of the files of the partitions of a table, e.g. #P#p0 or #p#p0#sp#s0.
+	partitionSuffixPattern = regexp.MustCompile(`(?i)#p#.*$`)
+	// encodedCharPattern matches the characters of the database and table names which are encoded in the file names, e.g. @002d for -.
+	encodedCharPattern = regexp.MustCompile(`@([0-9a-fA-F]{4})`)
+)
+
+/*
+fileIOSnapshot holds the number of operations, the bytes read and written and the time spent, in picoseconds, read by
+an execution, keyed by file name and by event name.
+*/
+type fileIOSnapshot struct {
+	Files  map[string]counters `json:"files"`
+	Events map[string]counters `json:"events"`
+}
+
+/*
+PopulateFileIOMetrics reports the operations on the files of the server during the interval, for the files with the
+longest I/O latency and for the file events with the longest I/O latency, from the counters of the file summaries. The
+files of the tables are reported along with their database and table, and the ones of the excluded databases are left out.
+*/
+func PopulateFileIOMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string) {
+	store, err := infrautils.NewStore(args, fileIOStoreName)
+	if err != nil {
+		log.Warn("File I/O metrics are not reported: %v", err)
+		return
+	}
+	populateFileIOMetrics(db, i, args, excludedDatabases, store)
+}
+
+func populateFileIOMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string, store persist.Storer) {
+	fileCounters, err := utils.CollectMetrics[utils.FileIOCounters](db, utils.FileSummaryByInstanceQuery)
+	if err != nil {
+		log.Error("Error collecting file summaries: %v", err)
+		return
+	}
+	eventCounters, err := utils.CollectMetrics[utils.FileIOCounters](db, utils.FileSummaryByEventNameQuery)
+	if err != nil {
+		log.Error("Error collecting file summaries by event: %v", err)
+		return
+	}
+	dataDir := ""
+	if dataDirs, dataDirErr := utils.CollectMetrics[utils.DataDir](db, utils.DataDirQuery); dataDirErr != nil {
+		log.Warn("The files are not mapped to their tables, as the data directory is unknown: %v", dataDirErr)
+	} else if len(dataDirs) > 0 {
+		dataDir = dataDirs[0].DataDir
+	}
+
+	current := newFileIOSnapshot(fileCounters, eventCounters)
+	previous, intervalSec, ok := swapIntervalCounters(store, current)
+	if !ok {
+		return
+	}
+
+	collectionTimestamp := time.Now().UTC().Format(time.RFC3339)
+	queryCountThreshold := validator.GetValidQueryCountThreshold(args.QueryMonitoringCountThreshold)
+
+	metrics := fileIODeltas(fileCounters, current, previous, dataDir, excludedDatabases, queryCountThreshold, intervalSec, collectionTimestamp)
+	metrics = append(metrics, eventFileIODeltas(current, previous, queryCountThreshold, intervalSec, collectionTimestamp)...)
+	if len(metrics) == 0 {
+		return
+	}
+
+	if err = setFileIOMetrics(i, args, metrics); err != nil {
+		log.Error("Error setting file I/O metrics: %v", err)
+		return
+	}
+}
+
+func newFileIOSnapshot(fileCounters, eventCounters []utils.FileIOCounters) fileIOSnapshot {
+	snapshot := fileIOSnapshot{
+		Files:  make(map[string]counters, len(fileCounters)),
+		Events: make(map[string]counters, len(eventCounters)),
+	}
+	for _, row := range fileCounters {
+		if row.FileName == nil {
+			continue
+		}
+		snapshot.Files[*row.FileName] = newCounters(row)
+	}
+	for _, row := range eventCounters {
+		snapshot.Events[row.EventName] = newCounters(row)
+	}
+	return snapshot
+}
+
+// fileIODeltas returns the deltas of the files with the longest I/O latency, leaving out the files of the excluded databases.
+func fileIODeltas(fileCounters []utils.FileIOCounters, current, previous fileIOSnapshot, dataDir string, excludedDatabases []string,
+	limit int, intervalSec int64, collectionTimestamp string) []utils.FileIOMetrics {
+	metrics := make([]utils.FileIOMetrics, 0, len(fileCounters))
+	for _, row := range fileCounters {
+		if row.FileName == nil {
+			continue
+		}
+		previousCounter, found := previous.Files[*row.FileName]
+		delta := current.Files[*row.FileName].delta(previousCounter, found)
+		if delta.sum("read_count", "write_count", "misc_count") == 0 {
+			continue
+		}
+		database, table := fileTable(*row.FileName, dataDir)
+		if database != nil && slices.ContainsFunc(excludedDatabases, func(excludedDatabase string) bool {
+			return strings.EqualFold(excludedDatabase, *database)
+		}) {
+			continue
+		}
+
+		metricData := newFileIOMetrics(fileIOScopeFile, row.EventName, delta, intervalSec, collectionTimestamp)
+		metricData.FileName = row.FileName
+		metricData.DatabaseName = database
+		metricData.TableName = table
+		metrics = append(metrics, metricData)
+	}
+	sortFileIOMetrics(metrics)
+	if len(metrics) > limit {
+		metrics = metrics[:limit]
+	}
+	return metrics
+}
+
+// eventFileIODeltas returns the deltas of the file events with the longest I/O latency.
+func eventFileIODeltas(current, previous fileIOSnapshot, limit int, intervalSec int64, collectionTimestamp string) []utils.FileIOMetrics {
+	metrics := make([]utils.FileIOMetrics, 0, len(current.Events))
+	for event, counter := range current.Events {
+		previousCounter, found := previous.Events[event]
+		delta := counter.delta(previousCounter, found)
+		if delta.sum("read_count", "write_count", "misc_count") == 0 {
+			continue
+		}
+		metrics = append(metrics, newFileIOMetrics(fileIOScopeEvent, event, delta, intervalSec, collectionTimestamp))
+	}
+	sortFileIOMetrics(metrics)
+	if len(metrics) > limit {
+		metrics = metrics[:limit]
+	}
+	return metrics
+}
+
+func newFileIOMetrics(scope string, event string, delta counters, intervalSec int64, collectionTimestamp string) utils.FileIOMetrics {
+	return utils.FileIOMetrics{
+		Scope:               scope,
+		EventName:           event,
+		ReadCount:           delta["read_count"],
+		ReadBytes:           delta["read_bytes"],
+		ReadLatencyMs:       roundMs(float64(delta["read_timer"]) / picosecondsPerMillisecond),
+		WriteCount:          delta["write_count"],
+		WriteBytes:          delta["write_bytes"],
+		WriteLatencyMs:      roundMs(float64(delta["write_timer"]) / picosecondsPerMillisecond),
+		MiscCount:           delta["misc_count"],
+		MiscLatencyMs:       roundMs(float64(delta["misc_timer"]) / picosecondsPerMillisecond),
+		TotalLatencyMs:      roundMs(float64(delta.sum("read_timer", "write_timer", "misc_timer")) / picosecondsPerMillisecond),
+		IntervalSec:         intervalSec,
+		CollectionTimestamp: collectionTimestamp,
+	}
+}
+
+// sortFileIOMetrics sorts the metrics from the longest I/O latency, and by name for the same latency.
+func sortFileIOMetrics(metrics []utils.FileIOMetrics) {
+	slices.SortFunc(metrics, func(a, b utils.FileIOMetrics) int {
+		if order := cmp.Compare(b.TotalLatencyMs, a.TotalLatencyMs); order != 0 {
+			return order
+		}
+		if a.FileName != nil && b.FileName != nil && *a.FileName != *b.FileName {
+			return strings.Compare(*a.FileName, *b.FileName)
+		}
+		return strings.Compare(a.EventName, b.EventName)
+	})
+}
+
+/*
+fileTable returns the database and the table of a file of a table, e.g. shop and orders for /var/lib/mysql/shop/orders.ibd,
+or nothing for the other files, like the system tablespace, the logs or the temporary tables. The files of the data
+directory are only mapped when they are in the directory of a database, and the files of the tables created with a
+DATA DIRECTORY are mapped from the last two parts of their path.
+*/
+func fileTable(fileName string, dataDir string) (*string, *string) {
+	filePath := strings.ReplaceAll(fileName, `\`, "/")
+	dataDir = strings.TrimSuffix(strings.ReplaceAll(dataDir, `\`, "/"), "/") + "/"
+	var parts []string
+	switch {
+	case dataDir != "/" && strings.HasPrefix(filePath, dataDir):
+		parts = strings.Split(strings.TrimPrefix(filePath, dataDir), "/")
+	case strings.HasPrefix(filePath, "./"):
+		parts = strings.Split(strings.TrimPrefix(filePath, "./"), "/")
+	default:
+		parts = strings.Split(filePath, "/")
+		if len(parts) > 2 {
+			parts = parts[len(parts)-2:]
+		}
+	}
+	if len(parts) != 2 || parts[0] == "" {
+		return nil, nil
+	}
+
+	extension := path.Ext(parts[1])
+	if !slices.Contains(tableFileExtensions, extension) {
+		return nil, nil
+	}
+	table := partitionSuffixPattern.ReplaceAllString(strings.TrimSuffix(parts[1], extension), "")
+	if table == "" || strings.HasPrefix(table, "#") {
+		return nil, nil
+	}
+	database := decodeFileName(parts[0])
+	table = decodeFileName(table)
+	return &database, &table
+}
+
+// decodeFileName decodes the characters of a database or table name which are encoded in its file name.
+func decodeFileName(name string) string {
+	return encodedCharPattern.ReplaceAllStringFunc(name, func(encoded string) string {
+		code, err := strconv.ParseUint(encoded[1:], 16, 32)
+		if err != nil {
+			return encoded
+		}
+		return string(rune(code))
+	})
+}
+
+// setFileIOMetrics sets the file I/O metrics in the integration.
+func setFileIOMetrics(i *integration.Integration, args arguments.ArgumentList, metrics []utils.FileIOMetrics) error {
+	metricList := make([]interface{}, 0, len(metrics))
+	for _, metricData := range metrics {
+		metricList = append(metricList, metricData)
+	}
+
+	return utils.IngestMetric(metricList, "MysqlFileIOSample", i, args)
+}
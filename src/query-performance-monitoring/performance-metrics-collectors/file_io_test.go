@@ -0,0 +1,140 @@
+package performancemetricscollectors
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		dataDir  string
+		database string
+		table    string
+	}{
+		{"InnoDBTable", "/var/lib/mysql/shop/orders.ibd", "/var/lib/mysql/", "shop", "orders"},
+		{"RelativePath", "./shop/orders.ibd", "/var/lib/mysql/", "shop", "orders"},
+		{"Partition", "/var/lib/mysql/shop/events#P#p2024.ibd", "/var/lib/mysql/", "shop", "events"},
+		{"SubPartition", "/var/lib/mysql/shop/events#p#p2024#sp#s0.ibd", "/var/lib/mysql/", "shop", "events"},
+		{"EncodedNames", "/var/lib/mysql/my@002dshop/order@0024items.ibd", "/var/lib/mysql/", "my-shop", "order$items"},
+		{"MyISAMTable", "/var/lib/mysql/mysql/user.MYD", "/var/lib/mysql/", "mysql", "user"},
+		{"DataDirectory", "/mnt/fast/shop/orders.ibd", "/var/lib/mysql/", "shop", "orders"},
+		{"WindowsPath", `C:\ProgramData\MySQL\Data\shop\orders.ibd`, `C:\ProgramData\MySQL\Data\`, "shop", "orders"},
+		{"SystemTablespace", "/var/lib/mysql/ibdata1", "/var/lib/mysql/", "", ""},
+		{"DataDictionary", "/var/lib/mysql/mysql.ibd", "/var/lib/mysql/", "", ""},
+		{"RedoLog", "/var/lib/mysql/#innodb_redo/#ib_redo5", "/var/lib/mysql/", "", ""},
+		{"BinaryLog", "/var/lib/mysql/binlog.000042", "/var/lib/mysql/", "", ""},
+		{"TemporaryTable", "/var/lib/mysql/shop/#sql-1a2b_8.ibd", "/var/lib/mysql/", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database, table := fileTable(tt.fileName, tt.dataDir)
+			if tt.database == "" {
+				assert.Nil(t, database)
+				assert.Nil(t, table)
+				return
+			}
+			require.NotNil(t, database)
+			require.NotNil(t, table)
+			assert.Equal(t, tt.database, *database)
+			assert.Equal(t, tt.table, *table)
+		})
+	}
+}
+
+func TestEventFileIODeltasLimit(t *testing.T) {
+	current := fileIOSnapshot{Events: map[string]counters{
+		"wait/io/file/innodb/innodb_data_file": {"read_count": 16, "read_timer": 2600000000},
+		"wait/io/file/innodb/innodb_log_file":  {"write_count": 40, "write_timer": 4000000000},
+		"wait/io/file/sql/binlog":              {"write_count": 20, "write_timer": 1000000000},
+	}}
+
+	metrics := eventFileIODeltas(current, fileIOSnapshot{}, 2, 60, "2025-01-01T00:00:00Z")
+	require.Len(t, metrics, 2)
+	assert.Equal(t, "wait/io/file/innodb/innodb_log_file", metrics[0].EventName)
+	assert.Equal(t, "wait/io/file/innodb/innodb_data_file", metrics[1].EventName)
+}
+
+func TestPopulateFileIOMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	dataSource := &DataSource{DB: sqlx.NewDb(db, "sqlmock")}
+	store := persist.NewInMemoryStore()
+	args := arguments.ArgumentList{QueryMonitoringCountThreshold: 20}
+	excludedDatabases := []string{"", "mysql", "information_schema", "performance_schema", "sys"}
+	fileColumns := []string{"file_name", "event_name", "read_count", "read_bytes", "read_timer", "write_count", "write_bytes", "write_timer", "misc_count", "misc_timer"}
+	eventColumns := fileColumns[1:]
+	const dataFile = "wait/io/file/innodb/innodb_data_file"
+
+	expectSummaries := func(files, events *sqlmock.Rows) {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.file_summary_by_instance")).WillReturnRows(files)
+		mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.file_summary_by_event_name")).WillReturnRows(events)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT @@GLOBAL.datadir")).
+			WillReturnRows(sqlmock.NewRows([]string{"data_dir"}).AddRow("/var/lib/mysql/"))
+	}
+
+	// The first execution only keeps the counters
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+	expectSummaries(
+		sqlmock.NewRows(fileColumns).
+			AddRow("/var/lib/mysql/shop/orders.ibd", dataFile, 100, 1638400, 5000000000, 10, 163840, 1000000000, 20, 100000000).
+			AddRow("/var/lib/mysql/MySQL/user.MYD", "wait/io/file/myisam/dfile", 10, 1000, 1000000, 0, 0, 0, 0, 0),
+		sqlmock.NewRows(eventColumns).
+			AddRow(dataFile, 500, 8192000, 20000000000, 50, 819200, 4000000000, 100, 500000000))
+	populateFileIOMetrics(dataSource, i, args, excludedDatabases, store)
+	assert.Empty(t, e.Metrics)
+
+	i, err = integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e = i.LocalEntity()
+	expectSummaries(
+		sqlmock.NewRows(fileColumns).
+			AddRow("/var/lib/mysql/shop/orders.ibd", dataFile, 110, 1802240, 7000000000, 12, 196608, 1500000000, 20, 100000000).
+			AddRow("/var/lib/mysql/shop/customers.ibd", dataFile, 1, 16384, 500000000, 0, 0, 0, 0, 0).
+			AddRow("/var/lib/mysql/MySQL/user.MYD", "wait/io/file/myisam/dfile", 15, 1500, 2000000, 0, 0, 0, 0, 0).
+			AddRow("/var/lib/mysql/ibdata1", dataFile, 5, 81920, 100000000, 0, 0, 0, 0, 0),
+		sqlmock.NewRows(eventColumns).
+			AddRow(dataFile, 516, 8454144, 22600000000, 52, 851968, 4500000000, 100, 500000000))
+	populateFileIOMetrics(dataSource, i, args, excludedDatabases, store)
+
+	require.Len(t, e.Metrics, 4)
+	file := e.Metrics[0].Metrics
+	assert.Equal(t, "MysqlFileIOSample", file["event_type"])
+	assert.Equal(t, fileIOScopeFile, file["scope"])
+	assert.Equal(t, "/var/lib/mysql/shop/orders.ibd", file["file_name"])
+	assert.Equal(t, "shop", file["database_name"])
+	assert.Equal(t, "orders", file["table_name"])
+	assert.InDelta(t, 10.0, file["read_count"], 0.001)
+	assert.InDelta(t, 163840.0, file["read_bytes"], 0.001)
+	assert.InDelta(t, 2.0, file["read_latency_ms"], 0.001)
+	assert.InDelta(t, 2.0, file["write_count"], 0.001)
+	assert.InDelta(t, 0.5, file["write_latency_ms"], 0.001)
+	assert.InDelta(t, 2.5, file["total_latency_ms"], 0.001)
+	// The file opened during the interval and the system tablespace, but not the file of the excluded database, whatever its case
+	assert.Equal(t, "customers", e.Metrics[1].Metrics["table_name"])
+	assert.Equal(t, "/var/lib/mysql/ibdata1", e.Metrics[2].Metrics["file_name"])
+	assert.NotContains(t, e.Metrics[2].Metrics, "database_name")
+
+	event := e.Metrics[3].Metrics
+	assert.Equal(t, fileIOScopeEvent, event["scope"])
+	assert.Equal(t, dataFile, event["event_name"])
+	assert.InDelta(t, 16.0, event["read_count"], 0.001)
+	assert.InDelta(t, 3.1, event["total_latency_ms"], 0.001)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.file_summary_by_instance")).WillReturnError(errQuery)
+	populateFileIOMetrics(dataSource, i, args, excludedDatabases, store)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
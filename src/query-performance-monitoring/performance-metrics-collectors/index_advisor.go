@@ -0,0 +1,276 @@
+package performancemetricscollectors
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/constants"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+)
+
+// Issues of a plan step which an index may solve, reported as the issues of the recommendation.
+const (
+	issueFullTableScan      = "full_table_scan"
+	issueUnusedPossibleKeys = "unused_possible_keys"
+	issueFilesort           = "filesort"
+	issueTemporaryTable     = "temporary_table"
+	issueLowFiltered        = "low_filtered"
+)
+
+const (
+	indexRecommendationStoreName = "index_recommendations"
+	recommendationIDLength       = 16
+)
+
+var (
+	// tableReferencePattern matches a table of the FROM, JOIN or UPDATE clauses, e.g. `JOIN shop.customers`.
+	tableReferencePattern = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|UPDATE)\s+(?:[\w$]+\.)?([\w$]+)`)
+	// tableAliasPattern matches the alias following a table, e.g. ` AS c`, which may also be the next keyword.
+	tableAliasPattern = regexp.MustCompile(`(?i)^\s+(?:AS\s+)?([\w$]+)`)
+	/*
+		columnPredicatePattern matches a column compared with a value or with another column, e.g. `o.status IN (` or
+		`c.id = o.customer_id`. The column on the right is only matched when it is qualified, so it isn't taken for a keyword.
+	*/
+	columnPredicatePattern = regexp.MustCompile(`(?i)\b((?:[\w$]+\.)?[\w$]+)\s*(<=>|<=|>=|<>|!=|=|<|>|\bNOT\s+IN\b|\bIN\b|\bNOT\s+BETWEEN\b|\bBETWEEN\b|\bNOT\s+LIKE\b|\bLIKE\b|\bIS\s+(?:NOT\s+)?NULL\b)(?:\s*([\w$]+\.[\w$]+)\b)?`)
+	// sortClausePattern matches the columns of the GROUP BY and ORDER BY clauses.
+	sortClausePattern = regexp.MustCompile(`(?is)\b(?:GROUP|ORDER)\s+BY\s+(.+?)(?:\b(?:HAVING|ORDER|LIMIT|FOR|WINDOW|UNION)\b|\)|$)`)
+	sortColumnPattern = regexp.MustCompile(`(?i)^((?:[\w$]+\.)?[\w$]+)(?:\s+(?:ASC|DESC))?$`)
+)
+
+// aliasKeywords are the keywords which may follow a table, and are not its alias.
+var aliasKeywords = map[string]bool{
+	"WHERE": true, "JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "CROSS": true, "NATURAL": true, "STRAIGHT_JOIN": true,
+	"ON": true, "USING": true, "GROUP": true, "ORDER": true, "HAVING": true, "LIMIT": true, "SET": true, "FOR": true, "LOCK": true,
+	"UNION": true, "WINDOW": true, "USE": true, "FORCE": true, "IGNORE": true, "PARTITION": true,
+}
+
+// queryColumns holds the columns of a query used by each of its tables, in the order they appear.
+type queryColumns struct {
+	equality map[string][]string
+	sort     map[string][]string
+	ranges   map[string][]string
+}
+
+// indexAdvisor recommends indexes for the plans of the digests, reporting each recommendation once per IndexRecommendationTTL.
+type indexAdvisor struct {
+	store persist.Storer
+	seen  map[string]bool
+}
+
+func newIndexAdvisor(store persist.Storer) *indexAdvisor {
+	return &indexAdvisor{store: store, seen: map[string]bool{}}
+}
+
+/*
+advise returns the recommendations for the steps of the plan of a digest which have issues an index may solve: full scans
+and unused possible keys on large tables, filesorts, temporary tables and low filtered ratios. The candidate index of each
+table is built from the columns of the query compared with a value or joined, followed by the sorted columns, and by
+a single range column. Only the first plan of a digest in an execution is advised on, and the recommendations already
+reported in the last IndexRecommendationTTL are left out.
+*/
+func (a *indexAdvisor) advise(queryID, database, queryText string, steps []utils.QueryPlanMetrics) []utils.IndexRecommendationMetrics {
+	key := fmt.Sprintf("%s:%s", database, queryID)
+	if queryID == "" || a.seen[key] {
+		return nil
+	}
+	a.seen[key] = true
+
+	columns := parseQueryColumns(queryText)
+	var recommendations []utils.IndexRecommendationMetrics
+	for _, step := range steps {
+		issues := planStepIssues(step)
+		if len(issues) == 0 || step.TableName == "" {
+			continue
+		}
+
+		recommendation := utils.IndexRecommendationMetrics{
+			QueryID:             queryID,
+			DatabaseName:        database,
+			TableName:           step.TableName,
+			Issues:              strings.Join(issues, ","),
+			AccessType:          step.AccessType,
+			PossibleKeys:        step.PossibleKeys,
+			Key:                 step.Key,
+			RowsExaminedPerScan: step.RowsExaminedPerScan,
+			Filtered:            parsePlanNumber(step.Filtered),
+			CollectionTimestamp: time.Now().UTC().Format(time.RFC3339),
+		}
+		if indexColumns := columns.candidateIndex(step); len(indexColumns) > 0 {
+			recommendation.CandidateIndexColumns = strings.Join(indexColumns, ",")
+			recommendation.RecommendedIndex = fmt.Sprintf("CREATE INDEX idx_%s_%s ON %s (%s)",
+				step.TableName, strings.Join(indexColumns, "_"), step.TableName, strings.Join(indexColumns, ", "))
+		}
+		recommendation.RecommendationID = recommendationID(recommendation)
+
+		if a.isReported(recommendation.RecommendationID) {
+			continue
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	return recommendations
+}
+
+// isReported checks if the recommendation was reported recently, and keeps it as reported otherwise.
+func (a *indexAdvisor) isReported(id string) bool {
+	var reportedAt int64
+	if _, err := a.store.Get(id, &reportedAt); err == nil {
+		return true
+	}
+	a.store.Set(id, time.Now().Unix())
+	return false
+}
+
+func (a *indexAdvisor) save() {
+	if err := a.store.Save(); err != nil {
+		log.Warn("Error saving the reported index recommendations: %v", err)
+	}
+}
+
+// planStepIssues returns the issues of a step of the plan which an index may solve.
+func planStepIssues(step utils.QueryPlanMetrics) []string {
+	var issues []string
+	largeTable := step.RowsExaminedPerScan >= constants.IndexAdvisorMinRowsExamined
+	if step.AccessType == "ALL" && largeTable {
+		issues = append(issues, issueFullTableScan)
+	}
+	if step.PossibleKeys != "" && step.Key == "" && largeTable {
+		issues = append(issues, issueUnusedPossibleKeys)
+	}
+	if step.UsingFilesort == "true" {
+		issues = append(issues, issueFilesort)
+	}
+	if step.UsingTemporaryTable == "true" {
+		issues = append(issues, issueTemporaryTable)
+	}
+	if filtered := parsePlanNumber(step.Filtered); filtered != nil && *filtered < constants.IndexAdvisorLowFilteredPercentage && largeTable {
+		issues = append(issues, issueLowFiltered)
+	}
+	return issues
+}
+
+/*
+parseQueryColumns finds the columns of the query used by each table in its conditions and its GROUP BY and ORDER BY
+clauses. Qualified columns are attributed with the tables and aliases of the query, and unqualified ones only when the
+query has a single table. The literals are removed first, so their content is not taken for columns.
+*/
+func parseQueryColumns(queryText string) queryColumns {
+	text := strings.ReplaceAll(utils.ObfuscateSQL(queryText), "`", "")
+	columns := queryColumns{equality: map[string][]string{}, sort: map[string][]string{}, ranges: map[string][]string{}}
+
+	tables := map[string]string{}
+	for _, match := range tableReferencePattern.FindAllStringSubmatchIndex(text, -1) {
+		table := strings.ToLower(text[match[2]:match[3]])
+		tables[table] = table
+		if alias := tableAliasPattern.FindStringSubmatch(text[match[1]:]); alias != nil && !aliasKeywords[strings.ToUpper(alias[1])] {
+			tables[strings.ToLower(alias[1])] = table
+		}
+	}
+	resolve := func(column string) (string, string) {
+		column = strings.ToLower(column)
+		if qualifier, name, ok := strings.Cut(column, "."); ok {
+			return tables[qualifier], name
+		}
+		if distinct := distinctTables(tables); len(distinct) == 1 {
+			return distinct[0], column
+		}
+		return "", column
+	}
+	add := func(byTable map[string][]string, column string) {
+		if table, name := resolve(column); table != "" && !slices.Contains(byTable[table], name) {
+			byTable[table] = append(byTable[table], name)
+		}
+	}
+
+	for _, match := range columnPredicatePattern.FindAllStringSubmatch(text, -1) {
+		switch operator := strings.ToUpper(strings.Join(strings.Fields(match[2]), " ")); operator {
+		case "=", "<=>", "IN", "IS NULL":
+			add(columns.equality, match[1])
+			if match[3] != "" {
+				add(columns.equality, match[3])
+			}
+		case "<", ">", "<=", ">=", "BETWEEN", "LIKE", "IS NOT NULL":
+			add(columns.ranges, match[1])
+		}
+	}
+	for _, match := range sortClausePattern.FindAllStringSubmatch(text, -1) {
+		for _, column := range strings.Split(match[1], ",") {
+			if sortColumn := sortColumnPattern.FindStringSubmatch(strings.TrimSpace(column)); sortColumn != nil {
+				add(columns.sort, sortColumn[1])
+			}
+		}
+	}
+	return columns
+}
+
+func distinctTables(tables map[string]string) []string {
+	var distinct []string
+	for _, table := range tables {
+		if !slices.Contains(distinct, table) {
+			distinct = append(distinct, table)
+		}
+	}
+	return distinct
+}
+
+/*
+candidateIndex returns the columns of the index which may solve the issues of the step: the equality columns first, then
+the sorted ones, and a single range column, as the columns following a range can't be used to look up the index.
+No index is returned when the key the step already uses starts with the same columns.
+*/
+func (c queryColumns) candidateIndex(step utils.QueryPlanMetrics) []string {
+	table := strings.ToLower(step.TableName)
+
+	var indexColumns []string
+	add := func(column string) {
+		if len(indexColumns) < constants.IndexAdvisorMaxIndexColumns && !slices.Contains(indexColumns, column) {
+			indexColumns = append(indexColumns, column)
+		}
+	}
+	for _, column := range c.equality[table] {
+		add(column)
+	}
+	for _, column := range c.sort[table] {
+		add(column)
+	}
+	for _, column := range c.ranges[table] {
+		if !slices.Contains(indexColumns, column) {
+			add(column)
+			break
+		}
+	}
+
+	if len(indexColumns) == 0 || step.Key == "" {
+		return indexColumns
+	}
+	usedKeyParts := strings.Split(strings.ToLower(step.UsedKeyParts), ",")
+	if len(usedKeyParts) >= len(indexColumns) && slices.Equal(usedKeyParts[:len(indexColumns)], indexColumns) {
+		return nil
+	}
+	return indexColumns
+}
+
+// recommendationID identifies a recommendation by the digest, the table, the issues and the candidate index.
+func recommendationID(recommendation utils.IndexRecommendationMetrics) string {
+	hash := sha256.Sum256([]byte(strings.Join([]string{
+		recommendation.DatabaseName, recommendation.QueryID, recommendation.TableName, recommendation.Issues, recommendation.CandidateIndexColumns,
+	}, "\n")))
+	return fmt.Sprintf("%x", hash)[:recommendationIDLength]
+}
+
+// setIndexRecommendationMetrics sets the index recommendation metrics into the integration entity.
+func setIndexRecommendationMetrics(i *integration.Integration, args arguments.ArgumentList, metrics []utils.IndexRecommendationMetrics) error {
+	metricList := make([]interface{}, 0, len(metrics))
+	for _, metricData := range metrics {
+		metricList = append(metricList, metricData)
+	}
+
+	return utils.IngestMetric(metricList, "MysqlIndexRecommendationSample", i, args)
+}
@@ -0,0 +1,96 @@
+package performancemetricscollectors
+
+import (
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanStepIssues(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     utils.QueryPlanMetrics
+		expected []string
+	}{
+		{"FullScanOnLargeTable", utils.QueryPlanMetrics{AccessType: "ALL", RowsExaminedPerScan: 50000, Filtered: "100.00"}, []string{issueFullTableScan}},
+		{"FullScanOnSmallTable", utils.QueryPlanMetrics{AccessType: "ALL", RowsExaminedPerScan: 10, Filtered: "5.00"}, nil},
+		{"UnusedPossibleKeys", utils.QueryPlanMetrics{AccessType: "ALL", PossibleKeys: "idx_status", RowsExaminedPerScan: 2000, Filtered: "3.50"},
+			[]string{issueFullTableScan, issueUnusedPossibleKeys, issueLowFiltered}},
+		{"FilesortAndTemporaryTable", utils.QueryPlanMetrics{AccessType: "ref", Key: "idx_customer", RowsExaminedPerScan: 5, UsingFilesort: "true", UsingTemporaryTable: "true"},
+			[]string{issueFilesort, issueTemporaryTable}},
+		{"IndexLookup", utils.QueryPlanMetrics{AccessType: "eq_ref", Key: "PRIMARY", RowsExaminedPerScan: 1, Filtered: "100.00", UsingFilesort: "false"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, planStepIssues(tt.step))
+		})
+	}
+}
+
+func TestCandidateIndex(t *testing.T) {
+	columns := parseQueryColumns("SELECT o.id, c.name FROM shop.`orders` AS o JOIN customers c ON c.id = o.customer_id " +
+		"WHERE o.status IN ('paid', 'shipped') AND o.created_at > '2024-01-01' AND c.country = 'ES' AND o.note <> 'x=1' ORDER BY o.created_at DESC, c.name")
+
+	assert.Equal(t, []string{"customer_id", "status", "created_at"}, columns.candidateIndex(utils.QueryPlanMetrics{TableName: "orders"}))
+	assert.Equal(t, []string{"id", "country", "name"}, columns.candidateIndex(utils.QueryPlanMetrics{TableName: "customers"}))
+	// The key already used starts with the candidate columns
+	assert.Nil(t, columns.candidateIndex(utils.QueryPlanMetrics{TableName: "customers", Key: "idx_all", UsedKeyParts: "id,country,name,email"}))
+
+	// Unqualified columns are only attributed when the query has a single table
+	single := parseQueryColumns("SELECT * FROM orders WHERE status = ? AND total BETWEEN ? AND ? GROUP BY customer_id")
+	assert.Equal(t, []string{"status", "customer_id", "total"}, single.candidateIndex(utils.QueryPlanMetrics{TableName: "orders"}))
+
+	joined := parseQueryColumns("SELECT * FROM orders JOIN customers WHERE status = 1")
+	assert.Empty(t, joined.candidateIndex(utils.QueryPlanMetrics{TableName: "orders"}))
+}
+
+func TestIndexAdvisor(t *testing.T) {
+	store := persist.NewInMemoryStore()
+	queryText := "SELECT * FROM orders WHERE status = 'paid' ORDER BY created_at"
+	steps := []utils.QueryPlanMetrics{
+		{TableName: "orders", AccessType: "ALL", RowsExaminedPerScan: 120000, Filtered: "10.00", UsingFilesort: "true"},
+		{TableName: "customers", AccessType: "eq_ref", Key: "PRIMARY", RowsExaminedPerScan: 1, Filtered: "100.00"},
+	}
+
+	recommendations := newIndexAdvisor(store).advise("digest1", "shop", queryText, steps)
+	require.Len(t, recommendations, 1)
+	recommendation := recommendations[0]
+	assert.Equal(t, "digest1", recommendation.QueryID)
+	assert.Equal(t, "shop", recommendation.DatabaseName)
+	assert.Equal(t, "orders", recommendation.TableName)
+	assert.Equal(t, "full_table_scan,filesort", recommendation.Issues)
+	assert.Equal(t, "status,created_at", recommendation.CandidateIndexColumns)
+	assert.Equal(t, "CREATE INDEX idx_orders_status_created_at ON orders (status, created_at)", recommendation.RecommendedIndex)
+	assert.Equal(t, 10.0, *recommendation.Filtered)
+	assert.Len(t, recommendation.RecommendationID, recommendationIDLength)
+
+	// Only the first plan of a digest in an execution is advised on
+	advisor := newIndexAdvisor(persist.NewInMemoryStore())
+	assert.Len(t, advisor.advise("digest1", "shop", queryText, steps), 1)
+	assert.Nil(t, advisor.advise("digest1", "shop", queryText, steps))
+
+	// The recommendations already reported are not reported again
+	assert.Empty(t, newIndexAdvisor(store).advise("digest1", "shop", queryText, steps))
+	assert.Len(t, newIndexAdvisor(store).advise("digest1", "archive", queryText, steps), 1)
+}
+
+func TestSetIndexRecommendationMetrics(t *testing.T) {
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+
+	err = setIndexRecommendationMetrics(i, arguments.ArgumentList{}, []utils.IndexRecommendationMetrics{
+		{RecommendationID: "abc", QueryID: "digest1", TableName: "orders", Issues: issueFullTableScan, RowsExaminedPerScan: 1000},
+	})
+	assert.NoError(t, err)
+	require.Len(t, e.Metrics, 1)
+	assert.Equal(t, "MysqlIndexRecommendationSample", e.Metrics[0].Metrics["event_type"])
+	assert.Equal(t, "orders", e.Metrics[0].Metrics["table_name"])
+	assert.Equal(t, issueFullTableScan, e.Metrics[0].Metrics["issues"])
+}
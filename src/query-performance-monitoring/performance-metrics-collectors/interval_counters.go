@@ -0,0 +1,76 @@
+package performancemetricscollectors
+
+import (
+	"reflect"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+)
+
+// intervalCountersKey is the key of the counters in the store of each collector reporting deltas.
+const intervalCountersKey = "counters"
+
+// counters holds the cumulative counters of an item, e.g. a file or a table, by the column they are read from.
+type counters map[string]uint64
+
+/*
+newCounters returns the counters of a row, which are its uint64 fields, keyed by their db tag. The other fields,
+like the names identifying the item or the current connections of an account, are left out.
+*/
+func newCounters(row any) counters {
+	value := reflect.ValueOf(row)
+	rowType := value.Type()
+	result := counters{}
+	for n := 0; n < rowType.NumField(); n++ {
+		if field := rowType.Field(n); field.Type.Kind() == reflect.Uint64 {
+			result[field.Tag.Get("db")] = value.Field(n).Uint()
+		}
+	}
+	return result
+}
+
+/*
+delta returns the counters of the interval, subtracting the previous counters of the item from the current ones. The
+whole current counters are the delta of an item missing from the previous execution, which appeared afterwards, or of
+counters lower than the previous ones, which were reset by a restart of the server or a truncation of the summaries.
+*/
+func (c counters) delta(previous counters, found bool) counters {
+	if !found {
+		return c
+	}
+	result := make(counters, len(c))
+	for name, value := range c {
+		if value < previous[name] {
+			return c
+		}
+		result[name] = value - previous[name]
+	}
+	return result
+}
+
+// sum returns the sum of the given counters, e.g. of the operation counts to check if there was any activity.
+func (c counters) sum(names ...string) uint64 {
+	var total uint64
+	for _, name := range names {
+		total += c[name]
+	}
+	return total
+}
+
+/*
+swapIntervalCounters keeps the counters read by the current execution in the store and returns the ones of the
+previous execution, along with the seconds elapsed since. It returns false on the first execution, or when the
+previous counters expired, as there is nothing to subtract the current counters from yet.
+*/
+func swapIntervalCounters[T any](store persist.Storer, current T) (T, int64, bool) {
+	var previous T
+	previousTimestamp, previousErr := store.Get(intervalCountersKey, &previous)
+	currentTimestamp := store.Set(intervalCountersKey, current)
+	if err := store.Save(); err != nil {
+		log.Warn("Error saving the counters: %v", err)
+	}
+	if previousErr != nil {
+		return previous, 0, false
+	}
+	return previous, currentTimestamp - previousTimestamp, true
+}
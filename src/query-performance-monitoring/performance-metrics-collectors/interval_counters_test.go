@@ -0,0 +1,53 @@
+package performancemetricscollectors
+
+import (
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCounters(t *testing.T) {
+	threadID := uint64(40)
+	row := utils.WaitEventCounters{ThreadID: &threadID, WaitEventName: "wait/io/table/sql/handler", WaitCount: 60, TotalWaitTime: 30000000000}
+	assert.Equal(t, counters{"wait_count": 60, "total_wait_time": 30000000000}, newCounters(row))
+
+	// The current connections are not counters
+	account := utils.AccountCounters{User: "app", Host: "%", CurrentConnections: 5, TotalConnections: 120}
+	assert.NotContains(t, newCounters(account), "current_connections")
+	assert.Equal(t, uint64(120), newCounters(account)["total_connections"])
+}
+
+func TestCountersDelta(t *testing.T) {
+	previous := counters{"read_count": 10, "read_timer": 5000}
+	assert.Equal(t, counters{"read_count": 5, "read_timer": 1000}, counters{"read_count": 15, "read_timer": 6000}.delta(previous, true))
+	assert.Equal(t, counters{"read_count": 0, "read_timer": 0}, previous.delta(previous, true))
+	// The item appeared during the interval
+	assert.Equal(t, counters{"read_count": 3, "read_timer": 900}, counters{"read_count": 3, "read_timer": 900}.delta(nil, false))
+	// The counters were reset during the interval, even if only some of them are lower
+	assert.Equal(t, counters{"read_count": 12, "read_timer": 400}, counters{"read_count": 12, "read_timer": 400}.delta(previous, true))
+}
+
+func TestCountersSum(t *testing.T) {
+	delta := counters{"read_count": 2, "write_count": 3, "read_bytes": 16384}
+	assert.Equal(t, uint64(5), delta.sum("read_count", "write_count", "misc_count"))
+}
+
+func TestSwapIntervalCounters(t *testing.T) {
+	store := persist.NewInMemoryStore()
+
+	// The first execution only keeps the counters, as there is nothing to subtract them from yet
+	_, _, ok := swapIntervalCounters(store, map[string]counters{"shop": {"read_count": 10}})
+	assert.False(t, ok)
+
+	previous, intervalSec, ok := swapIntervalCounters(store, map[string]counters{"shop": {"read_count": 15}})
+	require.True(t, ok)
+	assert.Equal(t, map[string]counters{"shop": {"read_count": 10}}, previous)
+	assert.GreaterOrEqual(t, intervalSec, int64(0))
+
+	previous, _, ok = swapIntervalCounters(store, map[string]counters{})
+	require.True(t, ok)
+	assert.Equal(t, map[string]counters{"shop": {"read_count": 15}}, previous)
+}
@@ -0,0 +1,105 @@
+package performancemetricscollectors
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
+)
+
+// Patterns of the lines of the TRANSACTIONS section of the InnoDB status.
+var (
+	statusTransactionPattern = regexp.MustCompile(`^---TRANSACTION (\d+),`)
+	undoLogEntriesPattern    = regexp.MustCompile(`undo log entries (\d+)`)
+)
+
+// PopulateLongRunningTransactionMetrics retrieves the long-running and idle in transaction sessions and populates them into the integration entity.
+func PopulateLongRunningTransactionMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string) {
+	// Get the thresholds of the transactions to report
+	trxAgeThreshold := validator.GetValidTrxAgeThreshold(args.QueryMonitoringTrxAgeThreshold)
+	trxIdleThreshold := validator.GetValidTrxIdleThreshold(args.QueryMonitoringTrxIdleThreshold)
+	queryCountThreshold := validator.GetValidQueryCountThreshold(args.QueryMonitoringCountThreshold)
+
+	// Prepare the SQL query with the provided parameters
+	query, inputArgs, err := sqlx.In(utils.LongRunningTransactionsQuery, trxAgeThreshold, trxIdleThreshold, excludedDatabases, queryCountThreshold)
+	if err != nil {
+		log.Error("Failed to prepare long-running transactions query: %v", err)
+		return
+	}
+
+	metrics, err := utils.CollectMetrics[utils.LongRunningTransactionMetrics](db, query, inputArgs...)
+	if err != nil {
+		log.Error("Error collecting long-running transaction metrics: %v", err)
+		return
+	}
+
+	if len(metrics) == 0 {
+		return
+	}
+	setUndoLogEntries(db, metrics)
+
+	err = setLongRunningTransactionMetrics(metrics, i, args)
+	if err != nil {
+		log.Error("Error setting long-running transaction metrics: %v", err)
+		return
+	}
+}
+
+/*
+setUndoLogEntries sets the undo log entries of the transactions, which are only shown by the InnoDB status. They are left
+out when the status can't be read, e.g. without the PROCESS privilege, or when a transaction isn't listed in it.
+*/
+func setUndoLogEntries(db utils.DataSource, metrics []utils.LongRunningTransactionMetrics) {
+	status, err := utils.CollectMetrics[utils.InnoDBStatus](db, utils.InnoDBStatusQuery)
+	if err != nil || len(status) == 0 {
+		log.Debug("The undo log entries of the transactions are not reported: %v", err)
+		return
+	}
+
+	undoLogEntries := parseUndoLogEntries(status[0].Status)
+	for index := range metrics {
+		if metrics[index].TrxID == nil {
+			continue
+		}
+		if entries, ok := undoLogEntries[*metrics[index].TrxID]; ok {
+			metrics[index].UndoLogEntries = &entries
+		}
+	}
+}
+
+/*
+parseUndoLogEntries returns the undo log entries of the transactions listed in the TRANSACTIONS section of the InnoDB
+status, by transaction id. The entries are only shown when there are some, so a listed transaction without them has none.
+*/
+func parseUndoLogEntries(status string) map[string]int64 {
+	undoLogEntries := map[string]int64{}
+	trxID := ""
+	for _, line := range strings.Split(status, "\n") {
+		line = strings.TrimSpace(line)
+		if match := statusTransactionPattern.FindStringSubmatch(line); match != nil {
+			trxID = match[1]
+			undoLogEntries[trxID] = 0
+			continue
+		}
+		if match := undoLogEntriesPattern.FindStringSubmatch(line); match != nil && trxID != "" {
+			undoLogEntries[trxID], _ = strconv.ParseInt(match[1], 10, 64)
+		}
+	}
+	return undoLogEntries
+}
+
+// setLongRunningTransactionMetrics sets the long-running transaction metrics into the integration entity.
+func setLongRunningTransactionMetrics(metrics []utils.LongRunningTransactionMetrics, i *integration.Integration, args arguments.ArgumentList) error {
+	metricList := make([]interface{}, 0, len(metrics))
+	for _, metricData := range metrics {
+		metricList = append(metricList, metricData)
+	}
+
+	return utils.IngestMetric(metricList, "MysqlLongRunningTransactionSample", i, args)
+}
@@ -0,0 +1,96 @@
+package performancemetricscollectors
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const innoDBStatusWithTransactions = `
+------------
+TRANSACTIONS
+------------
+Trx id counter 4212
+Purge done for trx's n:o < 4180 undo n:o < 0 state: running but idle
+History list length 42
+LIST OF TRANSACTIONS FOR EACH SESSION:
+---TRANSACTION 421938475, not started
+0 lock struct(s), heap size 1128, 0 row lock(s)
+---TRANSACTION 4211, ACTIVE 75 sec
+3 lock struct(s), heap size 1128, 2 row lock(s), undo log entries 1
+MySQL thread id 17, OS thread handle 139876, query id 88 10.0.0.5 app
+---TRANSACTION 4190, ACTIVE 1200 sec
+120 lock struct(s), heap size 24696, 15000 row lock(s), undo log entries 9500
+MySQL thread id 12, OS thread handle 139877, query id 64 10.0.0.6 batch updating
+--------
+FILE I/O
+--------
+`
+
+func TestParseUndoLogEntries(t *testing.T) {
+	assert.Equal(t, map[string]int64{"421938475": 0, "4211": 1, "4190": 9500}, parseUndoLogEntries(innoDBStatusWithTransactions))
+	// The transactions of the latest deadlock are not the open ones
+	assert.Empty(t, parseUndoLogEntries("*** (1) TRANSACTION:\nTRANSACTION 4190, ACTIVE 3 sec starting index read\n"+
+		"LOCK WAIT 3 lock struct(s), heap size 1136, 2 row lock(s), undo log entries 1\n"))
+}
+
+func TestPopulateLongRunningTransactionMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	dataSource := &DataSource{DB: sqlx.NewDb(db, "sqlmock")}
+	excludedDatabases := []string{"mysql", "sys"}
+	columns := []string{"trx_id", "pid", "user", "command", "session_state", "trx_age_sec", "idle_time_sec", "rows_locked", "lock_structs", "rows_modified", "last_query"}
+
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.innodb_trx t")).
+		WithArgs(600, 45, "mysql", "sys", 20).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("4211", "17", "app", "Sleep", "idle_in_transaction", 75, 70, 2, 3, 1, "UPDATE orders SET status = 'paid' WHERE id = 7").
+			AddRow("4190", "12", "batch", "Query", "active", 1200, 0, 15000, 120, 8000, "DELETE FROM events WHERE created < '2024-01-01'"))
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW ENGINE INNODB STATUS")).
+		WillReturnRows(sqlmock.NewRows([]string{"Type", "Name", "Status"}).AddRow("InnoDB", "", innoDBStatusWithTransactions))
+	args := arguments.ArgumentList{QueryMonitoringTrxAgeThreshold: 600, QueryMonitoringTrxIdleThreshold: 45, QueryMonitoringCountThreshold: 20}
+	PopulateLongRunningTransactionMetrics(dataSource, i, args, excludedDatabases)
+
+	require.Len(t, e.Metrics, 2)
+	assert.Equal(t, "MysqlLongRunningTransactionSample", e.Metrics[0].Metrics["event_type"])
+	assert.Equal(t, "idle_in_transaction", e.Metrics[0].Metrics["session_state"])
+	assert.Equal(t, float64(70), e.Metrics[0].Metrics["idle_time_sec"])
+	// The statement without a digest is reported without its literals
+	assert.Equal(t, "UPDATE orders SET status = ? WHERE id = ?", e.Metrics[0].Metrics["last_query"])
+	assert.Equal(t, float64(1), e.Metrics[0].Metrics["undo_log_entries"])
+	assert.Equal(t, float64(15000), e.Metrics[1].Metrics["rows_locked"])
+	assert.Equal(t, float64(8000), e.Metrics[1].Metrics["rows_modified"])
+	assert.Equal(t, float64(9500), e.Metrics[1].Metrics["undo_log_entries"])
+
+	// The undo log entries are left out when the InnoDB status can't be read
+	i, err = integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e = i.LocalEntity()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.innodb_trx t")).
+		WithArgs(600, 45, "mysql", "sys", 20).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("4211", "17", "app", "Sleep", "idle_in_transaction", 75, 70, 2, 3, 1, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("SHOW ENGINE INNODB STATUS")).WillReturnError(errQuery)
+	PopulateLongRunningTransactionMetrics(dataSource, i, args, excludedDatabases)
+	require.Len(t, e.Metrics, 1)
+	assert.NotContains(t, e.Metrics[0].Metrics, "undo_log_entries")
+
+	// Invalid thresholds are replaced by the defaults
+	mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.innodb_trx t")).
+		WithArgs(constants.DefaultTrxAgeThreshold, constants.DefaultTrxIdleThreshold, "mysql", "sys", 20).
+		WillReturnError(errQuery)
+	args = arguments.ArgumentList{QueryMonitoringTrxAgeThreshold: -1, QueryMonitoringTrxIdleThreshold: -1, QueryMonitoringCountThreshold: 20}
+	PopulateLongRunningTransactionMetrics(dataSource, i, args, excludedDatabases)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
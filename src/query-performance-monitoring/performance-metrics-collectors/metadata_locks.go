@@ -0,0 +1,160 @@
+package performancemetricscollectors
+
+import (
+	"slices"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
+)
+
+const (
+	metadataLockGranted = "GRANTED"
+	metadataLockPending = "PENDING"
+)
+
+// Metadata lock types reported by performance_schema.metadata_locks.
+const (
+	mdlIntentionExclusive = "INTENTION_EXCLUSIVE"
+	mdlShared             = "SHARED"
+	mdlSharedHighPrio     = "SHARED_HIGH_PRIO"
+	mdlSharedRead         = "SHARED_READ"
+	mdlSharedWrite        = "SHARED_WRITE"
+	mdlSharedWriteLowPrio = "SHARED_WRITE_LOW_PRIO"
+	mdlSharedUpgradable   = "SHARED_UPGRADABLE"
+	mdlSharedReadOnly     = "SHARED_READ_ONLY"
+	mdlSharedNoWrite      = "SHARED_NO_WRITE"
+	mdlSharedNoReadWrite  = "SHARED_NO_READ_WRITE"
+	mdlExclusive          = "EXCLUSIVE"
+)
+
+var (
+	// grantedIncompatibleLocks holds, for each requested lock type, the granted lock types which make it wait.
+	grantedIncompatibleLocks = map[string][]string{
+		mdlShared:             {mdlExclusive},
+		mdlSharedHighPrio:     {mdlExclusive},
+		mdlSharedRead:         {mdlSharedNoReadWrite, mdlExclusive},
+		mdlSharedWrite:        {mdlSharedReadOnly, mdlSharedNoWrite, mdlSharedNoReadWrite, mdlExclusive},
+		mdlSharedWriteLowPrio: {mdlSharedReadOnly, mdlSharedNoWrite, mdlSharedNoReadWrite, mdlExclusive},
+		mdlSharedUpgradable:   {mdlSharedUpgradable, mdlSharedNoWrite, mdlSharedNoReadWrite, mdlExclusive},
+		mdlSharedReadOnly:     {mdlSharedWrite, mdlSharedWriteLowPrio, mdlSharedNoWrite, mdlSharedNoReadWrite, mdlExclusive},
+		mdlSharedNoWrite:      {mdlSharedWrite, mdlSharedWriteLowPrio, mdlSharedUpgradable, mdlSharedNoWrite, mdlSharedNoReadWrite, mdlExclusive},
+		mdlSharedNoReadWrite: {mdlSharedRead, mdlSharedWrite, mdlSharedWriteLowPrio, mdlSharedUpgradable, mdlSharedReadOnly,
+			mdlSharedNoWrite, mdlSharedNoReadWrite, mdlExclusive},
+		mdlExclusive: {mdlShared, mdlSharedHighPrio, mdlSharedRead, mdlSharedWrite, mdlSharedWriteLowPrio,
+			mdlSharedUpgradable, mdlSharedReadOnly, mdlSharedNoWrite, mdlSharedNoReadWrite, mdlExclusive},
+	}
+	/*
+		pendingIncompatibleLocks holds, for each requested lock type, the pending lock types which are granted first.
+		This is how a long running SELECT makes the statements queued behind a waiting ALTER TABLE wait as well.
+	*/
+	pendingIncompatibleLocks = map[string][]string{
+		mdlShared:             {mdlExclusive},
+		mdlSharedHighPrio:     nil,
+		mdlSharedRead:         {mdlSharedNoReadWrite, mdlExclusive},
+		mdlSharedWrite:        {mdlSharedReadOnly, mdlSharedNoReadWrite, mdlExclusive},
+		mdlSharedWriteLowPrio: {mdlSharedReadOnly, mdlSharedNoWrite, mdlSharedNoReadWrite, mdlExclusive},
+		mdlSharedUpgradable:   {mdlExclusive},
+		mdlSharedReadOnly:     {mdlSharedNoReadWrite, mdlExclusive},
+		mdlSharedNoWrite:      {mdlExclusive},
+		mdlSharedNoReadWrite:  {mdlExclusive},
+		mdlExclusive:          nil,
+	}
+
+	// scopedObjectTypes are the objects locked as a scope, like the global read lock, only as INTENTION_EXCLUSIVE, SHARED or EXCLUSIVE.
+	scopedObjectTypes = []string{"GLOBAL", "SCHEMA", "COMMIT", "TABLESPACE", "BACKUP LOCK"}
+	// grantedIncompatibleScopedLocks and pendingIncompatibleScopedLocks are the counterparts of the above for the scoped locks.
+	grantedIncompatibleScopedLocks = map[string][]string{
+		mdlIntentionExclusive: {mdlShared, mdlExclusive},
+		mdlShared:             {mdlIntentionExclusive, mdlExclusive},
+		mdlExclusive:          {mdlIntentionExclusive, mdlShared, mdlExclusive},
+	}
+	pendingIncompatibleScopedLocks = map[string][]string{
+		mdlIntentionExclusive: {mdlShared, mdlExclusive},
+		mdlShared:             {mdlExclusive},
+		mdlExclusive:          nil,
+	}
+)
+
+// PopulateMetadataLockMetrics retrieves the sessions waiting for metadata locks and the sessions blocking them, and populates them into the integration entity.
+func PopulateMetadataLockMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string) {
+	// Prepare the SQL query with the provided parameters
+	query, inputArgs, err := sqlx.In(utils.MetadataLocksQuery, excludedDatabases)
+	if err != nil {
+		log.Error("Failed to prepare metadata locks query: %v", err)
+		return
+	}
+
+	// Collect the waiting sessions along with the other sessions locking the same objects
+	metrics, err := utils.CollectMetrics[utils.MetadataLockMetrics](db, query, inputArgs...)
+	if err != nil {
+		log.Error("Error collecting metadata lock metrics: %v", err)
+		return
+	}
+
+	// Keep only the sessions whose locks actually make the waiting sessions wait
+	metrics = slices.DeleteFunc(metrics, func(metricData utils.MetadataLockMetrics) bool {
+		return !isBlockingMetadataLock(metricData)
+	})
+	if len(metrics) == 0 {
+		return
+	}
+
+	// The limit applies to the conflicting locks, which are sorted from the longest waiting query
+	queryCountThreshold := validator.GetValidQueryCountThreshold(args.QueryMonitoringCountThreshold)
+	if len(metrics) > queryCountThreshold {
+		metrics = metrics[:queryCountThreshold]
+	}
+
+	err = setMetadataLockMetrics(metrics, i, args)
+	if err != nil {
+		log.Error("Error setting metadata lock metrics: %v", err)
+		return
+	}
+}
+
+/*
+isBlockingMetadataLock checks if the lock held or requested by the blocking session makes the waiting session wait,
+according to the compatibility of the metadata lock types. A pending lock only does so when it is granted before
+the waiting one. Unknown lock types are considered blocking, so they aren't left out of the report.
+*/
+func isBlockingMetadataLock(metricData utils.MetadataLockMetrics) bool {
+	if metricData.WaitingLockType == nil || metricData.BlockingLockType == nil || metricData.BlockingLockStatus == nil {
+		return false
+	}
+	waitingLockType, blockingLockType := *metricData.WaitingLockType, *metricData.BlockingLockType
+
+	scoped := metricData.ObjectType != nil && slices.Contains(scopedObjectTypes, *metricData.ObjectType)
+	var incompatibleLocks map[string][]string
+	switch {
+	case *metricData.BlockingLockStatus == metadataLockGranted && scoped:
+		incompatibleLocks = grantedIncompatibleScopedLocks
+	case *metricData.BlockingLockStatus == metadataLockGranted:
+		incompatibleLocks = grantedIncompatibleLocks
+	case *metricData.BlockingLockStatus == metadataLockPending && scoped:
+		incompatibleLocks = pendingIncompatibleScopedLocks
+	case *metricData.BlockingLockStatus == metadataLockPending:
+		incompatibleLocks = pendingIncompatibleLocks
+	default:
+		return false
+	}
+
+	locks, ok := incompatibleLocks[waitingLockType]
+	if !ok {
+		return true
+	}
+	return slices.Contains(locks, blockingLockType)
+}
+
+// setMetadataLockMetrics sets the metadata lock metrics into the integration entity.
+func setMetadataLockMetrics(metrics []utils.MetadataLockMetrics, i *integration.Integration, args arguments.ArgumentList) error {
+	metricList := make([]interface{}, 0, len(metrics))
+	for _, metricData := range metrics {
+		metricList = append(metricList, metricData)
+	}
+
+	return utils.IngestMetric(metricList, "MysqlMetadataLockSample", i, args)
+}
@@ -0,0 +1,107 @@
+package performancemetricscollectors
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBlockingMetadataLock(t *testing.T) {
+	tests := []struct {
+		name       string
+		objectType string
+		waiting    string
+		blocking   string
+		status     string
+		expected   bool
+	}{
+		{"AlterWaitsForSelect", "TABLE", mdlExclusive, mdlSharedRead, metadataLockGranted, true},
+		{"SelectQueuedBehindAlter", "TABLE", mdlSharedRead, mdlExclusive, metadataLockPending, true},
+		{"SelectWithConcurrentSelect", "TABLE", mdlSharedRead, mdlSharedRead, metadataLockGranted, false},
+		{"WriteWaitsForLockTablesRead", "TABLE", mdlSharedWrite, mdlSharedReadOnly, metadataLockGranted, true},
+		{"AlterWithQueuedAlter", "TABLE", mdlExclusive, mdlExclusive, metadataLockPending, false},
+		{"HighPriorityNotQueued", "TABLE", mdlSharedHighPrio, mdlExclusive, metadataLockPending, false},
+		{"WriteWaitsForGlobalReadLock", "GLOBAL", mdlIntentionExclusive, mdlShared, metadataLockGranted, true},
+		{"GlobalReadLockWaitsForWrite", "GLOBAL", mdlShared, mdlIntentionExclusive, metadataLockGranted, true},
+		{"ConcurrentWrites", "GLOBAL", mdlIntentionExclusive, mdlIntentionExclusive, metadataLockGranted, false},
+		{"UnknownLockType", "TABLE", "NEW_LOCK_TYPE", mdlSharedRead, metadataLockGranted, true},
+		{"OtherLockStatus", "TABLE", mdlExclusive, mdlSharedRead, "VICTIM", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isBlockingMetadataLock(utils.MetadataLockMetrics{
+				ObjectType:         ptr(tt.objectType),
+				WaitingLockType:    ptr(tt.waiting),
+				BlockingLockType:   ptr(tt.blocking),
+				BlockingLockStatus: ptr(tt.status),
+			}))
+		})
+	}
+}
+
+func TestPopulateMetadataLockMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	dataSource := &DataSource{DB: sqlx.NewDb(db, "sqlmock")}
+	excludedDatabases := []string{"mysql", "information_schema", "performance_schema", "sys"}
+	columns := []string{
+		"object_type", "object_schema", "object_name", "waiting_pid", "waiting_lock_type", "waiting_query",
+		"blocking_pid", "blocking_command", "blocking_lock_type", "blocking_lock_status", "blocking_query",
+	}
+
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.metadata_locks w")).
+		WithArgs("mysql", "information_schema", "performance_schema", "sys").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("TABLE", "shop", "orders", "12", mdlExclusive, "ALTER TABLE orders ADD COLUMN note TEXT",
+				"10", "Query", mdlSharedRead, metadataLockGranted, "SELECT SLEEP(100) FROM orders WHERE id = 5").
+			AddRow("TABLE", "shop", "orders", "12", mdlExclusive, "ALTER TABLE orders ADD COLUMN note TEXT",
+				"11", "Sleep", mdlSharedUpgradable, metadataLockPending, nil).
+			AddRow("TABLE", "shop", "orders", "13", mdlSharedRead, "SELECT * FROM orders",
+				"10", "Query", mdlSharedRead, metadataLockGranted, "SELECT SLEEP(100) FROM orders WHERE id = 5").
+			AddRow("TABLE", "shop", "orders", "13", mdlSharedRead, "SELECT * FROM orders",
+				"12", "Query", mdlExclusive, metadataLockPending, "ALTER TABLE orders ADD COLUMN note TEXT"))
+	PopulateMetadataLockMetrics(dataSource, i, arguments.ArgumentList{QueryMonitoringCountThreshold: 20}, excludedDatabases)
+
+	require.Len(t, e.Metrics, 2)
+	assert.Equal(t, "MysqlMetadataLockSample", e.Metrics[0].Metrics["event_type"])
+	assert.Equal(t, "12", e.Metrics[0].Metrics["waiting_pid"])
+	assert.Equal(t, "10", e.Metrics[0].Metrics["blocking_pid"])
+	assert.Equal(t, "SELECT SLEEP(100) FROM orders WHERE id = 5", e.Metrics[0].Metrics["blocking_query"])
+	assert.Equal(t, "13", e.Metrics[1].Metrics["waiting_pid"])
+	assert.Equal(t, "12", e.Metrics[1].Metrics["blocking_pid"])
+	assert.Equal(t, metadataLockPending, e.Metrics[1].Metrics["blocking_lock_status"])
+
+	// The limit applies once the locks which don't conflict are left out
+	i, err = integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e = i.LocalEntity()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.metadata_locks w")).
+		WithArgs("mysql", "information_schema", "performance_schema", "sys").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("TABLE", "shop", "orders", "12", mdlExclusive, "ALTER TABLE orders ADD COLUMN note TEXT",
+				"11", "Sleep", mdlSharedUpgradable, metadataLockPending, nil).
+			AddRow("TABLE", "shop", "orders", "12", mdlExclusive, "ALTER TABLE orders ADD COLUMN note TEXT",
+				"10", "Query", mdlSharedRead, metadataLockGranted, "SELECT SLEEP(100) FROM orders WHERE id = 5").
+			AddRow("TABLE", "shop", "orders", "13", mdlSharedRead, "SELECT * FROM orders",
+				"12", "Query", mdlExclusive, metadataLockPending, "ALTER TABLE orders ADD COLUMN note TEXT"))
+	PopulateMetadataLockMetrics(dataSource, i, arguments.ArgumentList{QueryMonitoringCountThreshold: 1}, excludedDatabases)
+	require.Len(t, e.Metrics, 1)
+	assert.Equal(t, "12", e.Metrics[0].Metrics["waiting_pid"])
+	assert.Equal(t, "10", e.Metrics[0].Metrics["blocking_pid"])
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.metadata_locks w")).WillReturnError(errQuery)
+	PopulateMetadataLockMetrics(dataSource, i, arguments.ArgumentList{QueryMonitoringCountThreshold: 20}, excludedDatabases)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
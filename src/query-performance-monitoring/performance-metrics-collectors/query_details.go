@@ -14,14 +14,20 @@ import (
 )
 
 // PopulateSlowQueryMetrics collects and sets slow query metrics and returns the list of query IDs
-func PopulateSlowQueryMetrics(i *integration.Integration, db utils.DataSource, args arguments.ArgumentList, excludedDatabases []string) []string {
+func PopulateSlowQueryMetrics(i *integration.Integration, db utils.DataSource, args arguments.ArgumentList, excludedDatabases []string, capabilities validator.Capabilities) []string {
 	// Get the slow query fetch interval
 	slowQueryFetchInterval := validator.GetValidSlowQueryFetchIntervalThreshold(args.SlowQueryMonitoringFetchInterval)
 
 	// Get the query count threshold
 	queryCountThreshold := validator.GetValidQueryCountThreshold(args.QueryMonitoringCountThreshold)
 
-	rawMetrics, queryIDList, err := collectGroupedSlowQueryMetrics(db, slowQueryFetchInterval, queryCountThreshold, excludedDatabases)
+	// The CPU time of the statements is left unset when the server doesn't report it
+	slowQueries := utils.SlowQueries
+	if !capabilities.StatementCPUTime {
+		slowQueries = utils.SlowQueriesWithoutCPUTime
+	}
+
+	rawMetrics, queryIDList, err := collectGroupedSlowQueryMetrics(db, slowQueries, slowQueryFetchInterval, queryCountThreshold, excludedDatabases)
 	if err != nil {
 		log.Error("Failed to collect slow query metrics: %v", err)
 		return []string{}
@@ -32,6 +38,11 @@ func PopulateSlowQueryMetrics(i *integration.Integration, db utils.DataSource, a
 		return []string{}
 	}
 
+	// Add the latency percentiles of each digest
+	if capabilities.LatencyHistograms {
+		addSlowQueryLatencyPercentiles(db, rawMetrics, queryIDList)
+	}
+
 	// Set the slow query metrics to the integration entity and ingest them
 	err = setSlowQueryMetrics(i, rawMetrics, args)
 	if err != nil {
@@ -43,9 +54,9 @@ func PopulateSlowQueryMetrics(i *integration.Integration, db utils.DataSource, a
 }
 
 // collectGroupedSlowQueryMetrics collects metrics from the performance schema database for slow queries
-func collectGroupedSlowQueryMetrics(db utils.DataSource, slowQueryfetchInterval int, queryCountThreshold int, excludedDatabases []string) ([]utils.SlowQueryMetrics, []string, error) {
+func collectGroupedSlowQueryMetrics(db utils.DataSource, slowQueries string, slowQueryfetchInterval int, queryCountThreshold int, excludedDatabases []string) ([]utils.SlowQueryMetrics, []string, error) {
 	// Prepare the SQL query with the provided parameters
-	query, args, err := sqlx.In(utils.SlowQueries, slowQueryfetchInterval, excludedDatabases, queryCountThreshold)
+	query, args, err := sqlx.In(slowQueries, slowQueryfetchInterval, excludedDatabases, queryCountThreshold)
 	if err != nil {
 		return nil, []string{}, err
 	}
@@ -82,6 +93,73 @@ func collectGroupedSlowQueryMetrics(db utils.DataSource, slowQueryfetchInterval
 	return metrics, qIDList, nil
 }
 
+/*
+addSlowQueryLatencyPercentiles sets the p50, p95, p99 and p99.9 latencies of the slow queries from the latency histograms of their digests.
+The p95, p99 and p99.9 latencies of the digests without a histogram are taken from the QUANTILE_* columns of the digest summary,
+where available. Percentiles which can't be retrieved are left unset.
+*/
+func addSlowQueryLatencyPercentiles(db utils.DataSource, metrics []utils.SlowQueryMetrics, queryIDList []string) {
+	percentiles := collectSlowQueryLatencyPercentiles(db, utils.SlowQueryLatencyPercentilesQuery, queryIDList)
+
+	missingQueryIDs := []string{}
+	for _, metric := range metrics {
+		if _, ok := percentiles[slowQueryKey(metric.QueryID, metric.DatabaseName)]; !ok {
+			missingQueryIDs = append(missingQueryIDs, *metric.QueryID)
+		}
+	}
+	if len(missingQueryIDs) > 0 {
+		for key, quantiles := range collectSlowQueryLatencyPercentiles(db, utils.SlowQueryLatencyQuantilesQuery, missingQueryIDs) {
+			if _, ok := percentiles[key]; !ok {
+				percentiles[key] = quantiles
+			}
+		}
+	}
+
+	for n := range metrics {
+		if latency, ok := percentiles[slowQueryKey(metrics[n].QueryID, metrics[n].DatabaseName)]; ok {
+			metrics[n].P50ElapsedTimeMs = latency.P50ElapsedTimeMs
+			metrics[n].P95ElapsedTimeMs = latency.P95ElapsedTimeMs
+			metrics[n].P99ElapsedTimeMs = latency.P99ElapsedTimeMs
+			metrics[n].P999ElapsedTimeMs = latency.P999ElapsedTimeMs
+		}
+	}
+}
+
+// collectSlowQueryLatencyPercentiles runs one of the latency percentiles queries and returns the percentiles by digest and database.
+func collectSlowQueryLatencyPercentiles(db utils.DataSource, percentilesQuery string, queryIDList []string) map[string]utils.SlowQueryLatencyPercentiles {
+	percentiles := map[string]utils.SlowQueryLatencyPercentiles{}
+
+	query, args, err := sqlx.In(percentilesQuery, queryIDList)
+	if err != nil {
+		log.Error("Failed to prepare latency percentiles query: %v", err)
+		return percentiles
+	}
+
+	rows, err := utils.CollectMetrics[utils.SlowQueryLatencyPercentiles](db, query, args...)
+	if err != nil {
+		// The histogram and quantiles are not available in every server, e.g. MariaDB
+		log.Debug("Latency percentiles not available: %v", err)
+		return percentiles
+	}
+
+	for _, row := range rows {
+		percentiles[slowQueryKey(row.QueryID, row.DatabaseName)] = row
+	}
+	return percentiles
+}
+
+// slowQueryKey identifies a digest, which is reported once for each database it has been run in.
+func slowQueryKey(queryID, databaseName *string) string {
+	key := ""
+	if queryID != nil {
+		key = *queryID
+	}
+	if databaseName != nil {
+		key += "/" + *databaseName
+	}
+	return key
+}
+
 // setSlowQueryMetrics sets the collected slow query metrics to the integration
 func setSlowQueryMetrics(i *integration.Integration, metrics []utils.SlowQueryMetrics, args arguments.ArgumentList) error {
 	metricList := make([]interface{}, 0, len(metrics))
@@ -106,7 +184,7 @@ func PopulateIndividualQueryDetails(db utils.DataSource, queryIDList []string, i
 	}
 
 	// Prepare a copy of the query list for reporting
-	queryListPatchedCopy := setupQueryListCopyForReporting(queryList)
+	queryListPatchedCopy := setupQueryListCopyForReporting(queryList, args.QueryMonitoringReportSampleText)
 
 	// Ingest the patched copy of the query list for reporting
 	if err := utils.IngestMetric(queryListPatchedCopy, "MysqlIndividualQueriesSample", i, args); err != nil {
@@ -149,8 +227,9 @@ func getIndividualQueryList(db utils.DataSource, queryIDList []string, args argu
 	return allMetrics, nil
 }
 
-// setupQueryListCopyForReporting prepares the query list by removing unnecessary data
-func setupQueryListCopyForReporting(originalQueryList []utils.IndividualQueryMetrics) []interface{} {
+// setupQueryListCopyForReporting prepares the query list by removing unnecessary data.
+// The sample text is kept when reportSampleText is set, and its literals are replaced on ingestion.
+func setupQueryListCopyForReporting(originalQueryList []utils.IndividualQueryMetrics, reportSampleText bool) []interface{} {
 	// Create a new list to hold the modified query metrics
 	modifiedQueryList := make([]utils.IndividualQueryMetrics, len(originalQueryList))
 
@@ -162,8 +241,10 @@ func setupQueryListCopyForReporting(originalQueryList []utils.IndividualQueryMet
 
 	// Iterate over the modified query list and remove the QueryText field from each metric
 	for i := range modifiedQueryList {
-		// Exclude QueryText from ingestion as it is only used for fetching the query execution plan
-		modifiedQueryList[i].QueryText = nil
+		// Exclude QueryText from ingestion unless requested, as it is only used for fetching the query execution plan
+		if !reportSampleText {
+			modifiedQueryList[i].QueryText = nil
+		}
 
 		// Add the modified query metric to the list for ingestion
 		metricsForIngestion = append(metricsForIngestion, modifiedQueryList[i])
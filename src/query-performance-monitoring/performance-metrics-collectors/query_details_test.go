@@ -2,6 +2,7 @@ package performancemetricscollectors
 
 import (
 	"errors"
+	"regexp"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -9,6 +10,7 @@ import (
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	arguments "github.com/newrelic/nri-mysql/src/args"
 	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -52,6 +54,20 @@ func TestSetSlowQueryMetrics(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestSetupQueryListCopyForReporting(t *testing.T) {
+	queryID := "1"
+	queryText := "SELECT * FROM users WHERE id = 1"
+	queryList := []utils.IndividualQueryMetrics{{QueryID: &queryID, QueryText: &queryText}}
+
+	withoutText := setupQueryListCopyForReporting(queryList, false)
+	assert.Nil(t, withoutText[0].(utils.IndividualQueryMetrics).QueryText)
+	// The original list keeps the text, as it is needed for the execution plans
+	assert.Equal(t, &queryText, queryList[0].QueryText)
+
+	withText := setupQueryListCopyForReporting(queryList, true)
+	assert.Equal(t, &queryText, withText[0].(utils.IndividualQueryMetrics).QueryText)
+}
+
 func TestGroupQueriesByDatabase(t *testing.T) {
 	queryText1 := "SELECT * FROM test_table1"
 	queryText2 := "SELECT * FROM test_table2"
@@ -188,7 +204,7 @@ func TestPopulateSlowQueryMetrics(t *testing.T) {
 			return nil, nil, errFailedToCollectMetrics
 		}
 
-		queryIDList := PopulateSlowQueryMetrics(i, mockDB, args, excludedDatabases)
+		queryIDList := PopulateSlowQueryMetrics(i, mockDB, args, excludedDatabases, mysql8Capabilities)
 		assert.Empty(t, queryIDList)
 	})
 
@@ -197,7 +213,7 @@ func TestPopulateSlowQueryMetrics(t *testing.T) {
 			return []utils.IndividualQueryMetrics{}, []string{}, nil
 		}
 
-		queryIDList := PopulateSlowQueryMetrics(i, mockDB, args, excludedDatabases)
+		queryIDList := PopulateSlowQueryMetrics(i, mockDB, args, excludedDatabases, mysql8Capabilities)
 		assert.Empty(t, queryIDList)
 	})
 
@@ -225,7 +241,113 @@ func TestPopulateSlowQueryMetrics(t *testing.T) {
 			return errFailedToSetMetrics
 		}
 
-		queryIDList := PopulateSlowQueryMetrics(i, mockDB, args, excludedDatabases)
+		queryIDList := PopulateSlowQueryMetrics(i, mockDB, args, excludedDatabases, mysql8Capabilities)
 		assert.Empty(t, queryIDList)
 	})
 }
+
+func TestPopulateSlowQueryMetricsWithoutCPUTime(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+	args := arguments.ArgumentList{SlowQueryMonitoringFetchInterval: 60, QueryMonitoringCountThreshold: 10}
+
+	// The CPU time is left unset, and the latency percentiles are not queried
+	mock.ExpectQuery(regexp.QuoteMeta("NULL AS avg_cpu_time_ms")).
+		WithArgs(60, "mysql", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"query_id", "query_text", "database_name", "avg_cpu_time_ms", "avg_elapsed_time_ms"}).
+			AddRow("digest1", "SELECT * FROM orders WHERE id = ?", "shop", nil, 12.5))
+	queryIDList := PopulateSlowQueryMetrics(i, &DataSource{DB: sqlx.NewDb(sqlDB, "sqlmock")}, args, []string{"mysql"},
+		validator.Capabilities{Version: "5.7.44", PerformanceSchema: true})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Equal(t, []string{"digest1"}, queryIDList)
+	require.Len(t, e.Metrics, 1)
+	assert.Equal(t, 12.5, e.Metrics[0].Metrics["avg_elapsed_time_ms"])
+	assert.NotContains(t, e.Metrics[0].Metrics, "avg_cpu_time_ms")
+	assert.NotContains(t, e.Metrics[0].Metrics, "p95_elapsed_time_ms")
+}
+
+func TestAddSlowQueryLatencyPercentiles(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	dataSource := &DataSource{DB: sqlx.NewDb(sqlDB, "sqlmock")}
+
+	metrics := []utils.SlowQueryMetrics{
+		{QueryID: ptr("digest1"), DatabaseName: ptr("shop"), MaxElapsedTimeMs: ptr(900.0)},
+		{QueryID: ptr("digest2"), DatabaseName: ptr("shop")},
+		{QueryID: ptr("digest3"), DatabaseName: ptr("shop")},
+	}
+	queryIDList := []string{"digest1", "digest2", "digest3"}
+
+	histogramQuery, histogramArgs, err := sqlx.In(utils.SlowQueryLatencyPercentilesQuery, queryIDList)
+	require.NoError(t, err)
+	mock.ExpectQuery(regexp.QuoteMeta(histogramQuery)).WithArgs(convertToDriverValue(histogramArgs)...).WillReturnRows(
+		sqlmock.NewRows([]string{"query_id", "database_name", "p50_elapsed_time_ms", "p95_elapsed_time_ms", "p99_elapsed_time_ms", "p999_elapsed_time_ms"}).
+			AddRow("digest1", "shop", 1.2, 120.5, 630.9, 1020.4))
+
+	quantilesQuery, quantilesArgs, err := sqlx.In(utils.SlowQueryLatencyQuantilesQuery, []string{"digest2", "digest3"})
+	require.NoError(t, err)
+	mock.ExpectQuery(regexp.QuoteMeta(quantilesQuery)).WithArgs(convertToDriverValue(quantilesArgs)...).WillReturnRows(
+		sqlmock.NewRows([]string{"query_id", "database_name", "p95_elapsed_time_ms", "p99_elapsed_time_ms", "p999_elapsed_time_ms"}).
+			AddRow("digest2", "shop", 45.0, 60.0, 75.5))
+
+	addSlowQueryLatencyPercentiles(dataSource, metrics, queryIDList)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// From the histogram
+	assert.Equal(t, 1.2, *metrics[0].P50ElapsedTimeMs)
+	assert.Equal(t, 120.5, *metrics[0].P95ElapsedTimeMs)
+	assert.Equal(t, 630.9, *metrics[0].P99ElapsedTimeMs)
+	assert.Equal(t, 1020.4, *metrics[0].P999ElapsedTimeMs)
+	assert.Equal(t, 900.0, *metrics[0].MaxElapsedTimeMs)
+
+	// From the quantiles of the digest summary, which have no median
+	assert.Nil(t, metrics[1].P50ElapsedTimeMs)
+	assert.Equal(t, 45.0, *metrics[1].P95ElapsedTimeMs)
+	assert.Equal(t, 60.0, *metrics[1].P99ElapsedTimeMs)
+	assert.Equal(t, 75.5, *metrics[1].P999ElapsedTimeMs)
+
+	// Not available
+	assert.Nil(t, metrics[2].P95ElapsedTimeMs)
+}
+
+func TestAddSlowQueryLatencyPercentilesNotAvailable(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+	dataSource := &DataSource{DB: sqlx.NewDb(sqlDB, "sqlmock")}
+
+	metrics := []utils.SlowQueryMetrics{{QueryID: ptr("digest1"), DatabaseName: ptr("shop")}}
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.events_statements_histogram_by_digest")).WillReturnError(errQuery)
+	mock.ExpectQuery(regexp.QuoteMeta("QUANTILE_95")).WillReturnError(errQuery)
+
+	addSlowQueryLatencyPercentiles(dataSource, metrics, []string{"digest1"})
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Nil(t, metrics[0].P50ElapsedTimeMs)
+	assert.Nil(t, metrics[0].P95ElapsedTimeMs)
+	assert.Nil(t, metrics[0].P99ElapsedTimeMs)
+	assert.Nil(t, metrics[0].P999ElapsedTimeMs)
+}
+
+func TestSampleTextReportedWithoutLiterals(t *testing.T) {
+	queryID := "1"
+	queryText := "SELECT * FROM users WHERE email = 'jane@example.com' AND card = 4111111111111111 AND id IN (1, 2)"
+	queryList := []utils.IndividualQueryMetrics{{QueryID: &queryID, QueryText: &queryText}}
+
+	// With the default policy, which leaves the other attributes as they are
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+	args := arguments.ArgumentList{QueryMonitoringRedactionPolicy: utils.RedactionPolicyNone, QueryMonitoringReportSampleText: true}
+	require.NoError(t, utils.IngestMetric(setupQueryListCopyForReporting(queryList, true), "MysqlIndividualQueriesSample", i, args))
+
+	require.Len(t, e.Metrics, 1)
+	assert.Equal(t, "SELECT * FROM users WHERE email = ? AND card = ? AND id IN (?, ?)", e.Metrics[0].Metrics["query_sample_text"])
+}
@@ -0,0 +1,163 @@
+package performancemetricscollectors
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/constants"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
+)
+
+const (
+	// explainAnalyzeIndent is the number of spaces each level of the EXPLAIN ANALYZE tree is indented with.
+	explainAnalyzeIndent = 4
+	explainAnalyzeNumber = `([0-9.]+(?:e[+-]?[0-9]+)?)`
+)
+
+/*
+explainAnalyzeIteratorPattern matches an iterator of the EXPLAIN ANALYZE tree, with its estimates and its actual figures, e.g.:
+
+	-> Filter: (t1.b > 3)  (cost=1.80 rows=9) (actual time=0.060..0.080 rows=9 loops=1)
+	    -> Index lookup on t2 using idx_a (a=t1.a)  (cost=0.25 rows=1) (never executed)
+*/
+var explainAnalyzeIteratorPattern = regexp.MustCompile(`^( *)-> (.*?)` +
+	`(?:\s+\(cost=(?:[0-9.e+-]+\.\.)?` + explainAnalyzeNumber + ` rows=` + explainAnalyzeNumber + `\))?` +
+	`(?:\s+\(actual time=` + explainAnalyzeNumber + `\.\.` + explainAnalyzeNumber + ` rows=` + explainAnalyzeNumber + ` loops=([0-9]+)\)|\s+\((never executed)\))?\s*$`)
+
+// queryAnalyzer runs EXPLAIN ANALYZE once per collection for each allow-listed digest.
+type queryAnalyzer struct {
+	digests   []string
+	timeLimit time.Duration
+	analyzed  map[string]bool
+}
+
+func newQueryAnalyzer(args arguments.ArgumentList) *queryAnalyzer {
+	return &queryAnalyzer{
+		digests:   utils.GetAnalyzeDigests(args.QueryMonitoringAnalyzeDigests),
+		timeLimit: time.Duration(validator.GetValidAnalyzeTimeLimit(args.QueryMonitoringAnalyzeTimeLimit)) * time.Millisecond,
+		analyzed:  map[string]bool{},
+	}
+}
+
+/*
+shouldAnalyze checks if the query is the first one of an allow-listed digest in this collection. Since EXPLAIN ANALYZE
+executes the query, a digest is only analyzed once, even if the execution fails or exceeds the time limit.
+*/
+func (a *queryAnalyzer) shouldAnalyze(query utils.IndividualQueryMetrics) bool {
+	if query.QueryID == nil || a.analyzed[*query.QueryID] || !slices.Contains(a.digests, *query.QueryID) {
+		return false
+	}
+	a.analyzed[*query.QueryID] = true
+	return true
+}
+
+/*
+processExplainAnalyzeMetrics runs EXPLAIN ANALYZE for the query and returns the figures of each iterator of its plan.
+Only queries which don't modify data are analyzed, and they run within a read-only transaction limited to timeLimit.
+*/
+func processExplainAnalyzeMetrics(db utils.DataSource, query utils.IndividualQueryMetrics, timeLimit time.Duration) ([]utils.QueryPlanAnalyzeMetrics, error) {
+	queryID, err := getQueryID(query)
+	if err != nil {
+		return nil, err
+	}
+	queryText, err := getQueryText(query, queryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSupportedStatement(queryText, []string{"SELECT", "WITH"}) || hasSideEffects(queryText) || utils.HasPlaceholders(queryText) {
+		log.Warn("Skipping query not supported for EXPLAIN ANALYZE. Query ID: %s", queryID)
+		return nil, nil
+	}
+
+	readOnlyDB, ok := db.(utils.ReadOnlyDataSource)
+	if !ok {
+		return nil, fmt.Errorf("%w: queries are only analyzed within a read-only transaction", utils.ErrReadOnlyNotSupported)
+	}
+
+	// The context outlives the time limit, so the server aborts the query before the connection is dropped
+	ctx, cancel := context.WithTimeout(context.Background(), timeLimit+constants.QueryPlanTimeoutDuration)
+	defer cancel()
+
+	var tree string
+	if err = readOnlyDB.QueryRowReadOnlyTimeLimitContext(ctx, timeLimit, fmt.Sprintf(constants.ExplainAnalyzeQueryFormat, queryText), &tree); err != nil {
+		return nil, fmt.Errorf("error running EXPLAIN ANALYZE for query ID %s: %w", queryID, err)
+	}
+
+	iterators := parseExplainAnalyzeTree(tree)
+	for i := range iterators {
+		iterators[i].QueryID = queryID
+		if query.EventID != nil {
+			iterators[i].EventID = *query.EventID
+		}
+		if query.ThreadID != nil {
+			iterators[i].ThreadID = *query.ThreadID
+		}
+	}
+	return iterators, nil
+}
+
+// parseExplainAnalyzeTree parses the EXPLAIN ANALYZE tree into its iterators, in the order they are listed.
+// Lines which are not iterators, such as the continuation of a long description, are ignored.
+func parseExplainAnalyzeTree(tree string) []utils.QueryPlanAnalyzeMetrics {
+	var iterators []utils.QueryPlanAnalyzeMetrics
+	for _, line := range strings.Split(tree, "\n") {
+		match := explainAnalyzeIteratorPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		iterator := utils.QueryPlanAnalyzeMetrics{
+			StepID:               len(iterators),
+			Depth:                len(match[1]) / explainAnalyzeIndent,
+			Operation:            strings.TrimSpace(match[2]),
+			EstimatedCost:        parseExplainAnalyzeNumber(match[3]),
+			EstimatedRows:        parseExplainAnalyzeNumber(match[4]),
+			ActualFirstRowTimeMs: parseExplainAnalyzeNumber(match[5]),
+			ActualLastRowTimeMs:  parseExplainAnalyzeNumber(match[6]),
+			ActualRows:           parseExplainAnalyzeNumber(match[7]),
+		}
+		if loops, err := strconv.ParseUint(match[8], 10, 64); err == nil {
+			iterator.Loops = &loops
+		}
+		iterator.Executed = strconv.FormatBool(match[9] == "" && iterator.ActualRows != nil)
+
+		// Both rows figures are per loop, so their ratio tells how far the estimate is from the actual rows
+		if iterator.ActualRows != nil && iterator.EstimatedRows != nil && *iterator.EstimatedRows > 0 {
+			ratio := *iterator.ActualRows / *iterator.EstimatedRows
+			iterator.RowsEstimateRatio = &ratio
+		}
+		iterators = append(iterators, iterator)
+	}
+	return iterators
+}
+
+func parseExplainAnalyzeNumber(value string) *float64 {
+	if value == "" {
+		return nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil
+	}
+	return &number
+}
+
+// setExplainAnalyzeMetrics sets the EXPLAIN ANALYZE metrics into the integration entity.
+func setExplainAnalyzeMetrics(i *integration.Integration, args arguments.ArgumentList, metrics []utils.QueryPlanAnalyzeMetrics) error {
+	metricList := make([]interface{}, 0, len(metrics))
+	for _, metricData := range metrics {
+		metricList = append(metricList, metricData)
+	}
+
+	return utils.IngestMetric(metricList, "MysqlQueryExecutionAnalyzeSample", i, args)
+}
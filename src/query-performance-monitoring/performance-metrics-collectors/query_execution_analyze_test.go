@@ -0,0 +1,157 @@
+package performancemetricscollectors
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const explainAnalyzeTree = `-> Nested loop inner join  (cost=4.95 rows=9) (actual time=0.153..0.200 rows=900 loops=1)
+    -> Filter: (t1.b > 3)  (cost=1.80 rows=9) (actual time=0.060..0.080 rows=9 loops=1)
+        -> Table scan on t1  (cost=1.80 rows=9) (actual time=0.056..0.071 rows=9 loops=1)
+    -> Index lookup on t2 using idx_a (a=t1.a)  (cost=0.25 rows=1) (never executed)
+    -> Sort: t1.a  (actual time=0.010..0.011 rows=1e+3 loops=2)
+`
+
+func TestParseExplainAnalyzeTree(t *testing.T) {
+	iterators := parseExplainAnalyzeTree(explainAnalyzeTree)
+	require.Len(t, iterators, 5)
+
+	join := iterators[0]
+	assert.Equal(t, 0, join.StepID)
+	assert.Equal(t, 0, join.Depth)
+	assert.Equal(t, "Nested loop inner join", join.Operation)
+	assert.Equal(t, 4.95, *join.EstimatedCost)
+	assert.Equal(t, float64(9), *join.EstimatedRows)
+	assert.Equal(t, 0.153, *join.ActualFirstRowTimeMs)
+	assert.Equal(t, 0.2, *join.ActualLastRowTimeMs)
+	assert.Equal(t, float64(900), *join.ActualRows)
+	assert.Equal(t, uint64(1), *join.Loops)
+	assert.Equal(t, float64(100), *join.RowsEstimateRatio)
+	assert.Equal(t, "true", join.Executed)
+
+	assert.Equal(t, "Filter: (t1.b > 3)", iterators[1].Operation)
+	assert.Equal(t, 1, iterators[1].Depth)
+	assert.Equal(t, 2, iterators[2].Depth)
+	assert.Equal(t, float64(1), *iterators[1].RowsEstimateRatio)
+
+	lookup := iterators[3]
+	assert.Equal(t, "Index lookup on t2 using idx_a (a=t1.a)", lookup.Operation)
+	assert.Equal(t, "false", lookup.Executed)
+	assert.Nil(t, lookup.ActualRows)
+	assert.Nil(t, lookup.Loops)
+	assert.Nil(t, lookup.RowsEstimateRatio)
+
+	sort := iterators[4]
+	assert.Nil(t, sort.EstimatedRows)
+	assert.Equal(t, float64(1000), *sort.ActualRows)
+	assert.Equal(t, uint64(2), *sort.Loops)
+	assert.Nil(t, sort.RowsEstimateRatio)
+
+	assert.Empty(t, parseExplainAnalyzeTree("EXPLAIN ANALYZE is not supported"))
+}
+
+func TestQueryAnalyzerShouldAnalyze(t *testing.T) {
+	analyzer := newQueryAnalyzer(arguments.ArgumentList{QueryMonitoringAnalyzeDigests: `["digest1"]`})
+	assert.Equal(t, 500*time.Millisecond, analyzer.timeLimit)
+
+	digest1 := "digest1"
+	digest2 := "digest2"
+	assert.False(t, analyzer.shouldAnalyze(utils.IndividualQueryMetrics{}))
+	assert.False(t, analyzer.shouldAnalyze(utils.IndividualQueryMetrics{QueryID: &digest2}))
+	assert.True(t, analyzer.shouldAnalyze(utils.IndividualQueryMetrics{QueryID: &digest1}))
+	// Each digest is only analyzed once per collection
+	assert.False(t, analyzer.shouldAnalyze(utils.IndividualQueryMetrics{QueryID: &digest1}))
+}
+
+func TestProcessExplainAnalyzeMetrics(t *testing.T) {
+	queryID := "digest1"
+	eventID := uint64(10)
+	threadID := uint64(20)
+
+	t.Run("Analyzed within the time limit", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		queryText := "SELECT * FROM t1 JOIN t2 ON t1.a = t2.a WHERE t1.b > 3"
+		mock.ExpectBegin()
+		mock.ExpectExec("SET SESSION max_execution_time = 200").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta("EXPLAIN ANALYZE FORMAT=TREE " + queryText)).
+			WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(explainAnalyzeTree))
+		mock.ExpectRollback()
+
+		query := utils.IndividualQueryMetrics{QueryID: &queryID, QueryText: &queryText, EventID: &eventID, ThreadID: &threadID}
+		iterators, err := processExplainAnalyzeMetrics(readOnlyDataSource{&DataSource{DB: sqlx.NewDb(db, "sqlmock")}}, query, 200*time.Millisecond)
+		require.NoError(t, err)
+		require.Len(t, iterators, 5)
+		assert.Equal(t, queryID, iterators[0].QueryID)
+		assert.Equal(t, eventID, iterators[0].EventID)
+		assert.Equal(t, threadID, iterators[0].ThreadID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Statements modifying data are not analyzed", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		queryText := "UPDATE t1 SET b = 1 WHERE a = 2"
+		query := utils.IndividualQueryMetrics{QueryID: &queryID, QueryText: &queryText}
+		iterators, err := processExplainAnalyzeMetrics(readOnlyDataSource{&DataSource{DB: sqlx.NewDb(db, "sqlmock")}}, query, time.Second)
+		assert.NoError(t, err)
+		assert.Empty(t, iterators)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Locking reads are not analyzed", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		queryText := "SELECT * FROM t1 WHERE a = 2 FOR UPDATE"
+		query := utils.IndividualQueryMetrics{QueryID: &queryID, QueryText: &queryText}
+		iterators, err := processExplainAnalyzeMetrics(readOnlyDataSource{&DataSource{DB: sqlx.NewDb(db, "sqlmock")}}, query, time.Second)
+		assert.NoError(t, err)
+		assert.Empty(t, iterators)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Data source without read-only transactions", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		queryText := "SELECT * FROM t1"
+		query := utils.IndividualQueryMetrics{QueryID: &queryID, QueryText: &queryText}
+		_, err = processExplainAnalyzeMetrics(&DataSource{DB: sqlx.NewDb(db, "sqlmock")}, query, time.Second)
+		assert.ErrorIs(t, err, utils.ErrReadOnlyNotSupported)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSetExplainAnalyzeMetrics(t *testing.T) {
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+
+	iterators := parseExplainAnalyzeTree(explainAnalyzeTree)
+	args := arguments.ArgumentList{QueryMonitoringRedactionPolicy: utils.RedactionPolicyLiterals}
+	require.NoError(t, setExplainAnalyzeMetrics(i, args, iterators))
+
+	ms := e.Metrics
+	require.Len(t, ms, 5)
+	assert.Equal(t, "MysqlQueryExecutionAnalyzeSample", ms[1].Metrics["event_type"])
+	// The conditions of the operations are redacted as the query text when a policy is configured
+	assert.Equal(t, "Filter: (t1.b > ?)", ms[1].Metrics["operation"])
+	assert.Equal(t, float64(9), ms[1].Metrics["estimated_rows"])
+	assert.Equal(t, float64(9), ms[1].Metrics["actual_rows"])
+}
@@ -5,6 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/bitly/go-simplejson"
@@ -12,13 +15,49 @@ import (
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 	arguments "github.com/newrelic/nri-mysql/src/args"
 	dbutils "github.com/newrelic/nri-mysql/src/dbutils"
+	infrautils "github.com/newrelic/nri-mysql/src/infrautils"
 	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/constants"
 	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
+)
+
+var (
+	// insertSourcePattern matches the first clause telling where the rows of an INSERT or REPLACE come from.
+	insertSourcePattern = regexp.MustCompile(`(?i)\b(SELECT|WITH|SET|TABLE)\b|\b(VALUES?)\s*\(`)
+	sideEffectsPattern  = regexp.MustCompile(`(?i);|:=|\bINTO\s+(OUTFILE|DUMPFILE|@)|\bFOR\s+(UPDATE|SHARE)\b|\bLOCK\s+IN\s+SHARE\s+MODE\b|\b(SLEEP|BENCHMARK|GET_LOCK|RELEASE_LOCK|RELEASE_ALL_LOCKS|LOAD_FILE|SOURCE_POS_WAIT|MASTER_POS_WAIT|WAIT_FOR_EXECUTED_GTID_SET)\s*\(`)
 )
 
 // PopulateExecutionPlans populates execution plans for the given queries.
-func PopulateExecutionPlans(db utils.DataSource, queryGroups map[string][]utils.IndividualQueryMetrics, i *integration.Integration, args arguments.ArgumentList) {
+func PopulateExecutionPlans(db utils.DataSource, queryGroups map[string][]utils.IndividualQueryMetrics, i *integration.Integration, args arguments.ArgumentList, capabilities validator.Capabilities) {
 	var events []utils.QueryPlanMetrics
+	redactor := utils.NewRedactor(args)
+	statementTypes := utils.GetExplainStatementTypes(args.QueryMonitoringExplainStatementTypes)
+	analyzer := newQueryAnalyzer(args)
+	if !capabilities.ExplainAnalyze && len(analyzer.digests) > 0 {
+		log.Warn("EXPLAIN ANALYZE requires MySQL 8.0.18+, the digests are not analyzed on version %s", capabilities.Version)
+		analyzer.digests = nil
+	}
+	var analyzeEvents []utils.QueryPlanAnalyzeMetrics
+	var planChanges []utils.QueryPlanChangeMetrics
+	var indexRecommendations []utils.IndexRecommendationMetrics
+
+	// The last known plan of each digest is kept between executions to detect when it changes
+	var planDetector *planChangeDetector
+	if store, err := infrautils.NewStoreWithTTL(args, planStoreName, constants.QueryPlanFingerprintTTL); err != nil {
+		log.Warn("Plan changes are not detected: %v", err)
+	} else {
+		planDetector = newPlanChangeDetector(store)
+		defer planDetector.save()
+	}
+
+	// The reported index recommendations are kept between executions, so they are not reported at every execution
+	var advisor *indexAdvisor
+	if store, err := infrautils.NewStoreWithTTL(args, indexRecommendationStoreName, constants.IndexRecommendationTTL); err != nil {
+		log.Warn("Indexes are not recommended: %v", err)
+	} else {
+		advisor = newIndexAdvisor(store)
+		defer advisor.save()
+	}
 
 	for dbName, queries := range queryGroups {
 		dsn := dbutils.GenerateDSN(args, dbName)
@@ -31,12 +70,50 @@ func PopulateExecutionPlans(db utils.DataSource, queryGroups map[string][]utils.
 		defer db.Close()
 
 		for _, query := range queries {
-			tableIngestionDataList, err := processExecutionPlanMetrics(db, query)
+			if analyzer.shouldAnalyze(query) {
+				iterators, err := processExplainAnalyzeMetrics(db, query, analyzer.timeLimit)
+				if err != nil {
+					log.Error("Error processing EXPLAIN ANALYZE metrics: %v", err)
+				}
+				analyzeEvents = append(analyzeEvents, iterators...)
+			}
+
+			tableIngestionDataList, err := processExecutionPlanMetrics(db, query, statementTypes, redactor)
 			if err != nil {
 				log.Error("Error processing execution plan metrics: %v", err)
 				continue
 			}
 			events = append(events, tableIngestionDataList...)
+
+			if planDetector != nil && len(tableIngestionDataList) > 0 {
+				if change := planDetector.detect(tableIngestionDataList[0].QueryID, dbName, tableIngestionDataList); change != nil {
+					planChanges = append(planChanges, *change)
+				}
+			}
+			if advisor != nil && len(tableIngestionDataList) > 0 && query.QueryText != nil {
+				indexRecommendations = append(indexRecommendations, advisor.advise(tableIngestionDataList[0].QueryID, dbName, *query.QueryText, tableIngestionDataList)...)
+			}
+		}
+	}
+
+	// Set the plan changes of the digests
+	if len(planChanges) > 0 {
+		if err := setPlanChangeMetrics(i, args, planChanges); err != nil {
+			log.Error("Error publishing query plan changes: %v", err)
+		}
+	}
+
+	// Set the index recommendations for the plans of the digests
+	if len(indexRecommendations) > 0 {
+		if err := setIndexRecommendationMetrics(i, args, indexRecommendations); err != nil {
+			log.Error("Error publishing index recommendations: %v", err)
+		}
+	}
+
+	// Set the actual execution figures of the analyzed queries
+	if len(analyzeEvents) > 0 {
+		if err := setExplainAnalyzeMetrics(i, args, analyzeEvents); err != nil {
+			log.Error("Error publishing EXPLAIN ANALYZE metrics: %v", err)
 		}
 	}
 
@@ -53,8 +130,9 @@ func PopulateExecutionPlans(db utils.DataSource, queryGroups map[string][]utils.
 	}
 }
 
-// processExecutionPlanMetrics processes the execution plan metrics for a given query.
-func processExecutionPlanMetrics(db utils.DataSource, query utils.IndividualQueryMetrics) ([]utils.QueryPlanMetrics, error) {
+// processExecutionPlanMetrics processes the execution plan metrics for a given query of one of the given statement types.
+// The strings of the plan are redacted by the configured policy, since its conditions hold the literals of the query.
+func processExecutionPlanMetrics(db utils.DataSource, query utils.IndividualQueryMetrics, statementTypes []string, redactor *utils.Redactor) ([]utils.QueryPlanMetrics, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), constants.QueryPlanTimeoutDuration)
 	defer cancel()
 
@@ -68,13 +146,24 @@ func processExecutionPlanMetrics(db utils.DataSource, query utils.IndividualQuer
 		return []utils.QueryPlanMetrics{}, err
 	}
 
-	if !isSupportedStatement(queryText) {
+	if !isSupportedStatement(queryText, statementTypes) {
 		log.Warn("Skipping unsupported query for EXPLAIN: %s. Query ID: %s", queryText, queryID)
 		return []utils.QueryPlanMetrics{}, nil
 	}
 
-	if strings.Contains(queryText, "?") {
-		log.Warn("Skipping query with placeholders for EXPLAIN: %s. Query ID: %s", queryText, queryID)
+	// Statements with placeholders, such as the ones of prepared statements, can't be explained as they are
+	planStrategy := planStrategyQueryText
+	if utils.HasPlaceholders(queryText) {
+		resolvedText, strategy := resolvePlaceholders(db, queryID, queryText)
+		if strategy == "" {
+			log.Warn("Skipping query with placeholders for EXPLAIN, no values were found for them: %s. Query ID: %s", queryText, queryID)
+			return []utils.QueryPlanMetrics{}, nil
+		}
+		queryText, planStrategy = resolvedText, strategy
+	}
+
+	if hasSideEffects(queryText) {
+		log.Warn("Skipping query with side effects for EXPLAIN. Query ID: %s", queryID)
 		return []utils.QueryPlanMetrics{}, nil
 	}
 
@@ -83,7 +172,13 @@ func processExecutionPlanMetrics(db utils.DataSource, query utils.IndividualQuer
 		return []utils.QueryPlanMetrics{}, err
 	}
 
-	escapedJSON, err := escapeAllStringsInJSON(execPlanJSON)
+	redactedJSON, err := redactor.RedactJSON(execPlanJSON)
+	if err != nil {
+		log.Error("Error redacting strings in JSON for query ID '%s': %v", queryID, err)
+		return []utils.QueryPlanMetrics{}, err
+	}
+
+	escapedJSON, err := escapeAllStringsInJSON(redactedJSON)
 	if err != nil {
 		log.Error("Error escaping strings in JSON for query '%s': %v", queryText, err)
 		return []utils.QueryPlanMetrics{}, err
@@ -94,6 +189,14 @@ func processExecutionPlanMetrics(db utils.DataSource, query utils.IndividualQuer
 		return []utils.QueryPlanMetrics{}, err
 	}
 
+	// Identify the steps with the digest and the plan they belong to, and how the explained text was produced
+	planHash := newPlanFingerprint(dbPerformanceEvents).Hash
+	for i := range dbPerformanceEvents {
+		dbPerformanceEvents[i].QueryID = queryID
+		dbPerformanceEvents[i].PlanHash = planHash
+		dbPerformanceEvents[i].PlanStrategy = planStrategy
+	}
+
 	return dbPerformanceEvents, nil
 }
 
@@ -121,16 +224,32 @@ func getQueryText(query utils.IndividualQueryMetrics, queryID string) (string, e
 	return queryText, nil
 }
 
-// executeExplainQuery executes the EXPLAIN query and returns the result as a JSON string.
+/*
+executeExplainQuery executes the EXPLAIN query and returns the result as a JSON string.
+The EXPLAIN is run within a read-only transaction, so the explained statement can't modify any data. Data sources
+not supporting read-only transactions are only used to explain queries, which don't modify data.
+*/
 func executeExplainQuery(ctx context.Context, db utils.DataSource, queryText string) (string, error) {
 	execPlanQuery := fmt.Sprintf(constants.ExplainQueryFormat, queryText)
+
+	var execPlanJSON string
+	if readOnlyDB, ok := db.(utils.ReadOnlyDataSource); ok {
+		if err := readOnlyDB.QueryRowReadOnlyContext(ctx, execPlanQuery, &execPlanJSON); err != nil {
+			return "", err
+		}
+		return execPlanJSON, nil
+	}
+
+	if statementType := getStatementType(queryText); statementType != "SELECT" && statementType != "WITH" {
+		return "", fmt.Errorf("%w: %s statements are only explained within a read-only transaction", utils.ErrReadOnlyNotSupported, statementType)
+	}
+
 	rows, err := db.QueryxContext(ctx, execPlanQuery)
 	if err != nil {
 		return "", err
 	}
 	defer rows.Close()
 
-	var execPlanJSON string
 	if rows.Next() {
 		err := rows.Scan(&execPlanJSON)
 		if err != nil {
@@ -214,21 +333,23 @@ func extractMetricsFromJSONString(jsonString string, eventID uint64, threadID ui
 // extractMetrics recursively retrieves metrics from the query plan.
 func extractMetrics(js *simplejson.Json, dbPerformanceEvents []utils.QueryPlanMetrics, eventID uint64, threadID uint64, memo utils.Memo, stepID *int) []utils.QueryPlanMetrics {
 	tableName, _ := js.Get("table_name").String()
-	queryCost, _ := js.Get("cost_info").Get("query_cost").String()
+	queryCost := planFigure(js.Get("cost_info").Get("query_cost"))
 	accessType, _ := js.Get("access_type").String()
 	rowsExaminedPerScan, _ := js.Get("rows_examined_per_scan").Int64()
 	rowsProducedPerJoin, _ := js.Get("rows_produced_per_join").Int64()
-	filtered, _ := js.Get("filtered").String()
-	readCost, _ := js.Get("cost_info").Get("read_cost").String()
-	evalCost, _ := js.Get("cost_info").Get("eval_cost").String()
-	prefixCost, _ := js.Get("cost_info").Get("prefix_cost").String()
-	dataReadPerJoin, _ := js.Get("cost_info").Get("data_read_per_join").String()
+	filtered := planFigure(js.Get("filtered"))
+	readCost := planFigure(js.Get("cost_info").Get("read_cost"))
+	evalCost := planFigure(js.Get("cost_info").Get("eval_cost"))
+	prefixCost := planFigure(js.Get("cost_info").Get("prefix_cost"))
+	dataReadPerJoin := planFigure(js.Get("cost_info").Get("data_read_per_join"))
 	usingIndex, _ := js.Get("using_index").Bool()
-	keyLength, _ := js.Get("key_length").String()
+	keyLength := planFigure(js.Get("key_length"))
 	possibleKeysArray, _ := js.Get("possible_keys").StringArray()
 	key, _ := js.Get("key").String()
 	usedKeyPartsArray, _ := js.Get("used_key_parts").StringArray()
 	refArray, _ := js.Get("ref").StringArray()
+	usingFilesort, _ := js.Get("using_filesort").Bool()
+	usingTemporaryTable, _ := js.Get("using_temporary_table").Bool()
 
 	possibleKeys := strings.Join(possibleKeysArray, ",")
 	usedKeyParts := strings.Join(usedKeyPartsArray, ",")
@@ -237,6 +358,12 @@ func extractMetrics(js *simplejson.Json, dbPerformanceEvents []utils.QueryPlanMe
 	if queryCost != "" {
 		memo.QueryCost = queryCost
 	}
+	if usingFilesort {
+		memo.UsingFilesort = true
+	}
+	if usingTemporaryTable {
+		memo.UsingTemporaryTable = true
+	}
 
 	if tableName != "" || accessType != "" || rowsExaminedPerScan != 0 || rowsProducedPerJoin != 0 || filtered != "" || readCost != "" || evalCost != "" {
 		dbPerformanceEvents = append(dbPerformanceEvents, utils.QueryPlanMetrics{
@@ -259,6 +386,8 @@ func extractMetrics(js *simplejson.Json, dbPerformanceEvents []utils.QueryPlanMe
 			DataReadPerJoin:     dataReadPerJoin,
 			UsingIndex:          fmt.Sprintf("%t", usingIndex),
 			KeyLength:           keyLength,
+			UsingFilesort:       fmt.Sprintf("%t", memo.UsingFilesort),
+			UsingTemporaryTable: fmt.Sprintf("%t", memo.UsingTemporaryTable),
 		})
 		*stepID++
 	}
@@ -270,6 +399,20 @@ func extractMetrics(js *simplejson.Json, dbPerformanceEvents []utils.QueryPlanMe
 	return dbPerformanceEvents
 }
 
+/*
+planFigure returns a figure of the plan as a string. Depending on the version, the server prints the figures, such as
+the costs and the filtered percentage, either as strings or as numbers. Missing figures are returned empty.
+*/
+func planFigure(js *simplejson.Json) string {
+	if figure, err := js.String(); err == nil {
+		return figure
+	}
+	if figure, err := js.Float64(); err == nil {
+		return strconv.FormatFloat(figure, 'f', -1, 64)
+	}
+	return ""
+}
+
 // processMap processes a map within the JSON object.
 func processMap(jsMap map[string]interface{}, dbPerformanceEvents []utils.QueryPlanMetrics, eventID uint64, threadID uint64, memo utils.Memo, stepID *int) []utils.QueryPlanMetrics {
 	for _, value := range jsMap {
@@ -339,18 +482,40 @@ func SetExecutionPlanMetrics(i *integration.Integration, args arguments.Argument
 	return nil
 }
 
-// isSupportedStatement checks if the given query is a supported statement.
-func isSupportedStatement(query string) bool {
+// isSupportedStatement checks if the given query is a supported statement of one of the allowed types.
+func isSupportedStatement(query string, allowedTypes []string) bool {
+	statementType := getStatementType(query)
+	if statementType == "" || !slices.Contains(allowedTypes, statementType) {
+		return false
+	}
+	// INSERT and REPLACE are only explained when they insert the result of a SELECT
+	if statementType == "INSERT" || statementType == "REPLACE" {
+		source := insertSourcePattern.FindStringSubmatch(query)
+		return source != nil && (strings.EqualFold(source[1], "SELECT") || strings.EqualFold(source[1], "WITH"))
+	}
+	return true
+}
+
+// getStatementType returns the type of the statement, i.e. its first keyword, if it is one of the explainable ones.
+func getStatementType(query string) string {
 	upperCaseQuery := strings.ToUpper(strings.TrimSpace(query))
 	/*
 		SupportedStatements defines the SQL statements for which this integration fetches query execution plans.
 		Restricting the supported statements improves compatibility and reduces the complexity of plan analysis.
 	*/
-	supportedStatements := []string{"SELECT", "WITH"}
-	for _, stmt := range supportedStatements {
+	for _, stmt := range constants.SupportedExplainStatementTypes {
 		if strings.HasPrefix(upperCaseQuery, stmt) {
-			return true
+			return stmt
 		}
 	}
-	return false
+	return ""
+}
+
+/*
+hasSideEffects checks if explaining the query could have effects other than returning its plan, even within
+a read-only transaction: several statements, writing to files or variables, locking reads, or calling functions
+which lock, wait or read files. Literals are removed first, so their content is not taken for SQL.
+*/
+func hasSideEffects(query string) bool {
+	return sideEffectsPattern.MatchString(strings.TrimRight(strings.TrimSpace(utils.ObfuscateSQL(query)), ";"))
 }
@@ -2,13 +2,19 @@ package performancemetricscollectors
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
 	"testing"
+	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	arguments "github.com/newrelic/nri-mysql/src/args"
 
 	"github.com/bitly/go-simplejson"
+	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/constants"
 	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -98,6 +104,8 @@ func TestExtractMetricsFromJSONString(t *testing.T) {
 				Key:                 "key1",
 				UsedKeyParts:        "key1_part1,key1_part2",
 				Ref:                 "const",
+				UsingFilesort:       "false",
+				UsingTemporaryTable: "false",
 			},
 		}
 
@@ -120,6 +128,42 @@ func TestExtractMetricsFromJSONString(t *testing.T) {
 	})
 }
 
+func TestExtractMetricsFromJSONString_SortsAndTemporaryTables(t *testing.T) {
+	jsonString := `{"query_block": {"cost_info": {"query_cost": "5.10"}, "ordering_operation": {"using_filesort": true,
+		"grouping_operation": {"using_temporary_table": true, "nested_loop": [
+			{"table": {"table_name": "orders", "access_type": "ALL"}},
+			{"table": {"table_name": "customers", "access_type": "eq_ref"}}]}}}}`
+
+	metrics, err := extractMetricsFromJSONString(jsonString, 1, 1)
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 2)
+	for _, step := range metrics {
+		assert.Equal(t, "true", step.UsingFilesort)
+		assert.Equal(t, "true", step.UsingTemporaryTable)
+		assert.Equal(t, "5.10", step.QueryCost)
+	}
+
+	metrics, err = extractMetricsFromJSONString(`{"query_block": {"table": {"table_name": "orders", "access_type": "ALL"}}}`, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "false", metrics[0].UsingFilesort)
+	assert.Equal(t, "false", metrics[0].UsingTemporaryTable)
+}
+
+func TestExtractMetricsFromJSONString_NumericFigures(t *testing.T) {
+	// Older servers print some of the figures of the plan as numbers
+	jsonString := `{"query_block": {"select_id": 1, "table": {"update": true, "table_name": "orders", "access_type": "range",
+		"key": "PRIMARY", "key_length": 4, "rows_examined_per_scan": 10, "filtered": 100}}}`
+
+	metrics, err := extractMetricsFromJSONString(jsonString, 1, 1)
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, "orders", metrics[0].TableName)
+	assert.Equal(t, "100", metrics[0].Filtered)
+	assert.Equal(t, "4", metrics[0].KeyLength)
+	assert.Equal(t, int64(10), metrics[0].RowsExaminedPerScan)
+	assert.Equal(t, "", metrics[0].QueryCost)
+}
+
 func getTestCases() []struct {
 	name                 string
 	jsonString           string
@@ -352,24 +396,121 @@ func TestSetExecutionPlanMetrics(t *testing.T) {
 
 func TestIsSupportedStatement(t *testing.T) {
 	t.Run("Supported Statement", func(t *testing.T) {
-		assert.True(t, isSupportedStatement("SELECT * FROM test"))
-		assert.True(t, isSupportedStatement("WITH cte AS (SELECT * FROM test) SELECT * FROM cte"))
-		assert.True(t, isSupportedStatement("select * from users"))
-		assert.True(t, isSupportedStatement("  SELECT * FROM users"))
-		assert.True(t, isSupportedStatement("with cte as (select * from users) select * from cte"))
-		assert.True(t, isSupportedStatement("Select * from test"))
-		assert.True(t, isSupportedStatement("With cte as (Select * from test) Select * from cte"))
+		assert.True(t, isSupportedStatement("SELECT * FROM test", constants.SupportedExplainStatementTypes))
+		assert.True(t, isSupportedStatement("WITH cte AS (SELECT * FROM test) SELECT * FROM cte", constants.SupportedExplainStatementTypes))
+		assert.True(t, isSupportedStatement("select * from users", constants.SupportedExplainStatementTypes))
+		assert.True(t, isSupportedStatement("  SELECT * FROM users", constants.SupportedExplainStatementTypes))
+		assert.True(t, isSupportedStatement("with cte as (select * from users) select * from cte", constants.SupportedExplainStatementTypes))
+		assert.True(t, isSupportedStatement("Select * from test", constants.SupportedExplainStatementTypes))
+		assert.True(t, isSupportedStatement("With cte as (Select * from test) Select * from cte", constants.SupportedExplainStatementTypes))
 	})
 
 	t.Run("Unsupported Statement", func(t *testing.T) {
-		assert.False(t, isSupportedStatement("DROP TABLE test"))
-		assert.False(t, isSupportedStatement("ALTER TABLE test ADD COLUMN value INT"))
-		assert.False(t, isSupportedStatement("INSERT INTO test VALUES (1)"))
-		assert.False(t, isSupportedStatement("UPDATE test SET value = 1"))
-		assert.False(t, isSupportedStatement("DELETE FROM test"))
-		assert.False(t, isSupportedStatement("CREATE TABLE users (id INT, name VARCHAR(255))"))
-		assert.False(t, isSupportedStatement(""))
-		assert.False(t, isSupportedStatement("   "))
+		queryTypes := []string{"SELECT", "WITH"}
+		assert.False(t, isSupportedStatement("DROP TABLE test", queryTypes))
+		assert.False(t, isSupportedStatement("ALTER TABLE test ADD COLUMN value INT", queryTypes))
+		assert.False(t, isSupportedStatement("INSERT INTO test VALUES (1)", queryTypes))
+		assert.False(t, isSupportedStatement("UPDATE test SET value = 1", queryTypes))
+		assert.False(t, isSupportedStatement("DELETE FROM test", queryTypes))
+		assert.False(t, isSupportedStatement("CREATE TABLE users (id INT, name VARCHAR(255))", queryTypes))
+		assert.False(t, isSupportedStatement("", queryTypes))
+		assert.False(t, isSupportedStatement("   ", queryTypes))
+	})
+
+	t.Run("Supported DML Statement", func(t *testing.T) {
+		assert.True(t, isSupportedStatement("UPDATE test SET value = 1 WHERE id = 2", constants.SupportedExplainStatementTypes))
+		assert.True(t, isSupportedStatement("delete t1 FROM t1 JOIN t2 ON t1.id = t2.id", constants.SupportedExplainStatementTypes))
+		assert.True(t, isSupportedStatement("INSERT INTO archive (id, value) SELECT id, value FROM test", constants.SupportedExplainStatementTypes))
+		assert.True(t, isSupportedStatement("REPLACE INTO archive WITH recent AS (SELECT * FROM test) SELECT * FROM recent", constants.SupportedExplainStatementTypes))
+		assert.True(t, isSupportedStatement("INSERT INTO archive (value) SELECT value FROM test ON DUPLICATE KEY UPDATE value = VALUES(value)", constants.SupportedExplainStatementTypes))
+	})
+
+	t.Run("Unsupported DML Statement", func(t *testing.T) {
+		assert.False(t, isSupportedStatement("INSERT INTO test VALUES (1)", constants.SupportedExplainStatementTypes))
+		assert.False(t, isSupportedStatement("INSERT INTO test (value) VALUE (1)", constants.SupportedExplainStatementTypes))
+		assert.False(t, isSupportedStatement("REPLACE INTO test SET value = 1", constants.SupportedExplainStatementTypes))
+		assert.False(t, isSupportedStatement("UPDATE test SET value = 1", []string{"SELECT", "DELETE"}))
+	})
+}
+
+func TestHasSideEffects(t *testing.T) {
+	assert.False(t, hasSideEffects("UPDATE test SET value = 'a;b' WHERE note = 'sleep(1)'"))
+	assert.False(t, hasSideEffects("SELECT * FROM test;"))
+	assert.True(t, hasSideEffects("SELECT 1; DELETE FROM test"))
+	assert.True(t, hasSideEffects("SELECT * FROM test INTO OUTFILE '/tmp/test'"))
+	assert.True(t, hasSideEffects("SELECT value INTO @value FROM test"))
+	assert.True(t, hasSideEffects("SELECT @total := SUM(value) FROM test"))
+	assert.True(t, hasSideEffects("SELECT * FROM test WHERE SLEEP (10) = 0"))
+	assert.True(t, hasSideEffects("SELECT GET_LOCK('test', 10)"))
+	assert.True(t, hasSideEffects("SELECT * FROM test WHERE id = 1 FOR UPDATE"))
+	assert.True(t, hasSideEffects("SELECT * FROM test FOR UPDATE SKIP LOCKED"))
+	assert.True(t, hasSideEffects("SELECT * FROM test t JOIN other o ON t.id = o.id FOR SHARE OF t NOWAIT"))
+	assert.True(t, hasSideEffects("SELECT * FROM test WHERE id = 1 LOCK IN SHARE MODE"))
+	assert.False(t, hasSideEffects("SELECT * FROM test WHERE note = 'for update'"))
+}
+
+// readOnlyDataSource runs the read-only queries on a transaction of the mock database.
+type readOnlyDataSource struct {
+	*DataSource
+}
+
+func (d readOnlyDataSource) QueryRowReadOnlyContext(ctx context.Context, query string, dest ...interface{}) error {
+	return d.QueryRowReadOnlyTimeLimitContext(ctx, 0, query, dest...)
+}
+
+func (d readOnlyDataSource) QueryRowReadOnlyTimeLimitContext(ctx context.Context, timeLimit time.Duration, query string, dest ...interface{}) error {
+	tx, err := d.DB.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if timeLimit > 0 {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf("SET SESSION max_execution_time = %d", timeLimit.Milliseconds())); err != nil {
+			return err
+		}
+	}
+	return tx.QueryRowxContext(ctx, query).Scan(dest...)
+}
+
+func TestExecuteExplainQuery(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Read-only transaction", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("EXPLAIN FORMAT=JSON UPDATE test SET value = 1 WHERE id = 2")).
+			WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(`{"query_block":{}}`))
+		mock.ExpectRollback()
+
+		plan, err := executeExplainQuery(ctx, readOnlyDataSource{&DataSource{DB: sqlx.NewDb(db, "sqlmock")}}, "UPDATE test SET value = 1 WHERE id = 2")
+		assert.NoError(t, err)
+		assert.Equal(t, `{"query_block":{}}`, plan)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DML without read-only transactions", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		_, err = executeExplainQuery(ctx, &DataSource{DB: sqlx.NewDb(db, "sqlmock")}, "DELETE FROM test WHERE id = 2")
+		assert.ErrorIs(t, err, utils.ErrReadOnlyNotSupported)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Query without read-only transactions", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectQuery(regexp.QuoteMeta("EXPLAIN FORMAT=JSON SELECT * FROM test")).
+			WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow(`{"query_block":{}}`))
+
+		plan, err := executeExplainQuery(ctx, &DataSource{DB: sqlx.NewDb(db, "sqlmock")}, "SELECT * FROM test")
+		assert.NoError(t, err)
+		assert.Equal(t, `{"query_block":{}}`, plan)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -396,7 +537,7 @@ func TestPopulateExecutionPlans(t *testing.T) {
 			return nil, assert.AnError
 		}
 
-		PopulateExecutionPlans(mockDB, queryGroups, mockIntegration.Integration, mockArgs)
+		PopulateExecutionPlans(mockDB, queryGroups, mockIntegration.Integration, mockArgs, mysql8Capabilities)
 
 		mockDB.AssertExpectations(t)
 		mockIntegration.AssertExpectations(t)
@@ -405,7 +546,7 @@ func TestPopulateExecutionPlans(t *testing.T) {
 	t.Run("No Metrics Collected", func(t *testing.T) {
 		queryGroups := map[string][]utils.IndividualQueryMetrics{}
 
-		PopulateExecutionPlans(mockDB, queryGroups, mockIntegration.Integration, mockArgs)
+		PopulateExecutionPlans(mockDB, queryGroups, mockIntegration.Integration, mockArgs, mysql8Capabilities)
 
 		mockDB.AssertExpectations(t)
 		mockIntegration.AssertExpectations(t)
@@ -0,0 +1,153 @@
+package performancemetricscollectors
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+)
+
+const (
+	planStoreName    = "plans"
+	planHashLength   = 16
+	noPlanStep       = "-"
+	planStepsDivider = "; "
+)
+
+// planFingerprint identifies the plan of a digest, and keeps what is needed to describe how it changed.
+type planFingerprint struct {
+	Hash      string
+	QueryCost string
+	Steps     []string
+	// Candidate is a different plan found by the last execution, which is only taken as a change once found again
+	Candidate *planFingerprint `json:",omitempty"`
+}
+
+/*
+newPlanFingerprint computes the fingerprint of the plan of a query from its steps. The hash only depends on the join order,
+and on the access type and key of each table, so it doesn't change with the estimated costs and rows.
+*/
+func newPlanFingerprint(steps []utils.QueryPlanMetrics) planFingerprint {
+	fingerprint := planFingerprint{Steps: make([]string, 0, len(steps))}
+	for _, step := range steps {
+		fingerprint.Steps = append(fingerprint.Steps, planStepSignature(step))
+	}
+	if len(steps) > 0 {
+		fingerprint.QueryCost = steps[0].QueryCost
+	}
+	hash := sha256.Sum256([]byte(strings.Join(fingerprint.Steps, "\n")))
+	fingerprint.Hash = fmt.Sprintf("%x", hash)[:planHashLength]
+	return fingerprint
+}
+
+// planStepSignature describes a step of the plan as table:access_type:key, e.g. orders:ref:idx_customer.
+func planStepSignature(step utils.QueryPlanMetrics) string {
+	signature := step.TableName + ":" + step.AccessType
+	if step.Key != "" {
+		signature += ":" + step.Key
+	}
+	return signature
+}
+
+// planChangeDetector compares the plan of each digest with the last one known, kept in a store between executions.
+type planChangeDetector struct {
+	store persist.Storer
+	seen  map[string]bool
+}
+
+func newPlanChangeDetector(store persist.Storer) *planChangeDetector {
+	return &planChangeDetector{store: store, seen: map[string]bool{}}
+}
+
+/*
+detect returns the change of the plan of the digest in the database, if its last known plan is different, and keeps
+the new plan as the last known one. Nothing is returned the first time a digest is seen. Only the plans explained with
+the same strategy are compared, and the plans of dummy values aren't, as they don't reflect the queries which ran.
+Only the first plan of a digest in an execution is compared, and since the plans of its queries may also differ with
+their literals, a different plan is only taken as a change when the next execution finds it again.
+*/
+func (d *planChangeDetector) detect(queryID, database string, steps []utils.QueryPlanMetrics) *utils.QueryPlanChangeMetrics {
+	if queryID == "" || len(steps) == 0 || steps[0].PlanStrategy == planStrategyDummyValues {
+		return nil
+	}
+	key := fmt.Sprintf("%s:%s:%s", database, queryID, steps[0].PlanStrategy)
+	if d.seen[key] {
+		return nil
+	}
+	d.seen[key] = true
+	fingerprint := newPlanFingerprint(steps)
+
+	var previous planFingerprint
+	if _, err := d.store.Get(key, &previous); err != nil || previous.Hash == fingerprint.Hash {
+		d.store.Set(key, fingerprint)
+		return nil
+	}
+	if previous.Candidate == nil || previous.Candidate.Hash != fingerprint.Hash {
+		previous.Candidate = &fingerprint
+		d.store.Set(key, previous)
+		return nil
+	}
+	d.store.Set(key, fingerprint)
+
+	changedSteps := diffPlanSteps(previous.Steps, fingerprint.Steps)
+	return &utils.QueryPlanChangeMetrics{
+		QueryID:             queryID,
+		DatabaseName:        database,
+		OldPlanHash:         previous.Hash,
+		NewPlanHash:         fingerprint.Hash,
+		OldQueryCost:        parsePlanNumber(previous.QueryCost),
+		NewQueryCost:        parsePlanNumber(fingerprint.QueryCost),
+		ChangedStepsCount:   len(changedSteps),
+		ChangedSteps:        strings.Join(changedSteps, planStepsDivider),
+		CollectionTimestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func (d *planChangeDetector) save() {
+	if err := d.store.Save(); err != nil {
+		log.Warn("Error saving the query plans for the next execution: %v", err)
+	}
+}
+
+// diffPlanSteps describes the steps which differ between two plans, e.g. "1: orders:ALL -> orders:ref:idx_customer".
+func diffPlanSteps(oldSteps, newSteps []string) []string {
+	var changes []string
+	for i := 0; i < max(len(oldSteps), len(newSteps)); i++ {
+		oldStep, newStep := noPlanStep, noPlanStep
+		if i < len(oldSteps) {
+			oldStep = oldSteps[i]
+		}
+		if i < len(newSteps) {
+			newStep = newSteps[i]
+		}
+		if oldStep != newStep {
+			changes = append(changes, fmt.Sprintf("%d: %s -> %s", i, oldStep, newStep))
+		}
+	}
+	return changes
+}
+
+func parsePlanNumber(number string) *float64 {
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// setPlanChangeMetrics sets the plan change metrics into the integration entity.
+func setPlanChangeMetrics(i *integration.Integration, args arguments.ArgumentList, metrics []utils.QueryPlanChangeMetrics) error {
+	metricList := make([]interface{}, 0, len(metrics))
+	for _, metricData := range metrics {
+		metricList = append(metricList, metricData)
+	}
+
+	return utils.IngestMetric(metricList, "MysqlQueryPlanChangeSample", i, args)
+}
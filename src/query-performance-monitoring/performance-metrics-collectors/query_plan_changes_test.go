@@ -0,0 +1,109 @@
+package performancemetricscollectors
+
+import (
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func planSteps(queryCost string, steps ...[3]string) []utils.QueryPlanMetrics {
+	plan := make([]utils.QueryPlanMetrics, 0, len(steps))
+	for _, step := range steps {
+		plan = append(plan, utils.QueryPlanMetrics{QueryCost: queryCost, TableName: step[0], AccessType: step[1], Key: step[2], ReadCost: "1.00"})
+	}
+	return plan
+}
+
+func TestNewPlanFingerprint(t *testing.T) {
+	plan := planSteps("12.50", [3]string{"orders", "ALL", ""}, [3]string{"customers", "eq_ref", "PRIMARY"})
+	fingerprint := newPlanFingerprint(plan)
+	assert.Len(t, fingerprint.Hash, planHashLength)
+	assert.Equal(t, "12.50", fingerprint.QueryCost)
+	assert.Equal(t, []string{"orders:ALL", "customers:eq_ref:PRIMARY"}, fingerprint.Steps)
+
+	// Estimates don't change the hash
+	plan[0].ReadCost = "99.00"
+	plan[0].RowsExaminedPerScan = 1000
+	assert.Equal(t, fingerprint.Hash, newPlanFingerprint(plan).Hash)
+
+	// The join order does
+	reordered := planSteps("12.50", [3]string{"customers", "eq_ref", "PRIMARY"}, [3]string{"orders", "ALL", ""})
+	assert.NotEqual(t, fingerprint.Hash, newPlanFingerprint(reordered).Hash)
+}
+
+func TestPlanChangeDetector(t *testing.T) {
+	store := persist.NewInMemoryStore()
+	oldPlan := planSteps("120.5", [3]string{"orders", "ALL", ""}, [3]string{"customers", "eq_ref", "PRIMARY"})
+	newPlan := planSteps("3.2", [3]string{"orders", "ref", "idx_customer"}, [3]string{"customers", "eq_ref", "PRIMARY"}, [3]string{"items", "ref", "idx_order"})
+
+	// The first time a digest is seen there is nothing to compare with
+	assert.Nil(t, newPlanChangeDetector(store).detect("digest1", "shop", oldPlan))
+	assert.Nil(t, newPlanChangeDetector(store).detect("digest1", "shop", oldPlan))
+
+	// A different plan is only a change once the next execution finds it again
+	assert.Nil(t, newPlanChangeDetector(store).detect("digest1", "shop", newPlan))
+	detector := newPlanChangeDetector(store)
+	change := detector.detect("digest1", "shop", newPlan)
+	require.NotNil(t, change)
+	assert.Equal(t, "digest1", change.QueryID)
+	assert.Equal(t, "shop", change.DatabaseName)
+	assert.Equal(t, newPlanFingerprint(oldPlan).Hash, change.OldPlanHash)
+	assert.Equal(t, newPlanFingerprint(newPlan).Hash, change.NewPlanHash)
+	assert.Equal(t, 120.5, *change.OldQueryCost)
+	assert.Equal(t, 3.2, *change.NewQueryCost)
+	assert.Equal(t, 2, change.ChangedStepsCount)
+	assert.Equal(t, "0: orders:ALL -> orders:ref:idx_customer; 2: - -> items:ref:idx_order", change.ChangedSteps)
+
+	// Only the first plan of a digest in an execution is compared
+	assert.Nil(t, detector.detect("digest1", "shop", oldPlan))
+	// Digests are told apart by database
+	assert.Nil(t, detector.detect("digest1", "other", newPlan))
+	assert.Nil(t, detector.detect("", "shop", newPlan))
+}
+
+func withPlanStrategy(plan []utils.QueryPlanMetrics, strategy string) []utils.QueryPlanMetrics {
+	for i := range plan {
+		plan[i].PlanStrategy = strategy
+	}
+	return plan
+}
+
+func TestPlanChangeDetectorStrategies(t *testing.T) {
+	store := persist.NewInMemoryStore()
+	samplePlan := withPlanStrategy(planSteps("3.2", [3]string{"orders", "ref", "idx_customer"}), planStrategyHistorySample)
+	preparedPlan := withPlanStrategy(planSteps("120.5", [3]string{"orders", "ALL", ""}), planStrategyPreparedStatement)
+	dummyPlan := withPlanStrategy(planSteps("120.5", [3]string{"orders", "ALL", ""}), planStrategyDummyValues)
+	rangePlan := withPlanStrategy(planSteps("50.0", [3]string{"orders", "range", "idx_customer"}), planStrategyHistorySample)
+
+	// The plans explained with different strategies, or with dummy values, are not compared
+	for _, plans := range [][]utils.QueryPlanMetrics{samplePlan, dummyPlan, preparedPlan, dummyPlan, samplePlan, preparedPlan} {
+		assert.Nil(t, newPlanChangeDetector(store).detect("digest1", "shop", plans))
+	}
+
+	// Nor are the plans which only differ with the literals of the sample of an execution
+	for _, plans := range [][]utils.QueryPlanMetrics{rangePlan, samplePlan, rangePlan, samplePlan} {
+		assert.Nil(t, newPlanChangeDetector(store).detect("digest1", "shop", plans))
+	}
+}
+
+func TestSetPlanChangeMetrics(t *testing.T) {
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+
+	cost := 3.2
+	changes := []utils.QueryPlanChangeMetrics{{QueryID: "digest1", OldPlanHash: "a", NewPlanHash: "b", NewQueryCost: &cost, ChangedStepsCount: 1, ChangedSteps: "0: orders:ALL -> orders:ref:idx_customer"}}
+	require.NoError(t, setPlanChangeMetrics(i, arguments.ArgumentList{}, changes))
+
+	ms := e.Metrics[0]
+	assert.Equal(t, "MysqlQueryPlanChangeSample", ms.Metrics["event_type"])
+	assert.Equal(t, "b", ms.Metrics["new_plan_hash"])
+	assert.Equal(t, 3.2, ms.Metrics["new_query_cost"])
+	assert.Equal(t, float64(1), ms.Metrics["changed_steps_count"])
+	assert.NotContains(t, ms.Metrics, "old_query_cost")
+}
@@ -0,0 +1,275 @@
+package performancemetricscollectors
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+)
+
+// Strategies producing the text which is explained for a query, reported as the plan_strategy of its plan.
+const (
+	// planStrategyQueryText explains the sample text of the query, which has no placeholders.
+	planStrategyQueryText = "query_text"
+	// planStrategyHistorySample explains a recent text of the same digest with literals, from the statements history.
+	planStrategyHistorySample = "history_sample"
+	// planStrategyPreparedStatement explains the prepared statement with the user variables it was last executed with.
+	planStrategyPreparedStatement = "prepared_statement"
+	// planStrategyDummyValues explains the query with values matching the types of the columns its placeholders are compared with.
+	planStrategyDummyValues = "dummy_values"
+
+	// placeholderSampleLimit is the number of recent statements searched for the values of the placeholders.
+	placeholderSampleLimit = 10
+	// unknownTypeValue is given to the placeholders which are not compared with a known column, such as the ones of LIMIT.
+	unknownTypeValue = "1"
+)
+
+var (
+	// placeholderColumnPattern matches the column a placeholder is compared with, at the end of the text preceding it, e.g. `o.customer_id = `.
+	placeholderColumnPattern = regexp.MustCompile("(?i)([\\w$]+)`?\\s*(?:<=>|<>|!=|<=|>=|=|<|>|\\bNOT\\s+LIKE|\\bLIKE|\\bNOT\\s+IN\\s*\\(|\\bIN\\s*\\(|\\bNOT\\s+BETWEEN|\\bBETWEEN)\\s*$")
+	// placeholderListPattern matches the text between two placeholders compared with the same column, as in `IN (?, ?)` or `BETWEEN ? AND ?`.
+	placeholderListPattern = regexp.MustCompile(`(?i)^\s*(?:,|AND)\s*$`)
+	// referencedTablePattern matches a table read or written by a statement, e.g. `FROM shop.orders`.
+	referencedTablePattern = regexp.MustCompile("(?i)\\b(?:FROM|JOIN|UPDATE|INTO)\\s+(?:`?[\\w$]+`?\\.)?`?([\\w$]+)")
+	// enumValuePattern matches a value of the type of an ENUM or SET column, e.g. `enum('new','paid')`.
+	enumValuePattern = regexp.MustCompile(`'((?:[^'\\]|\\.|'')*)'`)
+	// executeUsingPattern matches the EXECUTE statement of a prepared statement with the user variables it is executed with.
+	executeUsingPattern = regexp.MustCompile("(?is)^\\s*EXECUTE\\s+(`[^`]+`|[\\w$]+)\\s+USING\\s+(.+?)\\s*;?\\s*$")
+	// userVariablePattern matches a user variable, e.g. @id or @`order id`.
+	userVariablePattern = regexp.MustCompile("^@(?:`([^`]+)`|'([^']+)'|\"([^\"]+)\"|([\\w$.]+))$")
+	numberPattern       = regexp.MustCompile(`^[-+]?\d+(?:\.\d+)?(?:[eE][-+]?\d+)?$`)
+)
+
+// placeholderStrategy gives values to the placeholders of a query, returning an empty text when it finds none.
+type placeholderStrategy struct {
+	name    string
+	resolve func(db utils.DataSource, queryID string, queryText string, parts []string) (string, error)
+}
+
+var placeholderStrategies = []placeholderStrategy{
+	{planStrategyHistorySample, findHistorySample},
+	{planStrategyPreparedStatement, fillPreparedStatementValues},
+	{planStrategyDummyValues, fillDummyValues},
+}
+
+/*
+resolvePlaceholders gives values to the placeholders of a query so that it can be explained, and returns the resolved
+text along with the strategy which produced it. The strategies are tried from the values the query actually ran with
+to the dummy ones, and no strategy is returned when none of them could resolve the placeholders.
+*/
+func resolvePlaceholders(db utils.DataSource, queryID string, queryText string) (string, string) {
+	parts := utils.SplitPlaceholders(queryText)
+	for _, strategy := range placeholderStrategies {
+		resolvedText, err := strategy.resolve(db, queryID, queryText, parts)
+		if err != nil {
+			log.Debug("Placeholders of query ID %s not resolved with the %s strategy: %v", queryID, strategy.name, err)
+			continue
+		}
+		if resolvedText != "" {
+			return resolvedText, strategy.name
+		}
+	}
+	return "", ""
+}
+
+// findHistorySample returns the most recent text of the digest in the statements history which has literals instead of placeholders.
+func findHistorySample(db utils.DataSource, queryID string, _ string, _ []string) (string, error) {
+	samples, err := utils.CollectMetrics[utils.QueryHistorySample](db, utils.QueryHistorySamplesQuery, queryID, placeholderSampleLimit)
+	if err != nil {
+		return "", err
+	}
+	for _, sample := range samples {
+		if sample.SQLText != nil && !utils.HasPlaceholders(*sample.SQLText) {
+			return strings.TrimSpace(*sample.SQLText), nil
+		}
+	}
+	return "", nil
+}
+
+/*
+fillPreparedStatementValues gives the placeholders the values of the user variables of the last EXECUTE of the statement,
+when it was prepared with PREPARE. The variables hold their current value, which is the one of the last execution
+unless the thread has assigned them since.
+*/
+func fillPreparedStatementValues(db utils.DataSource, _ string, queryText string, parts []string) (string, error) {
+	executions, err := utils.CollectMetrics[utils.PreparedStatementExecution](db, utils.PreparedStatementExecutionsQuery, queryText, placeholderSampleLimit)
+	if err != nil {
+		return "", err
+	}
+	for _, execution := range executions {
+		if execution.ExecuteText == nil {
+			continue
+		}
+		variables := parseExecuteVariables(*execution.ExecuteText, execution.StatementName)
+		if len(variables) != len(parts)-1 {
+			continue
+		}
+		userVariables, err := utils.CollectMetrics[utils.UserVariable](db, utils.UserVariablesQuery, execution.ThreadID)
+		if err != nil {
+			return "", err
+		}
+		if values, ok := userVariableValues(variables, userVariables); ok {
+			return fillPlaceholders(parts, values), nil
+		}
+	}
+	return "", nil
+}
+
+// parseExecuteVariables returns the names of the user variables an EXECUTE statement of the prepared statement is executed with.
+func parseExecuteVariables(executeText string, statementName string) []string {
+	match := executeUsingPattern.FindStringSubmatch(executeText)
+	if match == nil || !strings.EqualFold(strings.Trim(match[1], "`"), statementName) {
+		return nil
+	}
+	var variables []string
+	for _, variable := range strings.Split(match[2], ",") {
+		name := userVariablePattern.FindStringSubmatch(strings.TrimSpace(variable))
+		if name == nil {
+			return nil
+		}
+		variables = append(variables, name[1]+name[2]+name[3]+name[4])
+	}
+	return variables
+}
+
+// userVariableValues returns the values of the variables as SQL literals, unless one of them is not defined.
+func userVariableValues(variables []string, userVariables []utils.UserVariable) ([]string, bool) {
+	// User variable names are not case-sensitive
+	valuesByName := make(map[string]*string, len(userVariables))
+	for _, userVariable := range userVariables {
+		valuesByName[strings.ToLower(userVariable.Name)] = userVariable.Value
+	}
+
+	values := make([]string, 0, len(variables))
+	for _, variable := range variables {
+		value, ok := valuesByName[strings.ToLower(variable)]
+		switch {
+		case !ok:
+			return nil, false
+		case value == nil:
+			values = append(values, "NULL")
+		case numberPattern.MatchString(*value):
+			values = append(values, *value)
+		default:
+			values = append(values, quoteSQLString(*value))
+		}
+	}
+	return values, true
+}
+
+/*
+fillDummyValues gives each placeholder a value of the type of the column it is compared with, read from the columns of
+the default database. When a column name is found in several tables, the tables the query references are preferred.
+The plan may differ from the ones of the actual values, as the values don't have their selectivity.
+*/
+func fillDummyValues(db utils.DataSource, _ string, queryText string, parts []string) (string, error) {
+	columns := placeholderColumns(parts)
+
+	var columnNames []string
+	for _, column := range columns {
+		if column != "" {
+			columnNames = append(columnNames, column)
+		}
+	}
+
+	columnTypes := map[string]utils.ColumnType{}
+	if len(columnNames) > 0 {
+		query, args, err := sqlx.In(utils.ColumnTypesQuery, columnNames)
+		if err != nil {
+			return "", err
+		}
+		rows, err := utils.CollectMetrics[utils.ColumnType](db, query, args...)
+		if err != nil {
+			return "", err
+		}
+		columnTypes = columnTypesByName(rows, referencedTables(queryText))
+	}
+
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = unknownTypeValue
+		if columnType, ok := columnTypes[strings.ToLower(column)]; ok {
+			values[i] = dummyValue(columnType)
+		}
+	}
+	return fillPlaceholders(parts, values), nil
+}
+
+// placeholderColumns returns the column each placeholder is compared with, or an empty name when it is not compared with a column.
+func placeholderColumns(parts []string) []string {
+	columns := make([]string, len(parts)-1)
+	for i := range columns {
+		if i > 0 && placeholderListPattern.MatchString(parts[i]) {
+			columns[i] = columns[i-1]
+			continue
+		}
+		if match := placeholderColumnPattern.FindStringSubmatch(parts[i]); match != nil {
+			columns[i] = match[1]
+		}
+	}
+	return columns
+}
+
+// referencedTables returns the lower-cased names of the tables read or written by a statement.
+func referencedTables(queryText string) map[string]bool {
+	tables := map[string]bool{}
+	for _, match := range referencedTablePattern.FindAllStringSubmatch(queryText, -1) {
+		tables[strings.ToLower(match[1])] = true
+	}
+	return tables
+}
+
+// columnTypesByName indexes the column types by lower-cased column name, preferring the columns of the given tables.
+func columnTypesByName(columnTypes []utils.ColumnType, tables map[string]bool) map[string]utils.ColumnType {
+	byName := make(map[string]utils.ColumnType, len(columnTypes))
+	for _, columnType := range columnTypes {
+		name := strings.ToLower(columnType.ColumnName)
+		current, ok := byName[name]
+		if !ok || (!tables[strings.ToLower(current.TableName)] && tables[strings.ToLower(columnType.TableName)]) {
+			byName[name] = columnType
+		}
+	}
+	return byName
+}
+
+// dummyValue returns a SQL literal of the type of the column.
+func dummyValue(columnType utils.ColumnType) string {
+	switch strings.ToLower(columnType.DataType) {
+	case "tinyint", "smallint", "mediumint", "int", "integer", "bigint", "decimal", "numeric", "float", "double", "real", "bit", "year":
+		return "1"
+	case "date":
+		return "'2000-01-01'"
+	case "datetime", "timestamp":
+		return "'2000-01-01 00:00:00'"
+	case "time":
+		return "'00:00:00'"
+	case "json":
+		return "'{}'"
+	case "enum", "set":
+		// Only the values of the type are valid
+		if match := enumValuePattern.FindStringSubmatch(columnType.ColumnType); match != nil {
+			return "'" + match[1] + "'"
+		}
+		return "''"
+	default:
+		return "'a'"
+	}
+}
+
+// fillPlaceholders joins the parts of a statement split around its placeholders, with the values in place of the placeholders.
+func fillPlaceholders(parts []string, values []string) string {
+	var text strings.Builder
+	for i, part := range parts {
+		text.WriteString(part)
+		if i < len(values) {
+			text.WriteString(values[i])
+		}
+	}
+	return text.String()
+}
+
+func quoteSQLString(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `''`).Replace(value) + "'"
+}
@@ -0,0 +1,151 @@
+package performancemetricscollectors
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePlaceholders(t *testing.T) {
+	queryText := "SELECT * FROM orders WHERE customer_id = ? AND status = ?"
+
+	t.Run("History sample", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.events_statements_history_long")).
+			WithArgs("digest1", placeholderSampleLimit).
+			WillReturnRows(sqlmock.NewRows([]string{"sql_text"}).
+				AddRow(queryText).
+				AddRow("SELECT * FROM orders WHERE customer_id = 42 AND status = 'paid'"))
+
+		resolvedText, strategy := resolvePlaceholders(&DataSource{DB: sqlx.NewDb(db, "sqlmock")}, "digest1", queryText)
+		assert.Equal(t, planStrategyHistorySample, strategy)
+		assert.Equal(t, "SELECT * FROM orders WHERE customer_id = 42 AND status = 'paid'", resolvedText)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Prepared statement", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.events_statements_history_long")).
+			WillReturnRows(sqlmock.NewRows([]string{"sql_text"}))
+		mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.prepared_statements_instances")).
+			WithArgs(queryText, placeholderSampleLimit).
+			WillReturnRows(sqlmock.NewRows([]string{"thread_id", "statement_name", "execute_text"}).
+				AddRow(51, "orders_by_customer", "EXECUTE other_statement USING @a, @b").
+				AddRow(51, "orders_by_customer", "EXECUTE orders_by_customer USING @customer, @Status"))
+		mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.user_variables_by_thread")).
+			WithArgs(51).
+			WillReturnRows(sqlmock.NewRows([]string{"variable_name", "variable_value"}).
+				AddRow("customer", "42").
+				AddRow("status", "it's paid"))
+
+		resolvedText, strategy := resolvePlaceholders(&DataSource{DB: sqlx.NewDb(db, "sqlmock")}, "digest1", queryText)
+		assert.Equal(t, planStrategyPreparedStatement, strategy)
+		assert.Equal(t, "SELECT * FROM orders WHERE customer_id = 42 AND status = 'it''s paid'", resolvedText)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Dummy values", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.events_statements_history_long")).
+			WillReturnError(errQuery)
+		mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.prepared_statements_instances")).
+			WillReturnRows(sqlmock.NewRows([]string{"thread_id", "statement_name", "execute_text"}))
+		mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.COLUMNS")).
+			WithArgs("customer_id", "status").
+			WillReturnRows(sqlmock.NewRows([]string{"table_name", "column_name", "data_type", "column_type"}).
+				AddRow("customers", "status", "varchar", "varchar(20)").
+				AddRow("orders", "customer_id", "bigint", "bigint unsigned").
+				AddRow("orders", "status", "enum", "enum('new','paid')"))
+
+		resolvedText, strategy := resolvePlaceholders(&DataSource{DB: sqlx.NewDb(db, "sqlmock")}, "digest1", queryText)
+		assert.Equal(t, planStrategyDummyValues, strategy)
+		assert.Equal(t, "SELECT * FROM orders WHERE customer_id = 1 AND status = 'new'", resolvedText)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No values found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.events_statements_history_long")).WillReturnError(errQuery)
+		mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.prepared_statements_instances")).WillReturnError(errQuery)
+		mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.COLUMNS")).WillReturnError(errQuery)
+
+		resolvedText, strategy := resolvePlaceholders(&DataSource{DB: sqlx.NewDb(db, "sqlmock")}, "digest1", queryText)
+		assert.Empty(t, strategy)
+		assert.Empty(t, resolvedText)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPlaceholderColumns(t *testing.T) {
+	parts := utils.SplitPlaceholders("SELECT * FROM `orders` o WHERE o.`customer_id`=? AND created BETWEEN ? AND ? AND id NOT IN (?, ?) AND note LIKE ? LIMIT ?")
+	assert.Equal(t, []string{"customer_id", "created", "created", "id", "id", "note", ""}, placeholderColumns(parts))
+}
+
+func TestParseExecuteVariables(t *testing.T) {
+	assert.Equal(t, []string{"a", "order id"}, parseExecuteVariables("EXECUTE stmt USING @a, @`order id`;", "stmt"))
+	assert.Equal(t, []string{"a"}, parseExecuteVariables("execute `Stmt` using @a", "stmt"))
+	assert.Nil(t, parseExecuteVariables("EXECUTE other USING @a", "stmt"))
+	assert.Nil(t, parseExecuteVariables("EXECUTE stmt USING @a, 1", "stmt"))
+	assert.Nil(t, parseExecuteVariables("EXECUTE stmt", "stmt"))
+}
+
+func TestUserVariableValues(t *testing.T) {
+	userVariables := []utils.UserVariable{{Name: "id", Value: ptr("-7.5")}, {Name: "name", Value: ptr(`O'Brien\`)}, {Name: "empty"}}
+
+	values, ok := userVariableValues([]string{"ID", "name", "empty"}, userVariables)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"-7.5", `'O''Brien\\'`, "NULL"}, values)
+
+	_, ok = userVariableValues([]string{"id", "missing"}, userVariables)
+	assert.False(t, ok)
+}
+
+func TestDummyValue(t *testing.T) {
+	tests := []struct {
+		dataType   string
+		columnType string
+		expected   string
+	}{
+		{"int", "int", "1"},
+		{"DECIMAL", "decimal(10,2)", "1"},
+		{"date", "date", "'2000-01-01'"},
+		{"timestamp", "timestamp", "'2000-01-01 00:00:00'"},
+		{"time", "time", "'00:00:00'"},
+		{"json", "json", "'{}'"},
+		{"set", "set('it''s','b')", "'it''s'"},
+		{"varchar", "varchar(255)", "'a'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dataType, func(t *testing.T) {
+			assert.Equal(t, tt.expected, dummyValue(utils.ColumnType{DataType: tt.dataType, ColumnType: tt.columnType}))
+		})
+	}
+}
+
+func TestColumnTypesByName(t *testing.T) {
+	columnTypes := []utils.ColumnType{
+		{TableName: "customers", ColumnName: "Status", DataType: "varchar"},
+		{TableName: "orders", ColumnName: "status", DataType: "enum"},
+		{TableName: "audit", ColumnName: "status", DataType: "int"},
+	}
+
+	byName := columnTypesByName(columnTypes, referencedTables("SELECT * FROM shop.`orders` JOIN items ON items.order_id = orders.id"))
+	assert.Equal(t, "orders", byName["status"].TableName)
+
+	byName = columnTypesByName(columnTypes, referencedTables("SELECT 1"))
+	assert.Equal(t, "customers", byName["status"].TableName)
+}
@@ -0,0 +1,135 @@
+package performancemetricscollectors
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	infrautils "github.com/newrelic/nri-mysql/src/infrautils"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
+)
+
+const tableActivityStoreName = "table_activity"
+
+/*
+PopulateTableActivityMetrics reports the rows fetched, inserted, updated and deleted in the tables during the interval,
+and their lock waits, for the tables with the longest total latency.
+*/
+func PopulateTableActivityMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string) {
+	store, err := infrautils.NewStore(args, tableActivityStoreName)
+	if err != nil {
+		log.Warn("Table activity metrics are not reported: %v", err)
+		return
+	}
+	populateTableActivityMetrics(db, i, args, excludedDatabases, store)
+}
+
+func populateTableActivityMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string, store persist.Storer) {
+	// Prepare the SQL query with the provided parameters
+	query, inputArgs, err := sqlx.In(utils.TableActivityQuery, excludedDatabases)
+	if err != nil {
+		log.Error("Failed to prepare table activity query: %v", err)
+		return
+	}
+
+	tableCounters, err := utils.CollectMetrics[utils.TableActivityCounters](db, query, inputArgs...)
+	if err != nil {
+		log.Error("Error collecting table activity metrics: %v", err)
+		return
+	}
+
+	// The row operations and lock waits of each table, and the time spent on them, in picoseconds
+	current := make(map[string]counters, len(tableCounters))
+	for _, row := range tableCounters {
+		current[tableActivityKey(row)] = newCounters(row)
+	}
+	previous, intervalSec, ok := swapIntervalCounters(store, current)
+	if !ok {
+		return
+	}
+
+	queryCountThreshold := validator.GetValidQueryCountThreshold(args.QueryMonitoringCountThreshold)
+	metrics := tableActivityDeltas(tableCounters, current, previous, queryCountThreshold, intervalSec, time.Now().UTC().Format(time.RFC3339))
+	if len(metrics) == 0 {
+		return
+	}
+
+	if err = setTableActivityMetrics(i, args, metrics); err != nil {
+		log.Error("Error setting table activity metrics: %v", err)
+		return
+	}
+}
+
+// tableActivityKey identifies a table by its quoted database and table names, as both of them may contain dots.
+func tableActivityKey(row utils.TableActivityCounters) string {
+	return fmt.Sprintf("`%s`.`%s`", row.DatabaseName, row.TableName)
+}
+
+/*
+tableActivityDeltas returns the deltas of the tables with the longest total latency, which is the time spent on their
+row operations and waiting for their locks.
+*/
+func tableActivityDeltas(tableCounters []utils.TableActivityCounters, current, previous map[string]counters,
+	limit int, intervalSec int64, collectionTimestamp string) []utils.TableActivityMetrics {
+	metrics := make([]utils.TableActivityMetrics, 0, len(tableCounters))
+	for _, row := range tableCounters {
+		key := tableActivityKey(row)
+		previousCounter, found := previous[key]
+		delta := current[key].delta(previousCounter, found)
+		if delta.sum("fetch_count", "insert_count", "update_count", "delete_count", "lock_wait_count") == 0 {
+			continue
+		}
+
+		totalTimer := delta.sum("fetch_timer", "insert_timer", "update_timer", "delete_timer", "lock_wait_timer")
+		metrics = append(metrics, utils.TableActivityMetrics{
+			DatabaseName:        row.DatabaseName,
+			TableName:           row.TableName,
+			FetchCount:          delta["fetch_count"],
+			FetchLatencyMs:      roundMs(float64(delta["fetch_timer"]) / picosecondsPerMillisecond),
+			InsertCount:         delta["insert_count"],
+			InsertLatencyMs:     roundMs(float64(delta["insert_timer"]) / picosecondsPerMillisecond),
+			UpdateCount:         delta["update_count"],
+			UpdateLatencyMs:     roundMs(float64(delta["update_timer"]) / picosecondsPerMillisecond),
+			DeleteCount:         delta["delete_count"],
+			DeleteLatencyMs:     roundMs(float64(delta["delete_timer"]) / picosecondsPerMillisecond),
+			LockWaitCount:       delta["lock_wait_count"],
+			LockWaitLatencyMs:   roundMs(float64(delta["lock_wait_timer"]) / picosecondsPerMillisecond),
+			TotalLatencyMs:      roundMs(float64(totalTimer) / picosecondsPerMillisecond),
+			IntervalSec:         intervalSec,
+			CollectionTimestamp: collectionTimestamp,
+		})
+	}
+
+	// The tables are sorted from the longest total latency, and by name for the same latency
+	slices.SortFunc(metrics, func(a, b utils.TableActivityMetrics) int {
+		if order := cmp.Compare(b.TotalLatencyMs, a.TotalLatencyMs); order != 0 {
+			return order
+		}
+		if order := strings.Compare(a.DatabaseName, b.DatabaseName); order != 0 {
+			return order
+		}
+		return strings.Compare(a.TableName, b.TableName)
+	})
+	if len(metrics) > limit {
+		metrics = metrics[:limit]
+	}
+	return metrics
+}
+
+// setTableActivityMetrics sets the table activity metrics in the integration.
+func setTableActivityMetrics(i *integration.Integration, args arguments.ArgumentList, metrics []utils.TableActivityMetrics) error {
+	metricList := make([]interface{}, 0, len(metrics))
+	for _, metricData := range metrics {
+		metricList = append(metricList, metricData)
+	}
+
+	return utils.IngestMetric(metricList, "MysqlTableActivitySample", i, args)
+}
@@ -0,0 +1,73 @@
+package performancemetricscollectors
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPopulateTableActivityMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	dataSource := &DataSource{DB: sqlx.NewDb(db, "sqlmock")}
+	store := persist.NewInMemoryStore()
+	args := arguments.ArgumentList{QueryMonitoringCountThreshold: 2}
+	excludedDatabases := []string{"mysql", "information_schema", "performance_schema", "sys"}
+	columns := []string{"database_name", "table_name", "fetch_count", "fetch_timer", "insert_count", "insert_timer",
+		"update_count", "update_timer", "delete_count", "delete_timer", "lock_wait_count", "lock_wait_timer"}
+
+	// The first execution only keeps the counters
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.table_io_waits_summary_by_table io")).
+		WithArgs("mysql", "information_schema", "performance_schema", "sys").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("shop", "orders", 1000, 10000000000, 100, 2000000000, 50, 1000000000, 0, 0, 5, 500000000).
+			AddRow("shop", "customers", 500, 1000000000, 0, 0, 0, 0, 0, 0, 0, 0).
+			AddRow("shop", "audit_log", 0, 0, 10, 100000000, 0, 0, 0, 0, 0, 0))
+	populateTableActivityMetrics(dataSource, i, args, excludedDatabases, store)
+	assert.Empty(t, e.Metrics)
+
+	i, err = integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e = i.LocalEntity()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.table_io_waits_summary_by_table io")).
+		WithArgs("mysql", "information_schema", "performance_schema", "sys").
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("shop", "orders", 1200, 12000000000, 110, 2500000000, 52, 1200000000, 1, 300000000, 6, 1500000000).
+			AddRow("shop", "customers", 510, 1100000000, 0, 0, 0, 0, 0, 0, 0, 0).
+			AddRow("shop", "audit_log", 0, 0, 20, 200000000, 0, 0, 0, 0, 0, 0).
+			AddRow("shop", "carts", 40, 3000000000, 0, 0, 0, 0, 0, 0, 0, 0))
+	populateTableActivityMetrics(dataSource, i, args, excludedDatabases, store)
+
+	// Only the two tables with the longest total latency are reported
+	require.Len(t, e.Metrics, 2)
+	orders := e.Metrics[0].Metrics
+	assert.Equal(t, "MysqlTableActivitySample", orders["event_type"])
+	assert.Equal(t, "shop", orders["database_name"])
+	assert.Equal(t, "orders", orders["table_name"])
+	assert.InDelta(t, 200.0, orders["fetch_count"], 0.001)
+	assert.InDelta(t, 2.0, orders["fetch_latency_ms"], 0.001)
+	assert.InDelta(t, 10.0, orders["insert_count"], 0.001)
+	assert.InDelta(t, 2.0, orders["update_count"], 0.001)
+	assert.InDelta(t, 1.0, orders["delete_count"], 0.001)
+	assert.InDelta(t, 1.0, orders["lock_wait_count"], 0.001)
+	assert.InDelta(t, 1.0, orders["lock_wait_latency_ms"], 0.001)
+	assert.InDelta(t, 4.0, orders["total_latency_ms"], 0.001)
+	// The table first used during the interval
+	assert.Equal(t, "carts", e.Metrics[1].Metrics["table_name"])
+	assert.InDelta(t, 3.0, e.Metrics[1].Metrics["total_latency_ms"], 0.001)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.table_io_waits_summary_by_table io")).WillReturnError(errQuery)
+	populateTableActivityMetrics(dataSource, i, args, excludedDatabases, store)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,238 @@
+package performancemetricscollectors
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	infrautils "github.com/newrelic/nri-mysql/src/infrautils"
+	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
+)
+
+const (
+	waitEventStoreName = "wait_events"
+
+	// Scopes of the wait event deltas.
+	waitEventScopeServer   = "server"
+	waitEventScopeDatabase = "database"
+
+	// picosecondsPerMillisecond converts the performance_schema timers, in picoseconds, to milliseconds.
+	picosecondsPerMillisecond = 1e9
+)
+
+// waitEventCategories maps the prefixes of the wait event names to their categories, like WaitEventsQuery does.
+var waitEventCategories = []struct {
+	prefix   string
+	category string
+}{
+	{"wait/io/file/innodb/", "InnoDB File IO"},
+	{"wait/io/file/sql/", "SQL File IO"},
+	{"wait/io/socket/", "Network IO"},
+	{"wait/io/table/", "Table IO"},
+	{"wait/synch/cond/", "Condition Wait"},
+	{"wait/synch/mutex/", "Mutex"},
+	{"wait/lock/table/", "Table Lock"},
+	{"wait/lock/metadata/", "Metadata Lock"},
+	{"wait/lock/transaction/", "Transaction Lock"},
+}
+
+/*
+waitEventSnapshot holds the number of waits and the time waited, in picoseconds, read by an execution. The counters of
+the server are keyed by wait event, the ones of the sessions by thread id and wait event.
+*/
+type waitEventSnapshot struct {
+	Server  map[string]counters `json:"server"`
+	Threads map[string]counters `json:"threads"`
+}
+
+/*
+PopulateWaitEventDeltaMetrics reports the waits on each wait event during the interval, for the whole server and for
+the sessions of each database, from the counters of the wait event summaries, for the events with the longest wait time.
+*/
+func PopulateWaitEventDeltaMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string) {
+	store, err := infrautils.NewStore(args, waitEventStoreName)
+	if err != nil {
+		log.Warn("Wait event deltas are not reported: %v", err)
+		return
+	}
+	populateWaitEventDeltaMetrics(db, i, args, excludedDatabases, store)
+}
+
+func populateWaitEventDeltaMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string, store persist.Storer) {
+	serverCounters, err := utils.CollectMetrics[utils.WaitEventCounters](db, utils.WaitEventsSummaryGlobalQuery)
+	if err != nil {
+		log.Error("Error collecting wait event summaries: %v", err)
+		return
+	}
+	threadCounters, err := utils.CollectMetrics[utils.WaitEventCounters](db, utils.WaitEventsSummaryByThreadQuery)
+	if err != nil {
+		log.Error("Error collecting wait event summaries by thread: %v", err)
+		return
+	}
+
+	current := newWaitEventSnapshot(serverCounters, threadCounters)
+	previous, intervalSec, ok := swapIntervalCounters(store, current)
+	if !ok {
+		return
+	}
+
+	collectionTimestamp := time.Now().UTC().Format(time.RFC3339)
+	queryCountThreshold := validator.GetValidQueryCountThreshold(args.QueryMonitoringCountThreshold)
+
+	metrics := serverWaitEventDeltas(current, previous, queryCountThreshold, intervalSec, collectionTimestamp)
+	metrics = append(metrics, databaseWaitEventDeltas(threadCounters, current, previous, excludedDatabases, queryCountThreshold, intervalSec, collectionTimestamp)...)
+	if len(metrics) == 0 {
+		return
+	}
+
+	if err = setWaitEventDeltaMetrics(i, args, metrics); err != nil {
+		log.Error("Error setting wait event delta metrics: %v", err)
+		return
+	}
+}
+
+func newWaitEventSnapshot(serverCounters, threadCounters []utils.WaitEventCounters) waitEventSnapshot {
+	snapshot := waitEventSnapshot{
+		Server:  make(map[string]counters, len(serverCounters)),
+		Threads: make(map[string]counters, len(threadCounters)),
+	}
+	for _, row := range serverCounters {
+		snapshot.Server[row.WaitEventName] = newCounters(row)
+	}
+	for _, row := range threadCounters {
+		if row.ThreadID == nil {
+			continue
+		}
+		snapshot.Threads[threadWaitEventKey(row)] = newCounters(row)
+	}
+	return snapshot
+}
+
+func threadWaitEventKey(row utils.WaitEventCounters) string {
+	return fmt.Sprintf("%d:%s", *row.ThreadID, row.WaitEventName)
+}
+
+// serverWaitEventDeltas returns the deltas of the wait events of the server with the longest wait time.
+func serverWaitEventDeltas(current, previous waitEventSnapshot, limit int, intervalSec int64, collectionTimestamp string) []utils.WaitEventDeltaMetrics {
+	metrics := make([]utils.WaitEventDeltaMetrics, 0, len(current.Server))
+	for event, counter := range current.Server {
+		previousCounter, found := previous.Server[event]
+		delta := counter.delta(previousCounter, found)
+		if delta["wait_count"] == 0 {
+			continue
+		}
+		metrics = append(metrics, newWaitEventDeltaMetrics(waitEventScopeServer, nil, event, delta, intervalSec, collectionTimestamp))
+	}
+	sortWaitEventDeltas(metrics)
+	if len(metrics) > limit {
+		metrics = metrics[:limit]
+	}
+	return metrics
+}
+
+/*
+databaseWaitEventDeltas returns the deltas of the wait events of the sessions, summed by their current database, for
+the events of the databases with the longest wait time. The sessions without a database or in an excluded one are
+left out. The waits of the sessions which ended during the interval are lost, as their summaries are dropped.
+*/
+func databaseWaitEventDeltas(threadCounters []utils.WaitEventCounters, current, previous waitEventSnapshot, excludedDatabases []string,
+	limit int, intervalSec int64, collectionTimestamp string) []utils.WaitEventDeltaMetrics {
+	type databaseWaitEvent struct {
+		database string
+		event    string
+	}
+	deltas := map[databaseWaitEvent]counters{}
+	for _, row := range threadCounters {
+		if row.ThreadID == nil || row.DatabaseName == nil || slices.ContainsFunc(excludedDatabases, func(excludedDatabase string) bool {
+			return strings.EqualFold(excludedDatabase, *row.DatabaseName)
+		}) {
+			continue
+		}
+		key := threadWaitEventKey(row)
+		previousCounter, found := previous.Threads[key]
+		delta := current.Threads[key].delta(previousCounter, found)
+		if delta["wait_count"] == 0 {
+			continue
+		}
+		databaseEvent := databaseWaitEvent{*row.DatabaseName, row.WaitEventName}
+		sum, ok := deltas[databaseEvent]
+		if !ok {
+			sum = counters{}
+			deltas[databaseEvent] = sum
+		}
+		sum["wait_count"] += delta["wait_count"]
+		sum["total_wait_time"] += delta["total_wait_time"]
+	}
+
+	metrics := make([]utils.WaitEventDeltaMetrics, 0, len(deltas))
+	for key, delta := range deltas {
+		database := key.database
+		metrics = append(metrics, newWaitEventDeltaMetrics(waitEventScopeDatabase, &database, key.event, delta, intervalSec, collectionTimestamp))
+	}
+	sortWaitEventDeltas(metrics)
+	if len(metrics) > limit {
+		metrics = metrics[:limit]
+	}
+	return metrics
+}
+
+func newWaitEventDeltaMetrics(scope string, database *string, event string, delta counters, intervalSec int64, collectionTimestamp string) utils.WaitEventDeltaMetrics {
+	waitTimeMs := float64(delta["total_wait_time"]) / picosecondsPerMillisecond
+	return utils.WaitEventDeltaMetrics{
+		Scope:               scope,
+		DatabaseName:        database,
+		WaitEventName:       event,
+		WaitCategory:        waitEventCategory(event),
+		WaitTimeMs:          roundMs(waitTimeMs),
+		WaitCount:           delta["wait_count"],
+		AvgWaitTimeMs:       roundMs(waitTimeMs / float64(delta["wait_count"])),
+		IntervalSec:         intervalSec,
+		CollectionTimestamp: collectionTimestamp,
+	}
+}
+
+// waitEventCategory returns the category of a wait event, or Other for the events of no category.
+func waitEventCategory(event string) string {
+	for _, entry := range waitEventCategories {
+		if strings.HasPrefix(event, entry.prefix) {
+			return entry.category
+		}
+	}
+	return "Other"
+}
+
+// roundMs rounds a time in milliseconds to the microsecond, like the queries do.
+func roundMs(ms float64) float64 {
+	return math.Round(ms*1000) / 1000
+}
+
+// sortWaitEventDeltas sorts the deltas from the longest wait time, and by name for the same wait time.
+func sortWaitEventDeltas(metrics []utils.WaitEventDeltaMetrics) {
+	slices.SortFunc(metrics, func(a, b utils.WaitEventDeltaMetrics) int {
+		if order := cmp.Compare(b.WaitTimeMs, a.WaitTimeMs); order != 0 {
+			return order
+		}
+		if a.DatabaseName != nil && b.DatabaseName != nil && *a.DatabaseName != *b.DatabaseName {
+			return strings.Compare(*a.DatabaseName, *b.DatabaseName)
+		}
+		return strings.Compare(a.WaitEventName, b.WaitEventName)
+	})
+}
+
+// setWaitEventDeltaMetrics sets the wait event delta metrics in the integration.
+func setWaitEventDeltaMetrics(i *integration.Integration, args arguments.ArgumentList, metrics []utils.WaitEventDeltaMetrics) error {
+	metricList := make([]interface{}, 0, len(metrics))
+	for _, metricData := range metrics {
+		metricList = append(metricList, metricData)
+	}
+
+	return utils.IngestMetric(metricList, "MysqlWaitEventDeltaSample", i, args)
+}
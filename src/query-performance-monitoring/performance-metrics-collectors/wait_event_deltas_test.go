@@ -0,0 +1,104 @@
+package performancemetricscollectors
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/persist"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitEventCategory(t *testing.T) {
+	assert.Equal(t, "InnoDB File IO", waitEventCategory("wait/io/file/innodb/innodb_data_file"))
+	assert.Equal(t, "Table IO", waitEventCategory("wait/io/table/sql/handler"))
+	assert.Equal(t, "Metadata Lock", waitEventCategory("wait/lock/metadata/sql/mdl"))
+	assert.Equal(t, "Other", waitEventCategory("wait/synch/rwlock/innodb/dict_operation_lock"))
+}
+
+func TestServerWaitEventDeltasLimit(t *testing.T) {
+	current := waitEventSnapshot{Server: map[string]counters{
+		"wait/io/table/sql/handler":            {"wait_count": 30, "total_wait_time": 15000000000},
+		"wait/lock/table/sql/handler":          {"wait_count": 10, "total_wait_time": 2000000000},
+		"wait/io/file/innodb/innodb_data_file": {"wait_count": 4, "total_wait_time": 8000000000},
+	}}
+
+	metrics := serverWaitEventDeltas(current, waitEventSnapshot{}, 2, 60, "2025-01-01T00:00:00Z")
+	require.Len(t, metrics, 2)
+	assert.Equal(t, "wait/io/table/sql/handler", metrics[0].WaitEventName)
+	assert.Equal(t, "wait/io/file/innodb/innodb_data_file", metrics[1].WaitEventName)
+}
+
+func TestPopulateWaitEventDeltaMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	dataSource := &DataSource{DB: sqlx.NewDb(db, "sqlmock")}
+	store := persist.NewInMemoryStore()
+	args := arguments.ArgumentList{QueryMonitoringCountThreshold: 20}
+	excludedDatabases := []string{"", "mysql", "information_schema", "performance_schema", "sys"}
+	serverColumns := []string{"wait_event_name", "wait_count", "total_wait_time"}
+	threadColumns := []string{"thread_id", "database_name", "wait_event_name", "wait_count", "total_wait_time"}
+
+	// The first execution only keeps the counters
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.events_waits_summary_global_by_event_name")).
+		WillReturnRows(sqlmock.NewRows(serverColumns).
+			AddRow("wait/io/table/sql/handler", 100, 50000000000).
+			AddRow("wait/lock/table/sql/handler", 10, 2000000000))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.events_waits_summary_by_thread_by_event_name")).
+		WillReturnRows(sqlmock.NewRows(threadColumns).
+			AddRow(40, "shop", "wait/io/table/sql/handler", 60, 30000000000).
+			AddRow(41, "MySQL", "wait/io/table/sql/handler", 40, 20000000000))
+	populateWaitEventDeltaMetrics(dataSource, i, args, excludedDatabases, store)
+	assert.Empty(t, e.Metrics)
+
+	i, err = integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e = i.LocalEntity()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.events_waits_summary_global_by_event_name")).
+		WillReturnRows(sqlmock.NewRows(serverColumns).
+			AddRow("wait/io/table/sql/handler", 130, 65000000000).
+			AddRow("wait/lock/table/sql/handler", 10, 2000000000).
+			AddRow("wait/io/file/innodb/innodb_data_file", 4, 8000000000))
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.events_waits_summary_by_thread_by_event_name")).
+		WillReturnRows(sqlmock.NewRows(threadColumns).
+			AddRow(40, "shop", "wait/io/table/sql/handler", 80, 40000000000).
+			AddRow(40, "shop", "wait/io/file/innodb/innodb_data_file", 4, 8000000000).
+			AddRow(41, "MySQL", "wait/io/table/sql/handler", 45, 22000000000).
+			AddRow(42, "shop", "wait/io/table/sql/handler", 5, 3000000000).
+			AddRow(43, nil, "wait/io/table/sql/handler", 1, 1000000000))
+	// The sessions of the excluded databases are left out, whatever the case of their name
+	populateWaitEventDeltaMetrics(dataSource, i, args, excludedDatabases, store)
+
+	require.Len(t, e.Metrics, 4)
+	server := e.Metrics[0].Metrics
+	assert.Equal(t, "MysqlWaitEventDeltaSample", server["event_type"])
+	assert.Equal(t, waitEventScopeServer, server["scope"])
+	assert.Equal(t, "wait/io/table/sql/handler", server["wait_event_name"])
+	assert.Equal(t, "Table IO", server["wait_category"])
+	assert.InDelta(t, 15.0, server["wait_time_ms"], 0.001)
+	assert.InDelta(t, 30.0, server["wait_count"], 0.001)
+	assert.InDelta(t, 0.5, server["avg_wait_time_ms"], 0.001)
+	assert.Equal(t, "wait/io/file/innodb/innodb_data_file", e.Metrics[1].Metrics["wait_event_name"])
+
+	// The waits of the sessions of the database are summed, including the ones of the session started during the interval
+	database := e.Metrics[2].Metrics
+	assert.Equal(t, waitEventScopeDatabase, database["scope"])
+	assert.Equal(t, "shop", database["database_name"])
+	assert.Equal(t, "wait/io/table/sql/handler", database["wait_event_name"])
+	assert.InDelta(t, 13.0, database["wait_time_ms"], 0.001)
+	assert.InDelta(t, 25.0, database["wait_count"], 0.001)
+	assert.Equal(t, "wait/io/file/innodb/innodb_data_file", e.Metrics[3].Metrics["wait_event_name"])
+	assert.InDelta(t, 8.0, e.Metrics[3].Metrics["wait_time_ms"], 0.001)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM performance_schema.events_waits_summary_global_by_event_name")).WillReturnError(errQuery)
+	populateWaitEventDeltaMetrics(dataSource, i, args, excludedDatabases, store)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -10,7 +10,7 @@ import (
 )
 
 // PopulateWaitEventMetrics retrieves wait event metrics from the database and sets them in the integration.
-func PopulateWaitEventMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string) {
+func PopulateWaitEventMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string, capabilities validator.Capabilities) {
 	// Get the query count threshold
 	queryCountThreshold := validator.GetValidQueryCountThreshold(args.QueryMonitoringCountThreshold)
 
@@ -18,7 +18,11 @@ func PopulateWaitEventMetrics(db utils.DataSource, i *integration.Integration, a
 	excludedDatabasesArgs := []interface{}{excludedDatabases, excludedDatabases, queryCountThreshold}
 
 	// Prepare the SQL query with the provided parameters
-	preparedQuery, preparedArgs, err := sqlx.In(utils.WaitEventsQuery, excludedDatabasesArgs...)
+	waitEventsQuery := utils.WaitEventsQuery
+	if !capabilities.CommonTableExpressions {
+		waitEventsQuery = utils.WaitEventsQueryBelowVersion8
+	}
+	preparedQuery, preparedArgs, err := sqlx.In(waitEventsQuery, excludedDatabasesArgs...)
 	if err != nil {
 		log.Error("Failed to prepare wait event query: %v", err)
 		return
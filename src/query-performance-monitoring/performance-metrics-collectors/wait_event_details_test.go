@@ -13,6 +13,7 @@ import (
 	"github.com/newrelic/nri-mysql/src/args"
 	constants "github.com/newrelic/nri-mysql/src/query-performance-monitoring/constants"
 	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
+	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -91,13 +92,36 @@ func TestPopulateWaitEventMetrics(t *testing.T) {
 	))
 
 	// Call the function under test
-	PopulateWaitEventMetrics(dataSource, i, args, excludedDatabases)
+	PopulateWaitEventMetrics(dataSource, i, args, excludedDatabases, mysql8Capabilities)
 	assert.NoError(t, err)
 
 	// Verify that all expectations were met
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestPopulateWaitEventMetricsBelowVersion8(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	i, err := integration.New("test", "1.0.0")
+	require.NoError(t, err)
+	e := i.LocalEntity()
+	args := args.ArgumentList{QueryMonitoringCountThreshold: 10}
+
+	// MySQL 5.7 doesn't support WITH clauses
+	mock.ExpectQuery(regexp.QuoteMeta(") sd ON wda.THREAD_ID = sd.THREAD_ID")).
+		WithArgs("mysql", "mysql", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"wait_event_name", "wait_category", "total_wait_time_ms", "query_id", "query_text", "database_name"}).
+			AddRow("wait/io/file/innodb/innodb_data_file", "InnoDB File IO", 12.5, "queryid1", "SELECT 1", "testdb"))
+	PopulateWaitEventMetrics(&DataSource{DB: sqlx.NewDb(db, "sqlmock")}, i, args, []string{"mysql"}, validator.Capabilities{Version: "5.7.44", PerformanceSchema: true})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+	require.Len(t, e.Metrics, 1)
+	assert.Equal(t, "InnoDB File IO", e.Metrics[0].Metrics["wait_category"])
+	assert.Equal(t, 12.5, e.Metrics[0].Metrics["total_wait_time_ms"])
+}
+
 // TestSetWaitEventMetrics tests the setWaitEventMetrics function.
 func TestSetWaitEventQueryMetrics(t *testing.T) {
 	i, err := integration.New("test", "1.0.0")
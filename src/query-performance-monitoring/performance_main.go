@@ -8,68 +8,128 @@ import (
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
 	arguments "github.com/newrelic/nri-mysql/src/args"
 	dbutils "github.com/newrelic/nri-mysql/src/dbutils"
-	infrautils "github.com/newrelic/nri-mysql/src/infrautils"
+	"github.com/newrelic/nri-mysql/src/query-performance-monitoring/constants"
 	performancemetricscollectors "github.com/newrelic/nri-mysql/src/query-performance-monitoring/performance-metrics-collectors"
 	utils "github.com/newrelic/nri-mysql/src/query-performance-monitoring/utils"
 	validator "github.com/newrelic/nri-mysql/src/query-performance-monitoring/validator"
 )
 
-// PopulateQueryPerformanceMetrics serves as the entry point for retrieving and populating query performance metrics, including slow queries, detailed query information, query execution plans, wait events, and blocking sessions.
-func PopulateQueryPerformanceMetrics(args arguments.ArgumentList, e *integration.Entity, i *integration.Integration) {
+// PopulateQueryPerformanceMetrics serves as the entry point for retrieving and populating query performance metrics, including slow queries, detailed query information, query execution plans, wait events, blocking sessions, metadata locks, long-running transactions, deadlocks, account activity, file I/O and table activity.
+// Failing to connect to the database or to meet the preconditions is returned as an error.
+func PopulateQueryPerformanceMetrics(args arguments.ArgumentList, e *integration.Entity, i *integration.Integration) error {
 	// Generate Data Source Name (DSN) for database connection
 	dsn := dbutils.GenerateDSN(args, "")
 
 	// Open database connection
 	db, err := utils.OpenSQLXDB(dsn)
-	infrautils.FatalIfErr(err)
+	if err != nil {
+		return err
+	}
 	defer db.Close()
 
-	// Validate preconditions before proceeding
-	preValidationErr := validator.ValidatePreconditions(db)
+	// Get the list of unique excluded databases
+	excludedDatabases := utils.GetExcludedDatabases(args.ExcludedPerformanceDatabases)
+
+	// Validate preconditions before proceeding, and get the capabilities of the server the collectors are chosen from
+	capabilities, preValidationErr := validator.ValidatePreconditions(db)
 	if preValidationErr != nil {
-		infrautils.FatalIfErr(fmt.Errorf("preconditions failed: %w", preValidationErr))
+		return fmt.Errorf("preconditions failed: %w", preValidationErr)
 	}
 
-	// Get the list of unique excluded databases
-	excludedDatabases := utils.GetExcludedDatabases(args.ExcludedPerformanceDatabases)
+	if capabilities.PerformanceSchema {
+		// Populate metrics for slow queries, individual queries and their execution plans
+		populateQueryMetrics(db, i, args, excludedDatabases, capabilities)
+
+		// Populate wait event metrics, from the recent waits or from the waits of the interval
+		start := time.Now()
+		log.Debug("Beginning to retrieve wait event metrics")
+		if validator.GetValidWaitEventsMode(args.QueryMonitoringWaitEventsMode) == constants.WaitEventsModeDelta {
+			performancemetricscollectors.PopulateWaitEventDeltaMetrics(db, i, args, excludedDatabases)
+		} else {
+			performancemetricscollectors.PopulateWaitEventMetrics(db, i, args, excludedDatabases, capabilities)
+		}
+		log.Debug("Completed fetching wait event metrics in %v", time.Since(start))
+	} else {
+		log.Info("Only the blocking sessions and the deadlocks are reported for version %s", capabilities.Version)
+	}
+
+	// Populate blocking session metrics
+	start := time.Now()
+	log.Debug("Beginning to retrieve blocking session metrics")
+	performancemetricscollectors.PopulateBlockingSessionMetrics(db, i, args, excludedDatabases, capabilities)
+	log.Debug("Completed fetching blocking session metrics in %v", time.Since(start))
+
+	if capabilities.PerformanceSchema {
+		// Populate metadata lock metrics
+		start = time.Now()
+		log.Debug("Beginning to retrieve metadata lock metrics")
+		performancemetricscollectors.PopulateMetadataLockMetrics(db, i, args, excludedDatabases)
+		log.Debug("Completed fetching metadata lock metrics in %v", time.Since(start))
+
+		// Populate long-running and idle in transaction sessions
+		start = time.Now()
+		log.Debug("Beginning to retrieve long-running transaction metrics")
+		performancemetricscollectors.PopulateLongRunningTransactionMetrics(db, i, args, excludedDatabases)
+		log.Debug("Completed fetching long-running transaction metrics in %v", time.Since(start))
+	}
+
+	// Populate the latest deadlock
+	start = time.Now()
+	log.Debug("Beginning to retrieve deadlock metrics")
+	performancemetricscollectors.PopulateDeadlockMetrics(db, i, args)
+	log.Debug("Completed fetching deadlock metrics in %v", time.Since(start))
 
+	if capabilities.PerformanceSchema {
+		// Populate account metrics
+		start = time.Now()
+		log.Debug("Beginning to retrieve account metrics")
+		performancemetricscollectors.PopulateAccountMetrics(db, i, args, utils.GetExcludedUsers(args.ExcludedPerformanceUsers))
+		log.Debug("Completed fetching account metrics in %v", time.Since(start))
+
+		// Populate file I/O metrics
+		start = time.Now()
+		log.Debug("Beginning to retrieve file I/O metrics")
+		performancemetricscollectors.PopulateFileIOMetrics(db, i, args, excludedDatabases)
+		log.Debug("Completed fetching file I/O metrics in %v", time.Since(start))
+
+		// Populate table activity metrics
+		start = time.Now()
+		log.Debug("Beginning to retrieve table activity metrics")
+		performancemetricscollectors.PopulateTableActivityMetrics(db, i, args, excludedDatabases)
+		log.Debug("Completed fetching table activity metrics in %v", time.Since(start))
+	}
+	log.Debug("Query analysis completed.")
+	return nil
+}
+
+// populateQueryMetrics populates the slow queries, then the individual queries of their digests and their execution plans.
+func populateQueryMetrics(db utils.DataSource, i *integration.Integration, args arguments.ArgumentList, excludedDatabases []string, capabilities validator.Capabilities) {
 	// Populate metrics for slow queries
 	start := time.Now()
 	log.Debug("Beginning to retrieve slow query metrics")
-	queryIDList := performancemetricscollectors.PopulateSlowQueryMetrics(i, db, args, excludedDatabases)
+	queryIDList := performancemetricscollectors.PopulateSlowQueryMetrics(i, db, args, excludedDatabases, capabilities)
 	log.Debug("Completed fetching slow query metrics in %v", time.Since(start))
 
-	if len(queryIDList) > 0 {
-		// Populate metrics for individual queries
-		start = time.Now()
-		log.Debug("Beginning to retrieve individual query metrics")
-		groupQueriesByDatabase, individualQueryDetailsErr := performancemetricscollectors.PopulateIndividualQueryDetails(db, queryIDList, i, args)
-		if individualQueryDetailsErr != nil {
-			log.Error("Error populating individual query details: %v", individualQueryDetailsErr)
-		}
-		log.Debug("Completed fetching individual query metrics in %v", time.Since(start))
-
-		if len(groupQueriesByDatabase) > 0 {
-			// Populate execution plan details
-			start = time.Now()
-			log.Debug("Beginning to retrieve query execution plan metrics")
-			performancemetricscollectors.PopulateExecutionPlans(db, groupQueriesByDatabase, i, args)
-			log.Debug("Completed fetching query execution plan metrics in %v", time.Since(start))
-		} else {
-			log.Debug("No individual query metrics to fetch.")
-		}
+	if len(queryIDList) == 0 {
+		return
 	}
 
-	// Populate wait event metrics
+	// Populate metrics for individual queries
 	start = time.Now()
-	log.Debug("Beginning to retrieve wait event metrics")
-	performancemetricscollectors.PopulateWaitEventMetrics(db, i, args, excludedDatabases)
-	log.Debug("Completed fetching wait event metrics in %v", time.Since(start))
+	log.Debug("Beginning to retrieve individual query metrics")
+	groupQueriesByDatabase, individualQueryDetailsErr := performancemetricscollectors.PopulateIndividualQueryDetails(db, queryIDList, i, args)
+	if individualQueryDetailsErr != nil {
+		log.Error("Error populating individual query details: %v", individualQueryDetailsErr)
+	}
+	log.Debug("Completed fetching individual query metrics in %v", time.Since(start))
 
-	// Populate blocking session metrics
-	start = time.Now()
-	log.Debug("Beginning to retrieve blocking session metrics")
-	performancemetricscollectors.PopulateBlockingSessionMetrics(db, i, args, excludedDatabases)
-	log.Debug("Completed fetching blocking session metrics in %v", time.Since(start))
-	log.Debug("Query analysis completed.")
+	if len(groupQueriesByDatabase) > 0 {
+		// Populate execution plan details
+		start = time.Now()
+		log.Debug("Beginning to retrieve query execution plan metrics")
+		performancemetricscollectors.PopulateExecutionPlans(db, groupQueriesByDatabase, i, args, capabilities)
+		log.Debug("Completed fetching query execution plan metrics in %v", time.Since(start))
+	} else {
+		log.Debug("No individual query metrics to fetch.")
+	}
 }
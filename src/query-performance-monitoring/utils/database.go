@@ -2,19 +2,38 @@ package utils
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
 	constants "github.com/newrelic/nri-mysql/src/query-performance-monitoring/constants"
 )
 
+const (
+	setMaxExecutionTimeFormat = "SET SESSION max_execution_time = %d"
+	resetMaxExecutionTime     = "SET SESSION max_execution_time = DEFAULT"
+)
+
 type DataSource interface {
 	Close()
 	QueryX(string) (*sqlx.Rows, error)
 	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
 }
 
+/*
+ReadOnlyDataSource is a DataSource able to run a query within a read-only transaction, which is rolled back once
+the query returns. Any attempt of the query to modify data fails instead of being executed.
+*/
+type ReadOnlyDataSource interface {
+	DataSource
+	QueryRowReadOnlyContext(ctx context.Context, query string, dest ...interface{}) error
+	QueryRowReadOnlyTimeLimitContext(ctx context.Context, timeLimit time.Duration, query string, dest ...interface{}) error
+}
+
 type Database struct {
 	source *sqlx.DB
 }
@@ -52,6 +71,50 @@ func (db *Database) QueryxContext(ctx context.Context, query string, args ...int
 	return db.source.QueryxContext(ctx, query, args...)
 }
 
+// QueryRowReadOnlyContext runs a query returning a single row within a read-only transaction and scans the row into dest.
+func (db *Database) QueryRowReadOnlyContext(ctx context.Context, query string, dest ...interface{}) error {
+	return db.queryRowReadOnly(ctx, 0, query, dest...)
+}
+
+/*
+QueryRowReadOnlyTimeLimitContext runs a query like QueryRowReadOnlyContext, aborting it when its execution exceeds timeLimit.
+The limit is set with the max_execution_time session variable, which is reset to its global value before the transaction ends.
+*/
+func (db *Database) QueryRowReadOnlyTimeLimitContext(ctx context.Context, timeLimit time.Duration, query string, dest ...interface{}) error {
+	return db.queryRowReadOnly(ctx, timeLimit, query, dest...)
+}
+
+func (db *Database) queryRowReadOnly(ctx context.Context, timeLimit time.Duration, query string, dest ...interface{}) error {
+	tx, err := db.source.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("error starting read-only transaction: %w", err)
+	}
+	// Nothing is ever committed
+	defer func() {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			log.Warn("Error rolling back read-only transaction: %v", rollbackErr)
+		}
+	}()
+
+	if timeLimit > 0 {
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf(setMaxExecutionTimeFormat, timeLimit.Milliseconds())); err != nil {
+			return fmt.Errorf("error setting execution time limit: %w", err)
+		}
+		// The connection returns to the pool once the transaction ends, so the limit must not outlive it
+		defer func() {
+			if _, resetErr := tx.ExecContext(ctx, resetMaxExecutionTime); resetErr != nil {
+				log.Warn("Error resetting execution time limit: %v", resetErr)
+			}
+		}()
+	}
+
+	err = tx.QueryRowxContext(ctx, query).Scan(dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w for query '%s'", ErrNoRowsReturned, query)
+	}
+	return err
+}
+
 // collectMetrics collects metrics from the performance schema database
 func CollectMetrics[T any](db DataSource, preparedQuery string, preparedArgs ...interface{}) ([]T, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), constants.TimeoutDuration)
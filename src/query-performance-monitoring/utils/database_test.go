@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
@@ -199,6 +200,54 @@ func TestDatabase_QueryxContext_Error(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDatabase_QueryRowReadOnlyContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{source: sqlx.NewDb(db, "sqlmock")}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("^EXPLAIN FORMAT=JSON DELETE FROM test_table$").WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}).AddRow("{}"))
+	mock.ExpectRollback()
+
+	var plan string
+	err = database.QueryRowReadOnlyContext(context.Background(), "EXPLAIN FORMAT=JSON DELETE FROM test_table", &plan)
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", plan)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("^EXPLAIN FORMAT=JSON DELETE FROM test_table$").WillReturnRows(sqlmock.NewRows([]string{"EXPLAIN"}))
+	mock.ExpectRollback()
+
+	err = database.QueryRowReadOnlyContext(context.Background(), "EXPLAIN FORMAT=JSON DELETE FROM test_table", &plan)
+	assert.ErrorIs(t, err, ErrNoRowsReturned)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
+func TestDatabase_QueryRowReadOnlyTimeLimitContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	database := &Database{source: sqlx.NewDb(db, "sqlmock")}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("^SET SESSION max_execution_time = 250$").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("^EXPLAIN ANALYZE FORMAT=TREE SELECT \\* FROM test_table$").WillReturnError(fmt.Errorf("%w", errQuery))
+	mock.ExpectExec("^SET SESSION max_execution_time = DEFAULT$").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	var tree string
+	err = database.QueryRowReadOnlyTimeLimitContext(context.Background(), 250*time.Millisecond, "EXPLAIN ANALYZE FORMAT=TREE SELECT * FROM test_table", &tree)
+	assert.ErrorIs(t, err, errQuery)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
+
 func TestOpenDB(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"reflect"
+	"slices"
 	"strings"
 
 	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
@@ -22,6 +23,8 @@ var (
 	ErrQueryTextNil    = errors.New("query text is nil")
 	ErrQueryTextEmpty  = errors.New("query text is empty")
 	ErrQueryIDNil      = errors.New("query ID is nil")
+
+	ErrReadOnlyNotSupported = errors.New("read-only transactions are not supported by the data source")
 )
 
 func CreateMetricSet(e *integration.Entity, sampleName string, args arguments.ArgumentList) *metric.Set {
@@ -35,11 +38,16 @@ func CreateMetricSet(e *integration.Entity, sampleName string, args arguments.Ar
 }
 
 func getUniqueExcludedDatabases(excludedDBList []string) []string {
+	return getUniqueExcludedValues(constants.DefaultExcludedDatabases, excludedDBList)
+}
+
+// getUniqueExcludedValues merges the default excluded values with the configured ones, without duplicates.
+func getUniqueExcludedValues(defaultExcluded []string, excludedDBList []string) []string {
 	// Create a map to store unique databases
 	uniqueDatabases := make(map[string]struct{})
 
 	// Populate the map with default excluded databases
-	for _, dbName := range constants.DefaultExcludedDatabases {
+	for _, dbName := range defaultExcluded {
 		uniqueDatabases[dbName] = struct{}{}
 	}
 
@@ -74,6 +82,62 @@ func GetExcludedDatabases(excludedDatabasesList string) []string {
 	return excludedDatabases
 }
 
+// GetExcludedUsers parses the excluded users list from a JSON string and returns a list of unique excluded users.
+func GetExcludedUsers(excludedUsersList string) []string {
+	var excludedUsersSlice []string
+	if err := json.Unmarshal([]byte(excludedUsersList), &excludedUsersSlice); err != nil {
+		log.Warn("Failed to parse excluded users list: %v. Using default list: %v", err, constants.DefaultExcludedUsers)
+	}
+
+	return getUniqueExcludedValues(constants.DefaultExcludedUsers, excludedUsersSlice)
+}
+
+// GetExplainStatementTypes parses the list of statement types to explain from a JSON string.
+// Unknown types are ignored, and the default list is used when the list can't be parsed.
+func GetExplainStatementTypes(statementTypesList string) []string {
+	if statementTypesList == "" {
+		return constants.DefaultExplainStatementTypes
+	}
+
+	var statementTypesSlice []string
+	if err := json.Unmarshal([]byte(statementTypesList), &statementTypesSlice); err != nil {
+		log.Warn("Failed to parse explain statement types list: %v. Using default list: %v", err, constants.DefaultExplainStatementTypes)
+		return constants.DefaultExplainStatementTypes
+	}
+
+	statementTypes := make([]string, 0, len(statementTypesSlice))
+	for _, statementType := range statementTypesSlice {
+		statementType = strings.ToUpper(strings.TrimSpace(statementType))
+		if !slices.Contains(constants.SupportedExplainStatementTypes, statementType) {
+			log.Warn("Ignoring unsupported explain statement type %q. Supported types: %v", statementType, constants.SupportedExplainStatementTypes)
+			continue
+		}
+		statementTypes = append(statementTypes, statementType)
+	}
+	return statementTypes
+}
+
+// GetAnalyzeDigests parses the list of query digests to run EXPLAIN ANALYZE for from a JSON string.
+func GetAnalyzeDigests(analyzeDigestsList string) []string {
+	if analyzeDigestsList == "" {
+		return nil
+	}
+
+	var analyzeDigestsSlice []string
+	if err := json.Unmarshal([]byte(analyzeDigestsList), &analyzeDigestsSlice); err != nil {
+		log.Warn("Failed to parse analyze digests list: %v. No query is analyzed", err)
+		return nil
+	}
+
+	analyzeDigests := make([]string, 0, len(analyzeDigestsSlice))
+	for _, digest := range analyzeDigestsSlice {
+		if digest = strings.TrimSpace(digest); digest != "" {
+			analyzeDigests = append(analyzeDigests, digest)
+		}
+	}
+	return analyzeDigests
+}
+
 // Helper function to convert a slice of strings to a slice of interfaces
 func ConvertToInterfaceSlice(slice []string) []interface{} {
 	result := make([]interface{}, len(slice))
@@ -112,13 +176,14 @@ func IngestMetric(metricList []interface{}, eventName string, i *integration.Int
 		return err
 	}
 
+	redactor := NewRedactor(args)
 	metricCount := 0
 	for _, model := range metricList {
 		if model == nil {
 			continue
 		}
 		metricCount++
-		err := processModel(model, instanceEntity, eventName, args)
+		err := processModel(model, instanceEntity, eventName, args, redactor)
 		if err != nil {
 			log.Error("Error processing model: %v", err)
 			return err
@@ -145,7 +210,7 @@ func IngestMetric(metricList []interface{}, eventName string, i *integration.Int
 	return nil
 }
 
-func processModel(model interface{}, instanceEntity *integration.Entity, eventName string, args arguments.ArgumentList) error {
+func processModel(model interface{}, instanceEntity *integration.Entity, eventName string, args arguments.ArgumentList, redactor *Redactor) error {
 	metricSet := CreateMetricSet(instanceEntity, eventName, args)
 
 	modelValue := reflect.ValueOf(model)
@@ -163,11 +228,18 @@ func processModel(model interface{}, instanceEntity *integration.Entity, eventNa
 		metricName := fieldType.Tag.Get("metric_name")
 		sourceType := fieldType.Tag.Get("source_type")
 
-		if field.Kind() == reflect.Ptr && !field.IsNil() {
-			SetMetric(metricSet, metricName, field.Elem().Interface(), sourceType)
-		} else if field.Kind() != reflect.Ptr {
-			SetMetric(metricSet, metricName, field.Interface(), sourceType)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		value := field.Interface()
+		// Text attributes may hold query literals or user data, which the configured policy masks
+		if text, ok := value.(string); ok {
+			value = redactor.redactField(text, fieldType.Tag.Get("redact"))
 		}
+		SetMetric(metricSet, metricName, value, sourceType)
 	}
 	return nil
 }
@@ -83,7 +83,7 @@ func TestProcessModel(t *testing.T) {
 			Field1: "value1",
 			Field2: 123,
 		}
-		err := processModel(model, entity, "testEvent", arguments.ArgumentList{})
+		err := processModel(model, entity, "testEvent", arguments.ArgumentList{}, NewRedactor(arguments.ArgumentList{}))
 		assert.NoError(t, err)
 	})
 
@@ -94,20 +94,20 @@ func TestProcessModel(t *testing.T) {
 			Field2: 123,
 			Field3: &field3Value,
 		}
-		err := processModel(model, entity, "testEvent", arguments.ArgumentList{})
+		err := processModel(model, entity, "testEvent", arguments.ArgumentList{}, NewRedactor(arguments.ArgumentList{}))
 		assert.NoError(t, err)
 	})
 
 	t.Run("InvalidModelNotStruct", func(t *testing.T) {
 		model := "invalid model"
-		err := processModel(model, entity, "testEvent", arguments.ArgumentList{})
+		err := processModel(model, entity, "testEvent", arguments.ArgumentList{}, NewRedactor(arguments.ArgumentList{}))
 		assert.Error(t, err)
 		assert.Equal(t, ErrModelIsNotValid, err)
 	})
 
 	t.Run("InvalidModelNilPointer", func(t *testing.T) {
 		var model *TestModel
-		err := processModel(model, entity, "testEvent", arguments.ArgumentList{})
+		err := processModel(model, entity, "testEvent", arguments.ArgumentList{}, NewRedactor(arguments.ArgumentList{}))
 		assert.Error(t, err)
 		assert.Equal(t, ErrModelIsNotValid, err)
 	})
@@ -193,3 +193,21 @@ func TestGetExcludedDatabases(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExcludedUsers(t *testing.T) {
+	assert.ElementsMatch(t, []string{"mysql.session", "mysql.sys", "mysql.infoschema", "monitoring"}, GetExcludedUsers(`["monitoring", " mysql.sys "]`))
+	assert.ElementsMatch(t, constants.DefaultExcludedUsers, GetExcludedUsers("[]"))
+	assert.ElementsMatch(t, constants.DefaultExcludedUsers, GetExcludedUsers("invalid"))
+}
+
+func TestGetExplainStatementTypes(t *testing.T) {
+	assert.Equal(t, []string{"SELECT", "UPDATE"}, GetExplainStatementTypes(`["select", " UPDATE ", "DROP"]`))
+	assert.Empty(t, GetExplainStatementTypes("[]"))
+	assert.Equal(t, constants.DefaultExplainStatementTypes, GetExplainStatementTypes("invalid"))
+}
+
+func TestGetAnalyzeDigests(t *testing.T) {
+	assert.Equal(t, []string{"digest1", "digest2"}, GetAnalyzeDigests(`["digest1", " digest2 ", ""]`))
+	assert.Empty(t, GetAnalyzeDigests("[]"))
+	assert.Empty(t, GetAnalyzeDigests("invalid"))
+}
@@ -1,13 +1,18 @@
 package utils
 
 type SlowQueryMetrics struct {
-	QueryID                *string  `json:"query_id" db:"query_id" metric_name:"query_id" source_type:"attribute"`
-	QueryText              *string  `json:"query_text" db:"query_text" metric_name:"query_text" source_type:"attribute"`
+	QueryID                *string  `json:"query_id" db:"query_id" metric_name:"query_id" source_type:"attribute" redact:"-"`
+	QueryText              *string  `json:"query_text" db:"query_text" metric_name:"query_text" source_type:"attribute" redact:"sql"`
 	DatabaseName           *string  `json:"database_name" db:"database_name" metric_name:"database_name" source_type:"attribute"`
 	SchemaName             *string  `json:"schema_name" db:"schema_name" metric_name:"schema_name" source_type:"attribute"`
 	ExecutionCount         *uint64  `json:"execution_count" db:"execution_count" metric_name:"execution_count" source_type:"gauge"`
 	AvgCPUTimeMs           *float64 `json:"avg_cpu_time_ms" db:"avg_cpu_time_ms" metric_name:"avg_cpu_time_ms" source_type:"gauge"`
 	AvgElapsedTimeMs       *float64 `json:"avg_elapsed_time_ms" db:"avg_elapsed_time_ms" metric_name:"avg_elapsed_time_ms" source_type:"gauge"`
+	MaxElapsedTimeMs       *float64 `json:"max_elapsed_time_ms" db:"max_elapsed_time_ms" metric_name:"max_elapsed_time_ms" source_type:"gauge"`
+	P50ElapsedTimeMs       *float64 `json:"p50_elapsed_time_ms" db:"p50_elapsed_time_ms" metric_name:"p50_elapsed_time_ms" source_type:"gauge"`
+	P95ElapsedTimeMs       *float64 `json:"p95_elapsed_time_ms" db:"p95_elapsed_time_ms" metric_name:"p95_elapsed_time_ms" source_type:"gauge"`
+	P99ElapsedTimeMs       *float64 `json:"p99_elapsed_time_ms" db:"p99_elapsed_time_ms" metric_name:"p99_elapsed_time_ms" source_type:"gauge"`
+	P999ElapsedTimeMs      *float64 `json:"p999_elapsed_time_ms" db:"p999_elapsed_time_ms" metric_name:"p999_elapsed_time_ms" source_type:"gauge"`
 	AvgDiskReads           *float64 `json:"avg_disk_reads" db:"avg_disk_reads" metric_name:"avg_disk_reads" source_type:"gauge"`
 	AvgDiskWrites          *float64 `json:"avg_disk_writes" db:"avg_disk_writes" metric_name:"avg_disk_writes" source_type:"gauge"`
 	HasFullTableScan       *string  `json:"has_full_table_scan" db:"has_full_table_scan" metric_name:"has_full_table_scan" source_type:"attribute"`
@@ -16,11 +21,21 @@ type SlowQueryMetrics struct {
 	CollectionTimestamp    *string  `json:"collection_timestamp" db:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
 }
 
+// SlowQueryLatencyPercentiles holds the latency percentiles of a digest, which are added to its SlowQueryMetrics.
+type SlowQueryLatencyPercentiles struct {
+	QueryID           *string  `db:"query_id"`
+	DatabaseName      *string  `db:"database_name"`
+	P50ElapsedTimeMs  *float64 `db:"p50_elapsed_time_ms"`
+	P95ElapsedTimeMs  *float64 `db:"p95_elapsed_time_ms"`
+	P99ElapsedTimeMs  *float64 `db:"p99_elapsed_time_ms"`
+	P999ElapsedTimeMs *float64 `db:"p999_elapsed_time_ms"`
+}
+
 type IndividualQueryMetrics struct {
-	QueryID             *string `json:"query_id" db:"query_id" metric_name:"query_id" source_type:"attribute"`
-	AnonymizedQueryText *string `json:"query_text" db:"query_text" metric_name:"query_text" source_type:"attribute"`
-	// QueryText is used only for fetching query execution plan and not ingested to New Relic
-	QueryText       *string  `json:"query_sample_text" db:"query_sample_text" metric_name:"query_sample_text" source_type:"attribute"`
+	QueryID             *string `json:"query_id" db:"query_id" metric_name:"query_id" source_type:"attribute" redact:"-"`
+	AnonymizedQueryText *string `json:"query_text" db:"query_text" metric_name:"query_text" source_type:"attribute" redact:"sql"`
+	// QueryText is used for fetching the query execution plan and only ingested to New Relic, without its literals, when QueryMonitoringReportSampleText is set
+	QueryText       *string  `json:"query_sample_text" db:"query_sample_text" metric_name:"query_sample_text" source_type:"attribute" redact:"statement"`
 	EventID         *uint64  `json:"event_id" db:"event_id" metric_name:"event_id" source_type:"gauge"`
 	ThreadID        *uint64  `json:"thread_id" db:"thread_id" metric_name:"thread_id" source_type:"gauge"`
 	ExecutionTimeMs *float64 `json:"execution_time_ms" db:"execution_time_ms" metric_name:"execution_time_ms" source_type:"gauge"`
@@ -35,6 +50,9 @@ type QueryGroup struct {
 }
 
 type QueryPlanMetrics struct {
+	QueryID             string `json:"query_id" metric_name:"query_id" source_type:"attribute" redact:"-"`
+	PlanHash            string `json:"plan_hash" metric_name:"plan_hash" source_type:"attribute" redact:"-"`
+	PlanStrategy        string `json:"plan_strategy" metric_name:"plan_strategy" source_type:"attribute" redact:"-"`
 	EventID             uint64 `json:"event_id" metric_name:"event_id" source_type:"gauge"`
 	ThreadID            uint64 `json:"thread_id" db:"thread_id" metric_name:"thread_id" source_type:"gauge"`
 	StepID              int    `json:"step_id" metric_name:"step_id" source_type:"gauge"`
@@ -54,16 +72,95 @@ type QueryPlanMetrics struct {
 	DataReadPerJoin     string `json:"data_read_per_join" metric_name:"data_read_per_join" source_type:"attribute"`
 	UsingIndex          string `json:"using_index" metric_name:"using_index" source_type:"attribute"`
 	KeyLength           string `json:"key_length" metric_name:"key_length" source_type:"attribute"`
+	UsingFilesort       string `json:"using_filesort" metric_name:"using_filesort" source_type:"attribute"`
+	UsingTemporaryTable string `json:"using_temporary_table" metric_name:"using_temporary_table" source_type:"attribute"`
+}
+
+// QueryPlanChangeMetrics describes the change of the plan of a digest since the previous execution.
+type QueryPlanChangeMetrics struct {
+	QueryID             string   `json:"query_id" metric_name:"query_id" source_type:"attribute" redact:"-"`
+	DatabaseName        string   `json:"database_name" metric_name:"database_name" source_type:"attribute"`
+	OldPlanHash         string   `json:"old_plan_hash" metric_name:"old_plan_hash" source_type:"attribute" redact:"-"`
+	NewPlanHash         string   `json:"new_plan_hash" metric_name:"new_plan_hash" source_type:"attribute" redact:"-"`
+	OldQueryCost        *float64 `json:"old_query_cost" metric_name:"old_query_cost" source_type:"gauge"`
+	NewQueryCost        *float64 `json:"new_query_cost" metric_name:"new_query_cost" source_type:"gauge"`
+	ChangedStepsCount   int      `json:"changed_steps_count" metric_name:"changed_steps_count" source_type:"gauge"`
+	ChangedSteps        string   `json:"changed_steps" metric_name:"changed_steps" source_type:"attribute"`
+	CollectionTimestamp string   `json:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
+}
+
+// QueryPlanAnalyzeMetrics holds the estimated and actual figures of an iterator of the plan reported by EXPLAIN ANALYZE.
+type QueryPlanAnalyzeMetrics struct {
+	EventID              uint64   `json:"event_id" metric_name:"event_id" source_type:"gauge"`
+	ThreadID             uint64   `json:"thread_id" metric_name:"thread_id" source_type:"gauge"`
+	QueryID              string   `json:"query_id" metric_name:"query_id" source_type:"attribute" redact:"-"`
+	StepID               int      `json:"step_id" metric_name:"step_id" source_type:"gauge"`
+	Depth                int      `json:"depth" metric_name:"depth" source_type:"gauge"`
+	Operation            string   `json:"operation" metric_name:"operation" source_type:"attribute" redact:"sql"`
+	EstimatedCost        *float64 `json:"estimated_cost" metric_name:"estimated_cost" source_type:"gauge"`
+	EstimatedRows        *float64 `json:"estimated_rows" metric_name:"estimated_rows" source_type:"gauge"`
+	ActualRows           *float64 `json:"actual_rows" metric_name:"actual_rows" source_type:"gauge"`
+	Loops                *uint64  `json:"loops" metric_name:"loops" source_type:"gauge"`
+	ActualFirstRowTimeMs *float64 `json:"actual_first_row_time_ms" metric_name:"actual_first_row_time_ms" source_type:"gauge"`
+	ActualLastRowTimeMs  *float64 `json:"actual_last_row_time_ms" metric_name:"actual_last_row_time_ms" source_type:"gauge"`
+	RowsEstimateRatio    *float64 `json:"rows_estimate_ratio" metric_name:"rows_estimate_ratio" source_type:"gauge"`
+	Executed             string   `json:"executed" metric_name:"executed" source_type:"attribute"`
+}
+
+// QueryHistorySample is the text of a statement of a digest kept in the statements history.
+type QueryHistorySample struct {
+	SQLText *string `db:"sql_text"`
+}
+
+// PreparedStatementExecution is an EXECUTE statement run by the thread which prepared the statement.
+type PreparedStatementExecution struct {
+	ThreadID      uint64  `db:"thread_id"`
+	StatementName string  `db:"statement_name"`
+	ExecuteText   *string `db:"execute_text"`
+}
+
+// UserVariable is a user variable of a thread.
+type UserVariable struct {
+	Name  string  `db:"variable_name"`
+	Value *string `db:"variable_value"`
+}
+
+// ColumnType is the data type of a column of a table.
+type ColumnType struct {
+	TableName  string `db:"table_name"`
+	ColumnName string `db:"column_name"`
+	DataType   string `db:"data_type"`
+	ColumnType string `db:"column_type"`
+}
+
+// IndexRecommendationMetrics describes the issues of the plan of a digest on a table, and the index which may solve them.
+type IndexRecommendationMetrics struct {
+	RecommendationID      string   `json:"recommendation_id" metric_name:"recommendation_id" source_type:"attribute" redact:"-"`
+	QueryID               string   `json:"query_id" metric_name:"query_id" source_type:"attribute" redact:"-"`
+	DatabaseName          string   `json:"database_name" metric_name:"database_name" source_type:"attribute"`
+	TableName             string   `json:"table_name" metric_name:"table_name" source_type:"attribute"`
+	Issues                string   `json:"issues" metric_name:"issues" source_type:"attribute"`
+	AccessType            string   `json:"access_type" metric_name:"access_type" source_type:"attribute"`
+	PossibleKeys          string   `json:"possible_keys" metric_name:"possible_keys" source_type:"attribute"`
+	Key                   string   `json:"key" metric_name:"key" source_type:"attribute"`
+	RowsExaminedPerScan   int64    `json:"rows_examined_per_scan" metric_name:"rows_examined_per_scan" source_type:"gauge"`
+	Filtered              *float64 `json:"filtered" metric_name:"filtered" source_type:"gauge"`
+	CandidateIndexColumns string   `json:"candidate_index_columns" metric_name:"candidate_index_columns" source_type:"attribute"`
+	RecommendedIndex      string   `json:"recommended_index" metric_name:"recommended_index" source_type:"attribute"`
+	CollectionTimestamp   string   `json:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
 }
 
 type Memo struct {
 	QueryCost string `json:"query_cost" metric_name:"query_cost" source_type:"gauge"`
+	// Sorts and temporary tables are reported by the operations wrapping the tables they apply to
+	UsingFilesort       bool `json:"using_filesort"`
+	UsingTemporaryTable bool `json:"using_temporary_table"`
 }
 
 type WaitEventQueryMetrics struct {
 	TotalWaitTimeMs     *float64 `json:"total_wait_time_ms" db:"total_wait_time_ms" metric_name:"total_wait_time_ms" source_type:"gauge"`
-	QueryID             *string  `json:"query_id" db:"query_id" metric_name:"query_id" source_type:"attribute"`
-	QueryText           *string  `json:"query_text" db:"query_text" metric_name:"query_text" source_type:"attribute"`
+	QueryID             *string  `json:"query_id" db:"query_id" metric_name:"query_id" source_type:"attribute" redact:"-"`
+	QueryText           *string  `json:"query_text" db:"query_text" metric_name:"query_text" source_type:"attribute" redact:"sql"`
 	DatabaseName        *string  `json:"database_name" db:"database_name" metric_name:"database_name" source_type:"attribute"`
 	WaitCategory        *string  `json:"wait_category" db:"wait_category" metric_name:"wait_category" source_type:"attribute"`
 	CollectionTimestamp *string  `json:"collection_timestamp" db:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
@@ -72,25 +169,269 @@ type WaitEventQueryMetrics struct {
 	AvgWaitTimeMs       *string  `json:"avg_wait_time_ms" db:"avg_wait_time_ms" metric_name:"avg_wait_time_ms" source_type:"attribute"`
 }
 
+// WaitEventCounters holds the counters of a wait event of the server, or of a session when ThreadID is set.
+type WaitEventCounters struct {
+	ThreadID      *uint64 `db:"thread_id"`
+	DatabaseName  *string `db:"database_name"`
+	WaitEventName string  `db:"wait_event_name"`
+	WaitCount     uint64  `db:"wait_count"`
+	TotalWaitTime uint64  `db:"total_wait_time"`
+}
+
+// WaitEventDeltaMetrics holds the waits on a wait event during the collection interval, of the server or of the sessions of a database.
+type WaitEventDeltaMetrics struct {
+	Scope               string  `json:"scope" metric_name:"scope" source_type:"attribute" redact:"-"`
+	DatabaseName        *string `json:"database_name" metric_name:"database_name" source_type:"attribute"`
+	WaitEventName       string  `json:"wait_event_name" metric_name:"wait_event_name" source_type:"attribute" redact:"-"`
+	WaitCategory        string  `json:"wait_category" metric_name:"wait_category" source_type:"attribute" redact:"-"`
+	WaitTimeMs          float64 `json:"wait_time_ms" metric_name:"wait_time_ms" source_type:"gauge"`
+	WaitCount           uint64  `json:"wait_count" metric_name:"wait_count" source_type:"gauge"`
+	AvgWaitTimeMs       float64 `json:"avg_wait_time_ms" metric_name:"avg_wait_time_ms" source_type:"gauge"`
+	IntervalSec         int64   `json:"interval_sec" metric_name:"interval_sec" source_type:"gauge"`
+	CollectionTimestamp string  `json:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
+}
+
+// FileIOCounters holds the counters of the operations on a file since the server started, or on the files of an event when FileName is unset.
+type FileIOCounters struct {
+	FileName   *string `db:"file_name"`
+	EventName  string  `db:"event_name"`
+	ReadCount  uint64  `db:"read_count"`
+	ReadBytes  uint64  `db:"read_bytes"`
+	ReadTimer  uint64  `db:"read_timer"`
+	WriteCount uint64  `db:"write_count"`
+	WriteBytes uint64  `db:"write_bytes"`
+	WriteTimer uint64  `db:"write_timer"`
+	MiscCount  uint64  `db:"misc_count"`
+	MiscTimer  uint64  `db:"misc_timer"`
+}
+
+// DataDir is the data directory of the server.
+type DataDir struct {
+	DataDir string `db:"data_dir"`
+}
+
+// FileIOMetrics holds the operations on a file during the collection interval, or on the files of an event.
+type FileIOMetrics struct {
+	Scope               string  `json:"scope" metric_name:"scope" source_type:"attribute" redact:"-"`
+	FileName            *string `json:"file_name" metric_name:"file_name" source_type:"attribute" redact:"-"`
+	EventName           string  `json:"event_name" metric_name:"event_name" source_type:"attribute" redact:"-"`
+	DatabaseName        *string `json:"database_name" metric_name:"database_name" source_type:"attribute"`
+	TableName           *string `json:"table_name" metric_name:"table_name" source_type:"attribute"`
+	ReadCount           uint64  `json:"read_count" metric_name:"read_count" source_type:"gauge"`
+	ReadBytes           uint64  `json:"read_bytes" metric_name:"read_bytes" source_type:"gauge"`
+	ReadLatencyMs       float64 `json:"read_latency_ms" metric_name:"read_latency_ms" source_type:"gauge"`
+	WriteCount          uint64  `json:"write_count" metric_name:"write_count" source_type:"gauge"`
+	WriteBytes          uint64  `json:"write_bytes" metric_name:"write_bytes" source_type:"gauge"`
+	WriteLatencyMs      float64 `json:"write_latency_ms" metric_name:"write_latency_ms" source_type:"gauge"`
+	MiscCount           uint64  `json:"misc_count" metric_name:"misc_count" source_type:"gauge"`
+	MiscLatencyMs       float64 `json:"misc_latency_ms" metric_name:"misc_latency_ms" source_type:"gauge"`
+	TotalLatencyMs      float64 `json:"total_latency_ms" metric_name:"total_latency_ms" source_type:"gauge"`
+	IntervalSec         int64   `json:"interval_sec" metric_name:"interval_sec" source_type:"gauge"`
+	CollectionTimestamp string  `json:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
+}
+
+// TableActivityCounters holds the counters of the row operations and of the lock waits of a table since the server started.
+type TableActivityCounters struct {
+	DatabaseName  string `db:"database_name"`
+	TableName     string `db:"table_name"`
+	FetchCount    uint64 `db:"fetch_count"`
+	FetchTimer    uint64 `db:"fetch_timer"`
+	InsertCount   uint64 `db:"insert_count"`
+	InsertTimer   uint64 `db:"insert_timer"`
+	UpdateCount   uint64 `db:"update_count"`
+	UpdateTimer   uint64 `db:"update_timer"`
+	DeleteCount   uint64 `db:"delete_count"`
+	DeleteTimer   uint64 `db:"delete_timer"`
+	LockWaitCount uint64 `db:"lock_wait_count"`
+	LockWaitTimer uint64 `db:"lock_wait_timer"`
+}
+
+// TableActivityMetrics holds the row operations and the lock waits of a table during the collection interval.
+type TableActivityMetrics struct {
+	DatabaseName        string  `json:"database_name" metric_name:"database_name" source_type:"attribute"`
+	TableName           string  `json:"table_name" metric_name:"table_name" source_type:"attribute"`
+	FetchCount          uint64  `json:"fetch_count" metric_name:"fetch_count" source_type:"gauge"`
+	FetchLatencyMs      float64 `json:"fetch_latency_ms" metric_name:"fetch_latency_ms" source_type:"gauge"`
+	InsertCount         uint64  `json:"insert_count" metric_name:"insert_count" source_type:"gauge"`
+	InsertLatencyMs     float64 `json:"insert_latency_ms" metric_name:"insert_latency_ms" source_type:"gauge"`
+	UpdateCount         uint64  `json:"update_count" metric_name:"update_count" source_type:"gauge"`
+	UpdateLatencyMs     float64 `json:"update_latency_ms" metric_name:"update_latency_ms" source_type:"gauge"`
+	DeleteCount         uint64  `json:"delete_count" metric_name:"delete_count" source_type:"gauge"`
+	DeleteLatencyMs     float64 `json:"delete_latency_ms" metric_name:"delete_latency_ms" source_type:"gauge"`
+	LockWaitCount       uint64  `json:"lock_wait_count" metric_name:"lock_wait_count" source_type:"gauge"`
+	LockWaitLatencyMs   float64 `json:"lock_wait_latency_ms" metric_name:"lock_wait_latency_ms" source_type:"gauge"`
+	TotalLatencyMs      float64 `json:"total_latency_ms" metric_name:"total_latency_ms" source_type:"gauge"`
+	IntervalSec         int64   `json:"interval_sec" metric_name:"interval_sec" source_type:"gauge"`
+	CollectionTimestamp string  `json:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
+}
+
 type BlockingSessionMetrics struct {
-	BlockedTxnID         *string  `json:"blocked_txn_id" db:"blocked_txn_id" metric_name:"blocked_txn_id" source_type:"attribute"`
-	BlockedPID           *string  `json:"blocked_pid" db:"blocked_pid" metric_name:"blocked_pid" source_type:"attribute"`
-	BlockedThreadID      *int64   `json:"blocked_thread_id" db:"blocked_thread_id" metric_name:"blocked_thread_id" source_type:"gauge"`
-	BlockedQueryID       *string  `json:"blocked_query_id" db:"blocked_query_id" metric_name:"blocked_query_id" source_type:"attribute"`
-	BlockedQuery         *string  `json:"blocked_query" db:"blocked_query" metric_name:"blocked_query" source_type:"attribute"`
+	BlockedTxnID    *string `json:"blocked_txn_id" db:"blocked_txn_id" metric_name:"blocked_txn_id" source_type:"attribute" redact:"-"`
+	BlockedPID      *string `json:"blocked_pid" db:"blocked_pid" metric_name:"blocked_pid" source_type:"attribute"`
+	BlockedThreadID *int64  `json:"blocked_thread_id" db:"blocked_thread_id" metric_name:"blocked_thread_id" source_type:"gauge"`
+	BlockedQueryID  *string `json:"blocked_query_id" db:"blocked_query_id" metric_name:"blocked_query_id" source_type:"attribute" redact:"-"`
+	// The queries are digest texts, or the statements as they were run on MySQL 5.7 and MariaDB, whose literals are replaced
+	BlockedQuery         *string  `json:"blocked_query" db:"blocked_query" metric_name:"blocked_query" source_type:"attribute" redact:"statement"`
 	BlockedStatus        *string  `json:"blocked_status" db:"blocked_status" metric_name:"blocked_status" source_type:"attribute"`
 	BlockedHost          *string  `json:"blocked_host" db:"blocked_host" metric_name:"blocked_host" source_type:"attribute"`
 	BlockedDB            *string  `json:"database_name" db:"database_name" metric_name:"database_name" source_type:"attribute"`
-	BlockingTxnID        *string  `json:"blocking_txn_id" db:"blocking_txn_id" metric_name:"blocking_txn_id" source_type:"attribute"`
+	BlockingTxnID        *string  `json:"blocking_txn_id" db:"blocking_txn_id" metric_name:"blocking_txn_id" source_type:"attribute" redact:"-"`
 	BlockingPID          *string  `json:"blocking_pid" db:"blocking_pid" metric_name:"blocking_pid" source_type:"attribute"`
 	BlockingThreadID     *int64   `json:"blocking_thread_id" db:"blocking_thread_id" metric_name:"blocking_thread_id" source_type:"gauge"`
 	BlockingHost         *string  `json:"blocking_host" db:"blocking_host" metric_name:"blocking_host" source_type:"attribute"`
-	BlockingQueryID      *string  `json:"blocking_query_id" db:"blocking_query_id" metric_name:"blocking_query_id" source_type:"attribute"`
-	BlockingQuery        *string  `json:"blocking_query" db:"blocking_query" metric_name:"blocking_query" source_type:"attribute"`
+	BlockingQueryID      *string  `json:"blocking_query_id" db:"blocking_query_id" metric_name:"blocking_query_id" source_type:"attribute" redact:"-"`
+	BlockingQuery        *string  `json:"blocking_query" db:"blocking_query" metric_name:"blocking_query" source_type:"attribute" redact:"statement"`
 	BlockingStatus       *string  `json:"blocking_status" db:"blocking_status" metric_name:"blocking_status" source_type:"attribute"`
 	BlockedQueryTimeMs   *float64 `json:"blocked_query_time_ms" db:"blocked_query_time_ms" metric_name:"blocked_query_time_ms" source_type:"gauge"`
 	BlockingQueryTimeMs  *float64 `json:"blocking_query_time_ms" db:"blocking_query_time_ms" metric_name:"blocking_query_time_ms" source_type:"gauge"`
 	BlockedTxnStartTime  *string  `json:"blocked_txn_start_time" db:"blocked_txn_start_time" metric_name:"blocked_txn_start_time" source_type:"attribute"`
 	BlockingTxnStartTime *string  `json:"blocking_txn_start_time" db:"blocking_txn_start_time" metric_name:"blocking_txn_start_time" source_type:"attribute"`
 	CollectionTimestamp  *string  `json:"collection_timestamp" db:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
+	RootBlockingPID      *string  `json:"root_blocking_pid" metric_name:"root_blocking_pid" source_type:"attribute"`
+	BlockingChainDepth   *int64   `json:"blocking_chain_depth" metric_name:"blocking_chain_depth" source_type:"gauge"`
+	BlockingCycle        *string  `json:"blocking_cycle" metric_name:"blocking_cycle" source_type:"attribute" redact:"-"`
+}
+
+// BlockingChainMetrics describes a root blocker of the lock-wait graph, and the sessions waiting for it directly or transitively.
+type BlockingChainMetrics struct {
+	RootBlockingPID         *string `json:"root_blocking_pid" metric_name:"root_blocking_pid" source_type:"attribute"`
+	RootBlockingThreadID    *int64  `json:"root_blocking_thread_id" metric_name:"root_blocking_thread_id" source_type:"gauge"`
+	RootBlockingHost        *string `json:"root_blocking_host" metric_name:"root_blocking_host" source_type:"attribute"`
+	RootBlockingStatus      *string `json:"root_blocking_status" metric_name:"root_blocking_status" source_type:"attribute"`
+	RootBlockingQueryID     *string `json:"root_blocking_query_id" metric_name:"root_blocking_query_id" source_type:"attribute" redact:"-"`
+	RootBlockingQuery       *string `json:"root_blocking_query" metric_name:"root_blocking_query" source_type:"attribute" redact:"statement"`
+	DirectlyBlockedSessions int64   `json:"directly_blocked_sessions" metric_name:"directly_blocked_sessions" source_type:"gauge"`
+	TotalBlockedSessions    int64   `json:"total_blocked_sessions" metric_name:"total_blocked_sessions" source_type:"gauge"`
+	MaxChainDepth           int64   `json:"max_chain_depth" metric_name:"max_chain_depth" source_type:"gauge"`
+	TotalBlockedTimeMs      float64 `json:"total_blocked_time_ms" metric_name:"total_blocked_time_ms" source_type:"gauge"`
+	BlockingCycle           string  `json:"blocking_cycle" metric_name:"blocking_cycle" source_type:"attribute" redact:"-"`
+	CollectionTimestamp     *string `json:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
+}
+
+// MetadataLockMetrics describes a session waiting for a metadata lock, and a session whose lock on the same object it waits for.
+type MetadataLockMetrics struct {
+	ObjectType           *string  `json:"object_type" db:"object_type" metric_name:"object_type" source_type:"attribute"`
+	ObjectSchema         *string  `json:"object_schema" db:"object_schema" metric_name:"object_schema" source_type:"attribute"`
+	ObjectName           *string  `json:"object_name" db:"object_name" metric_name:"object_name" source_type:"attribute"`
+	WaitingThreadID      *int64   `json:"waiting_thread_id" db:"waiting_thread_id" metric_name:"waiting_thread_id" source_type:"gauge"`
+	WaitingPID           *string  `json:"waiting_pid" db:"waiting_pid" metric_name:"waiting_pid" source_type:"attribute"`
+	WaitingUser          *string  `json:"waiting_user" db:"waiting_user" metric_name:"waiting_user" source_type:"attribute"`
+	WaitingHost          *string  `json:"waiting_host" db:"waiting_host" metric_name:"waiting_host" source_type:"attribute"`
+	WaitingLockType      *string  `json:"waiting_lock_type" db:"waiting_lock_type" metric_name:"waiting_lock_type" source_type:"attribute" redact:"-"`
+	WaitingLockDuration  *string  `json:"waiting_lock_duration" db:"waiting_lock_duration" metric_name:"waiting_lock_duration" source_type:"attribute" redact:"-"`
+	WaitingQueryID       *string  `json:"waiting_query_id" db:"waiting_query_id" metric_name:"waiting_query_id" source_type:"attribute" redact:"-"`
+	WaitingQuery         *string  `json:"waiting_query" db:"waiting_query" metric_name:"waiting_query" source_type:"attribute" redact:"sql"`
+	WaitingQueryTimeMs   *float64 `json:"waiting_query_time_ms" db:"waiting_query_time_ms" metric_name:"waiting_query_time_ms" source_type:"gauge"`
+	BlockingThreadID     *int64   `json:"blocking_thread_id" db:"blocking_thread_id" metric_name:"blocking_thread_id" source_type:"gauge"`
+	BlockingPID          *string  `json:"blocking_pid" db:"blocking_pid" metric_name:"blocking_pid" source_type:"attribute"`
+	BlockingUser         *string  `json:"blocking_user" db:"blocking_user" metric_name:"blocking_user" source_type:"attribute"`
+	BlockingHost         *string  `json:"blocking_host" db:"blocking_host" metric_name:"blocking_host" source_type:"attribute"`
+	BlockingCommand      *string  `json:"blocking_command" db:"blocking_command" metric_name:"blocking_command" source_type:"attribute" redact:"-"`
+	BlockingLockType     *string  `json:"blocking_lock_type" db:"blocking_lock_type" metric_name:"blocking_lock_type" source_type:"attribute" redact:"-"`
+	BlockingLockDuration *string  `json:"blocking_lock_duration" db:"blocking_lock_duration" metric_name:"blocking_lock_duration" source_type:"attribute" redact:"-"`
+	BlockingLockStatus   *string  `json:"blocking_lock_status" db:"blocking_lock_status" metric_name:"blocking_lock_status" source_type:"attribute" redact:"-"`
+	BlockingQueryID      *string  `json:"blocking_query_id" db:"blocking_query_id" metric_name:"blocking_query_id" source_type:"attribute" redact:"-"`
+	BlockingQuery        *string  `json:"blocking_query" db:"blocking_query" metric_name:"blocking_query" source_type:"attribute" redact:"sql"`
+	BlockingQueryTimeMs  *float64 `json:"blocking_query_time_ms" db:"blocking_query_time_ms" metric_name:"blocking_query_time_ms" source_type:"gauge"`
+	CollectionTimestamp  *string  `json:"collection_timestamp" db:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
+}
+
+// LongRunningTransactionMetrics describes an open transaction which has been running for long or whose session is idle.
+type LongRunningTransactionMetrics struct {
+	TrxID             *string `json:"trx_id" db:"trx_id" metric_name:"trx_id" source_type:"attribute" redact:"-"`
+	PID               *string `json:"pid" db:"pid" metric_name:"pid" source_type:"attribute"`
+	ThreadID          *int64  `json:"thread_id" db:"thread_id" metric_name:"thread_id" source_type:"gauge"`
+	User              *string `json:"user" db:"user" metric_name:"user" source_type:"attribute"`
+	Host              *string `json:"host" db:"host" metric_name:"host" source_type:"attribute"`
+	DatabaseName      *string `json:"database_name" db:"database_name" metric_name:"database_name" source_type:"attribute"`
+	Command           *string `json:"command" db:"command" metric_name:"command" source_type:"attribute" redact:"-"`
+	TrxState          *string `json:"trx_state" db:"trx_state" metric_name:"trx_state" source_type:"attribute" redact:"-"`
+	TrxOperationState *string `json:"trx_operation_state" db:"trx_operation_state" metric_name:"trx_operation_state" source_type:"attribute" redact:"-"`
+	TrxIsolationLevel *string `json:"trx_isolation_level" db:"trx_isolation_level" metric_name:"trx_isolation_level" source_type:"attribute" redact:"-"`
+	SessionState      *string `json:"session_state" db:"session_state" metric_name:"session_state" source_type:"attribute" redact:"-"`
+	TrxStartTime      *string `json:"trx_start_time" db:"trx_start_time" metric_name:"trx_start_time" source_type:"attribute"`
+	TrxAgeSec         *int64  `json:"trx_age_sec" db:"trx_age_sec" metric_name:"trx_age_sec" source_type:"gauge"`
+	IdleTimeSec       *int64  `json:"idle_time_sec" db:"idle_time_sec" metric_name:"idle_time_sec" source_type:"gauge"`
+	RowsLocked        *int64  `json:"rows_locked" db:"rows_locked" metric_name:"rows_locked" source_type:"gauge"`
+	LockStructs       *int64  `json:"lock_structs" db:"lock_structs" metric_name:"lock_structs" source_type:"gauge"`
+	TablesLocked      *int64  `json:"tables_locked" db:"tables_locked" metric_name:"tables_locked" source_type:"gauge"`
+	RowsModified      *int64  `json:"rows_modified" db:"rows_modified" metric_name:"rows_modified" source_type:"gauge"`
+	UndoLogEntries    *int64  `json:"undo_log_entries" metric_name:"undo_log_entries" source_type:"gauge"`
+	LastQueryID       *string `json:"last_query_id" db:"last_query_id" metric_name:"last_query_id" source_type:"attribute" redact:"-"`
+	// LastQuery is the digest text of the last statement, or the statement as it is run when it has no digest
+	LastQuery           *string `json:"last_query" db:"last_query" metric_name:"last_query" source_type:"attribute" redact:"statement"`
+	CollectionTimestamp *string `json:"collection_timestamp" db:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
+}
+
+// AccountCounters holds the connections of an account, of its user and of its host, and the counters of its statements since the server started.
+type AccountCounters struct {
+	User                   string `db:"user"`
+	Host                   string `db:"host"`
+	CurrentConnections     int64  `db:"current_connections"`
+	TotalConnections       uint64 `db:"total_connections"`
+	UserCurrentConnections int64  `db:"user_current_connections"`
+	UserTotalConnections   uint64 `db:"user_total_connections"`
+	HostCurrentConnections int64  `db:"host_current_connections"`
+	HostTotalConnections   uint64 `db:"host_total_connections"`
+	StatementCount         uint64 `db:"statement_count"`
+	StatementErrorCount    uint64 `db:"statement_error_count"`
+	StatementTimer         uint64 `db:"statement_timer"`
+	RowsExamined           uint64 `db:"rows_examined"`
+}
+
+// AccountMetrics holds the current connections of an account, and its connections and statements during the collection interval.
+type AccountMetrics struct {
+	User                    string  `json:"user" metric_name:"user" source_type:"attribute"`
+	Host                    string  `json:"host" metric_name:"host" source_type:"attribute"`
+	CurrentConnections      int64   `json:"current_connections" metric_name:"current_connections" source_type:"gauge"`
+	TotalConnections        uint64  `json:"total_connections" metric_name:"total_connections" source_type:"gauge"`
+	UserCurrentConnections  int64   `json:"user_current_connections" metric_name:"user_current_connections" source_type:"gauge"`
+	UserTotalConnections    uint64  `json:"user_total_connections" metric_name:"user_total_connections" source_type:"gauge"`
+	HostCurrentConnections  int64   `json:"host_current_connections" metric_name:"host_current_connections" source_type:"gauge"`
+	HostTotalConnections    uint64  `json:"host_total_connections" metric_name:"host_total_connections" source_type:"gauge"`
+	StatementCount          uint64  `json:"statement_count" metric_name:"statement_count" source_type:"gauge"`
+	StatementErrorCount     uint64  `json:"statement_error_count" metric_name:"statement_error_count" source_type:"gauge"`
+	TotalStatementLatencyMs float64 `json:"total_statement_latency_ms" metric_name:"total_statement_latency_ms" source_type:"gauge"`
+	AvgStatementLatencyMs   float64 `json:"avg_statement_latency_ms" metric_name:"avg_statement_latency_ms" source_type:"gauge"`
+	RowsExamined            uint64  `json:"rows_examined" metric_name:"rows_examined" source_type:"gauge"`
+	IntervalSec             int64   `json:"interval_sec" metric_name:"interval_sec" source_type:"gauge"`
+	CollectionTimestamp     string  `json:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
+}
+
+// InnoDBStatus is the output of SHOW ENGINE INNODB STATUS.
+type InnoDBStatus struct {
+	Type   string `db:"Type"`
+	Name   string `db:"Name"`
+	Status string `db:"Status"`
+}
+
+// DeadlockMetrics describes the latest deadlock detected by InnoDB, with the two transactions of the lock wait cycle.
+// The statements of the transactions are taken as they were run, so their literals are replaced when ingested.
+type DeadlockMetrics struct {
+	DeadlockTimestamp   string  `json:"deadlock_timestamp" metric_name:"deadlock_timestamp" source_type:"attribute" redact:"-"`
+	VictimTransaction   int     `json:"victim_transaction" metric_name:"victim_transaction" source_type:"gauge"`
+	VictimTrxID         string  `json:"victim_trx_id" metric_name:"victim_trx_id" source_type:"attribute" redact:"-"`
+	Trx1ID              string  `json:"trx1_id" metric_name:"trx1_id" source_type:"attribute" redact:"-"`
+	Trx1ThreadID        *uint64 `json:"trx1_thread_id" metric_name:"trx1_thread_id" source_type:"gauge"`
+	Trx1User            string  `json:"trx1_user" metric_name:"trx1_user" source_type:"attribute"`
+	Trx1Host            string  `json:"trx1_host" metric_name:"trx1_host" source_type:"attribute"`
+	Trx1ActiveTimeSec   *int64  `json:"trx1_active_time_sec" metric_name:"trx1_active_time_sec" source_type:"gauge"`
+	Trx1RowLocks        *int64  `json:"trx1_row_locks" metric_name:"trx1_row_locks" source_type:"gauge"`
+	Trx1Query           string  `json:"trx1_query" metric_name:"trx1_query" source_type:"attribute" redact:"statement"`
+	Trx1LocksHeld       string  `json:"trx1_locks_held" metric_name:"trx1_locks_held" source_type:"attribute"`
+	Trx1LockWaited      string  `json:"trx1_lock_waited" metric_name:"trx1_lock_waited" source_type:"attribute"`
+	Trx1WaitedTable     string  `json:"trx1_waited_table" metric_name:"trx1_waited_table" source_type:"attribute"`
+	Trx1WaitedIndex     string  `json:"trx1_waited_index" metric_name:"trx1_waited_index" source_type:"attribute"`
+	Trx2ID              string  `json:"trx2_id" metric_name:"trx2_id" source_type:"attribute" redact:"-"`
+	Trx2ThreadID        *uint64 `json:"trx2_thread_id" metric_name:"trx2_thread_id" source_type:"gauge"`
+	Trx2User            string  `json:"trx2_user" metric_name:"trx2_user" source_type:"attribute"`
+	Trx2Host            string  `json:"trx2_host" metric_name:"trx2_host" source_type:"attribute"`
+	Trx2ActiveTimeSec   *int64  `json:"trx2_active_time_sec" metric_name:"trx2_active_time_sec" source_type:"gauge"`
+	Trx2RowLocks        *int64  `json:"trx2_row_locks" metric_name:"trx2_row_locks" source_type:"gauge"`
+	Trx2Query           string  `json:"trx2_query" metric_name:"trx2_query" source_type:"attribute" redact:"statement"`
+	Trx2LocksHeld       string  `json:"trx2_locks_held" metric_name:"trx2_locks_held" source_type:"attribute"`
+	Trx2LockWaited      string  `json:"trx2_lock_waited" metric_name:"trx2_lock_waited" source_type:"attribute"`
+	Trx2WaitedTable     string  `json:"trx2_waited_table" metric_name:"trx2_waited_table" source_type:"attribute"`
+	Trx2WaitedIndex     string  `json:"trx2_waited_index" metric_name:"trx2_waited_index" source_type:"attribute"`
+	CollectionTimestamp string  `json:"collection_timestamp" metric_name:"collection_timestamp" source_type:"attribute"`
 }
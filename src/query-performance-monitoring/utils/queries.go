@@ -24,6 +24,7 @@ const (
 			COUNT_STAR AS execution_count,
 			ROUND((SUM_CPU_TIME / COUNT_STAR) / 1000000000, 3) AS avg_cpu_time_ms,
 			ROUND((SUM_TIMER_WAIT / COUNT_STAR) / 1000000000, 3) AS avg_elapsed_time_ms,
+			ROUND(MAX_TIMER_WAIT / 1000000000, 3) AS max_elapsed_time_ms,
 			SUM_ROWS_EXAMINED / COUNT_STAR AS avg_disk_reads,
 			SUM_ROWS_AFFECTED / COUNT_STAR AS avg_disk_writes,
 			CASE
@@ -47,6 +48,95 @@ const (
 		LIMIT ?;
     `
 
+	/*
+		SlowQueriesWithoutCPUTime: The counterpart of SlowQueries for MySQL 5.7 and the MySQL 8.0 versions before 8.0.28,
+		whose digest summary doesn't report the CPU time of the statements. The average CPU time is left unset.
+
+		Arguments:
+		1. Interval in seconds (INT): The time period to look back for slow queries.
+		2. Excluded databases (STRING): A comma-separated list of database names to exclude from the results.
+		3. Limit (INT): The maximum number of results to return.
+	*/
+	SlowQueriesWithoutCPUTime = `
+        SELECT
+			DIGEST AS query_id,
+			CASE
+				WHEN CHAR_LENGTH(DIGEST_TEXT) > 4000 THEN CONCAT(LEFT(DIGEST_TEXT, 3997), '...')
+				ELSE DIGEST_TEXT
+			END AS query_text,
+			SCHEMA_NAME AS database_name,
+			'N/A' AS schema_name,
+			COUNT_STAR AS execution_count,
+			NULL AS avg_cpu_time_ms,
+			ROUND((SUM_TIMER_WAIT / COUNT_STAR) / 1000000000, 3) AS avg_elapsed_time_ms,
+			ROUND(MAX_TIMER_WAIT / 1000000000, 3) AS max_elapsed_time_ms,
+			SUM_ROWS_EXAMINED / COUNT_STAR AS avg_disk_reads,
+			SUM_ROWS_AFFECTED / COUNT_STAR AS avg_disk_writes,
+			CASE
+				WHEN SUM_NO_INDEX_USED > 0 THEN 'Yes'
+				ELSE 'No'
+			END AS has_full_table_scan,
+			CASE
+				WHEN DIGEST_TEXT LIKE 'SELECT%' THEN 'SELECT'
+				WHEN DIGEST_TEXT LIKE 'INSERT%' THEN 'INSERT'
+				WHEN DIGEST_TEXT LIKE 'UPDATE%' THEN 'UPDATE'
+				WHEN DIGEST_TEXT LIKE 'DELETE%' THEN 'DELETE'
+				ELSE 'OTHER'
+			END AS statement_type,
+			DATE_FORMAT(LAST_SEEN, '%Y-%m-%dT%H:%i:%sZ') AS last_execution_timestamp,
+			DATE_FORMAT(UTC_TIMESTAMP(), '%Y-%m-%dT%H:%i:%sZ') AS collection_timestamp
+		FROM performance_schema.events_statements_summary_by_digest
+		WHERE LAST_SEEN >= UTC_TIMESTAMP() - INTERVAL ? SECOND
+			AND SCHEMA_NAME IS NOT NULL
+			AND SCHEMA_NAME NOT IN (?)
+		ORDER BY avg_elapsed_time_ms DESC
+		LIMIT ?;
+    `
+
+	/*
+		SlowQueryLatencyPercentilesQuery: Computes the p50, p95, p99 and p99.9 latencies of the given digests from their
+		latency histograms, which reveal the tail latency hidden by the average. Each percentile is reported as the
+		upper bound of the first bucket which holds at least that fraction of the executions, in the same way the
+		QUANTILE_* columns of events_statements_summary_by_digest are computed. Available from MySQL 8.0.
+
+		Arguments:
+		1. Digests (STRING): A comma-separated list of the digests of the slow queries.
+	*/
+	SlowQueryLatencyPercentilesQuery = `
+		SELECT
+			DIGEST AS query_id,
+			SCHEMA_NAME AS database_name,
+			ROUND(MIN(CASE WHEN BUCKET_QUANTILE >= 0.50 THEN BUCKET_TIMER_HIGH END) / 1000000000, 3) AS p50_elapsed_time_ms,
+			ROUND(MIN(CASE WHEN BUCKET_QUANTILE >= 0.95 THEN BUCKET_TIMER_HIGH END) / 1000000000, 3) AS p95_elapsed_time_ms,
+			ROUND(MIN(CASE WHEN BUCKET_QUANTILE >= 0.99 THEN BUCKET_TIMER_HIGH END) / 1000000000, 3) AS p99_elapsed_time_ms,
+			ROUND(MIN(CASE WHEN BUCKET_QUANTILE >= 0.999 THEN BUCKET_TIMER_HIGH END) / 1000000000, 3) AS p999_elapsed_time_ms
+		FROM performance_schema.events_statements_histogram_by_digest
+		WHERE DIGEST IN (?)
+			AND COUNT_BUCKET_AND_LOWER > 0
+		GROUP BY
+			DIGEST,
+			SCHEMA_NAME;
+	`
+
+	/*
+		SlowQueryLatencyQuantilesQuery: Fetches the p95, p99 and p99.9 latencies of the given digests precomputed by the server
+		in events_statements_summary_by_digest, which has no median. It is used as a fallback for the digests whose latency histogram is
+		not available, e.g. because the histogram table has been truncated or its rows evicted.
+
+		Arguments:
+		1. Digests (STRING): A comma-separated list of the digests of the slow queries.
+	*/
+	SlowQueryLatencyQuantilesQuery = `
+		SELECT
+			DIGEST AS query_id,
+			SCHEMA_NAME AS database_name,
+			ROUND(QUANTILE_95 / 1000000000, 3) AS p95_elapsed_time_ms,
+			ROUND(QUANTILE_99 / 1000000000, 3) AS p99_elapsed_time_ms,
+			ROUND(QUANTILE_999 / 1000000000, 3) AS p999_elapsed_time_ms
+		FROM performance_schema.events_statements_summary_by_digest
+		WHERE DIGEST IN (?);
+	`
+
 	/*
 		CurrentRunningQueriesSearch: Fetches current running queries that match a specific digest.
 		Useful for real-time monitoring of active query execution, enabling the identification
@@ -247,16 +337,230 @@ const (
 	`
 
 	/*
-		BlockingSessionsQuery: Identifies and details current database transactions that are blocked by others.
-		This query provides information about blocked and blocking transactions, including their execution time
-		and queries involved. It is vital for detecting deadlocks or contention issues, helping in resolving
-		immediate blocking problems and planning long-term query and index optimizations to reduce this
-		occurrence.
+		WaitEventsQueryBelowVersion8: The counterpart of WaitEventsQuery for MySQL 5.7, which doesn't support common
+		table expressions, written with derived tables instead.
 
 		Arguments:
 		1. Excluded databases (STRING): A comma-separated list of database names to exclude from the results.
 		2. Limit (INT): The maximum number of results to return.
 	*/
+	WaitEventsQueryBelowVersion8 = `
+		SELECT
+			jd.query_id,
+			jd.database_name,
+			jd.wait_event_name,
+			CASE
+				WHEN jd.wait_event_name LIKE 'wait/io/file/innodb/%' THEN 'InnoDB File IO'
+				WHEN jd.wait_event_name LIKE 'wait/io/file/sql/%' THEN 'SQL File IO'
+				WHEN jd.wait_event_name LIKE 'wait/io/socket/%' THEN 'Network IO'
+				WHEN jd.wait_event_name LIKE 'wait/synch/cond/%' THEN 'Condition Wait'
+				WHEN jd.wait_event_name LIKE 'wait/synch/mutex/%' THEN 'Mutex'
+				WHEN jd.wait_event_name LIKE 'wait/lock/table/%' THEN 'Table Lock'
+				WHEN jd.wait_event_name LIKE 'wait/lock/metadata/%' THEN 'Metadata Lock'
+				WHEN jd.wait_event_name LIKE 'wait/lock/transaction/%' THEN 'Transaction Lock'
+				ELSE 'Other'
+			END AS wait_category,
+			ROUND(SUM(jd.total_wait_time) / 1000000000, 3) AS total_wait_time_ms,
+			SUM(jd.wait_event_count) AS wait_event_count,
+			ROUND(SUM(jd.total_wait_time) / 1000000000 / SUM(jd.wait_event_count), 3) AS avg_wait_time_ms,
+			CASE
+				WHEN CHAR_LENGTH(jd.query_text) > 4000 THEN CONCAT(LEFT(jd.query_text, 3997), '...')
+				ELSE jd.query_text
+			END AS query_text,
+			DATE_FORMAT(UTC_TIMESTAMP(), '%Y-%m-%dT%H:%i:%sZ') AS collection_timestamp
+		FROM (
+			SELECT
+				wda.wait_event_name,
+				wda.total_wait_time,
+				wda.wait_event_count,
+				sd.query_id,
+				sd.database_name,
+				sd.query_text
+			FROM (
+				SELECT
+					w.THREAD_ID,
+					w.EVENT_NAME AS wait_event_name,
+					SUM(w.TIMER_WAIT) AS total_wait_time,
+					COUNT(*) AS wait_event_count
+				FROM
+					performance_schema.events_waits_current w
+				GROUP BY
+					w.THREAD_ID,
+					w.EVENT_NAME
+				UNION ALL
+				SELECT
+					w.THREAD_ID,
+					w.EVENT_NAME AS wait_event_name,
+					SUM(w.TIMER_WAIT) AS total_wait_time,
+					COUNT(*) AS wait_event_count
+				FROM
+					performance_schema.events_waits_history w
+				GROUP BY
+					w.THREAD_ID,
+					w.EVENT_NAME
+			) wda
+			JOIN (
+				SELECT
+					s.THREAD_ID,
+					s.DIGEST AS query_id,
+					s.CURRENT_SCHEMA AS database_name,
+					s.DIGEST_TEXT AS query_text
+				FROM
+					performance_schema.events_statements_current s
+				WHERE
+					s.CURRENT_SCHEMA NOT IN (?)
+				UNION ALL
+				SELECT
+					s.THREAD_ID,
+					s.DIGEST AS query_id,
+					s.CURRENT_SCHEMA AS database_name,
+					s.DIGEST_TEXT AS query_text
+				FROM
+					performance_schema.events_statements_history s
+				WHERE
+					s.CURRENT_SCHEMA NOT IN (?)
+			) sd ON wda.THREAD_ID = sd.THREAD_ID
+		) jd
+		WHERE jd.query_id IS NOT NULL
+		GROUP BY
+			jd.query_id,
+			jd.database_name,
+			jd.wait_event_name,
+			wait_category,
+			jd.query_text
+		ORDER BY
+			total_wait_time_ms DESC
+		LIMIT ?;
+	`
+
+	/*
+		WaitEventsSummaryGlobalQuery: Retrieves the number of waits and the time waited on each wait event since the
+		server started, or since the summary was truncated. The idle event is left out, as it is the time the sessions
+		wait for their clients, not time the server spends on them.
+	*/
+	WaitEventsSummaryGlobalQuery = `
+		SELECT
+			EVENT_NAME AS wait_event_name,
+			COUNT_STAR AS wait_count,
+			SUM_TIMER_WAIT AS total_wait_time
+		FROM
+			performance_schema.events_waits_summary_global_by_event_name
+		WHERE
+			COUNT_STAR > 0
+			AND EVENT_NAME <> 'idle';
+	`
+
+	/*
+		WaitEventsSummaryByThreadQuery: Retrieves the number of waits and the time waited on each wait event by each
+		session since it started, along with the current database of the session. The summaries of a thread are
+		dropped when it ends.
+	*/
+	WaitEventsSummaryByThreadQuery = `
+		SELECT
+			w.THREAD_ID AS thread_id,
+			t.PROCESSLIST_DB AS database_name,
+			w.EVENT_NAME AS wait_event_name,
+			w.COUNT_STAR AS wait_count,
+			w.SUM_TIMER_WAIT AS total_wait_time
+		FROM
+			performance_schema.events_waits_summary_by_thread_by_event_name w
+		JOIN
+			performance_schema.threads t ON w.THREAD_ID = t.THREAD_ID
+		WHERE
+			t.TYPE = 'FOREGROUND'
+			AND w.COUNT_STAR > 0
+			AND w.EVENT_NAME <> 'idle';
+	`
+
+	/*
+		FileSummaryByInstanceQuery: Retrieves the number of reads, writes and other operations on each file opened by
+		the server since it started, with the bytes read and written and the time spent on them. The summary of a file
+		is dropped when it is deleted.
+	*/
+	FileSummaryByInstanceQuery = `
+		SELECT
+			FILE_NAME AS file_name,
+			EVENT_NAME AS event_name,
+			COUNT_READ AS read_count,
+			SUM_NUMBER_OF_BYTES_READ AS read_bytes,
+			SUM_TIMER_READ AS read_timer,
+			COUNT_WRITE AS write_count,
+			SUM_NUMBER_OF_BYTES_WRITE AS write_bytes,
+			SUM_TIMER_WRITE AS write_timer,
+			COUNT_MISC AS misc_count,
+			SUM_TIMER_MISC AS misc_timer
+		FROM
+			performance_schema.file_summary_by_instance
+		WHERE
+			COUNT_STAR > 0;
+	`
+
+	/*
+		FileSummaryByEventNameQuery: The counterpart of FileSummaryByInstanceQuery for each file event, such as the
+		InnoDB data files or the binary logs, which keeps the operations on the files deleted since the server started.
+	*/
+	FileSummaryByEventNameQuery = `
+		SELECT
+			EVENT_NAME AS event_name,
+			COUNT_READ AS read_count,
+			SUM_NUMBER_OF_BYTES_READ AS read_bytes,
+			SUM_TIMER_READ AS read_timer,
+			COUNT_WRITE AS write_count,
+			SUM_NUMBER_OF_BYTES_WRITE AS write_bytes,
+			SUM_TIMER_WRITE AS write_timer,
+			COUNT_MISC AS misc_count,
+			SUM_TIMER_MISC AS misc_timer
+		FROM
+			performance_schema.file_summary_by_event_name
+		WHERE
+			COUNT_STAR > 0;
+	`
+
+	// DataDirQuery: Retrieves the data directory of the server, where the files of the tables are kept in a directory per database.
+	DataDirQuery = `SELECT @@GLOBAL.datadir AS data_dir;`
+
+	/*
+		TableActivityQuery: Retrieves the rows fetched, inserted, updated and deleted in each table since the server
+		started, with the time spent on them, along with the waits for the table locks.
+
+		Arguments:
+		1. Excluded databases (STRING): A comma-separated list of database names to exclude from the results.
+	*/
+	TableActivityQuery = `
+		SELECT
+			io.OBJECT_SCHEMA AS database_name,
+			io.OBJECT_NAME AS table_name,
+			io.COUNT_FETCH AS fetch_count,
+			io.SUM_TIMER_FETCH AS fetch_timer,
+			io.COUNT_INSERT AS insert_count,
+			io.SUM_TIMER_INSERT AS insert_timer,
+			io.COUNT_UPDATE AS update_count,
+			io.SUM_TIMER_UPDATE AS update_timer,
+			io.COUNT_DELETE AS delete_count,
+			io.SUM_TIMER_DELETE AS delete_timer,
+			COALESCE(lk.COUNT_STAR, 0) AS lock_wait_count,
+			COALESCE(lk.SUM_TIMER_WAIT, 0) AS lock_wait_timer
+		FROM
+			performance_schema.table_io_waits_summary_by_table io
+		LEFT JOIN
+			performance_schema.table_lock_waits_summary_by_table lk
+			ON lk.OBJECT_TYPE = io.OBJECT_TYPE
+			AND lk.OBJECT_SCHEMA = io.OBJECT_SCHEMA
+			AND lk.OBJECT_NAME = io.OBJECT_NAME
+		WHERE
+			io.OBJECT_TYPE = 'TABLE'
+			AND io.OBJECT_SCHEMA NOT IN (?)
+			AND (io.COUNT_STAR > 0 OR lk.COUNT_STAR > 0);
+	`
+
+	/*
+		BlockingSessionsQuery: Identifies and details current database transactions that are blocked by others.
+		This query provides information about blocked and blocking transactions, including their execution time
+		and queries involved. It is vital for detecting deadlocks or contention issues, helping in resolving
+		immediate blocking problems and planning long-term query and index optimizations to reduce this
+		occurrence. All of the blocked/blocking pairs are returned, as the whole lock-wait graph is needed to find the
+		root blocker of each chain, and the excluded databases and the limit only apply to the reported sessions.
+	*/
 	BlockingSessionsQuery = `
 		SELECT 
                       r.trx_id AS blocked_txn_id,
@@ -299,11 +603,303 @@ const (
                   JOIN 
                       performance_schema.events_statements_summary_by_digest es_blocking 
                       ON esc_blocking.DIGEST = es_blocking.DIGEST
-				  WHERE
-					  wt.PROCESSLIST_DB IS NOT NULL
-					  AND wt.PROCESSLIST_DB NOT IN (?)
 				  ORDER BY 
-					  blocked_txn_start_time ASC
-				  LIMIT ?;
+					  blocked_txn_start_time ASC;
+	`
+
+	/*
+		BlockingSessionsQueryBelowVersion8: Identifies the blocked and blocking transactions on MySQL 5.7, where
+		performance_schema.data_lock_waits doesn't exist, from the InnoDB lock waits of information_schema. The sessions
+		are taken from the processlist, so the statement digests aren't reported, and their times have a precision of
+		a second. The statements are reported as they are run, and their literals are replaced when ingested. The database of the locked table is reported when the blocked session has no default database.
+		Like BlockingSessionsQuery, all of the pairs are returned.
+	*/
+	BlockingSessionsQueryBelowVersion8 = `
+		SELECT
+			r.trx_id AS blocked_txn_id,
+			r.trx_mysql_thread_id AS blocked_thread_id,
+			r.trx_mysql_thread_id AS blocked_pid,
+			wp.HOST AS blocked_host,
+			COALESCE(wp.DB, SUBSTRING_INDEX(REPLACE(l.lock_table, '` + "`" + `', ''), '.', 1)) AS database_name,
+			wp.STATE AS blocked_status,
+			b.trx_id AS blocking_txn_id,
+			b.trx_mysql_thread_id AS blocking_thread_id,
+			b.trx_mysql_thread_id AS blocking_pid,
+			bp.HOST AS blocking_host,
+			r.trx_query AS blocked_query,
+			b.trx_query AS blocking_query,
+			bp.STATE AS blocking_status,
+			wp.TIME * 1000 AS blocked_query_time_ms,
+			bp.TIME * 1000 AS blocking_query_time_ms,
+			DATE_FORMAT(CONVERT_TZ(r.trx_started, @@session.time_zone, '+00:00'), '%Y-%m-%dT%H:%i:%sZ') AS blocked_txn_start_time,
+			DATE_FORMAT(CONVERT_TZ(b.trx_started, @@session.time_zone, '+00:00'), '%Y-%m-%dT%H:%i:%sZ') AS blocking_txn_start_time,
+			DATE_FORMAT(UTC_TIMESTAMP(), '%Y-%m-%dT%H:%i:%sZ') AS collection_timestamp
+		FROM information_schema.innodb_lock_waits w
+		JOIN information_schema.innodb_locks l ON l.lock_id = w.requested_lock_id
+		JOIN information_schema.innodb_trx r ON r.trx_id = w.requesting_trx_id
+		JOIN information_schema.innodb_trx b ON b.trx_id = w.blocking_trx_id
+		JOIN information_schema.PROCESSLIST wp ON wp.ID = r.trx_mysql_thread_id
+		JOIN information_schema.PROCESSLIST bp ON bp.ID = b.trx_mysql_thread_id
+		ORDER BY blocked_txn_start_time ASC;
+	`
+
+	/*
+		BlockingSessionsQueryForMariaDB: The counterpart of BlockingSessionsQueryBelowVersion8 for MariaDB, whose
+		processlist reports the times of the sessions in milliseconds.
+	*/
+	BlockingSessionsQueryForMariaDB = `
+		SELECT
+			r.trx_id AS blocked_txn_id,
+			r.trx_mysql_thread_id AS blocked_thread_id,
+			r.trx_mysql_thread_id AS blocked_pid,
+			wp.HOST AS blocked_host,
+			COALESCE(wp.DB, SUBSTRING_INDEX(REPLACE(l.lock_table, '` + "`" + `', ''), '.', 1)) AS database_name,
+			wp.STATE AS blocked_status,
+			b.trx_id AS blocking_txn_id,
+			b.trx_mysql_thread_id AS blocking_thread_id,
+			b.trx_mysql_thread_id AS blocking_pid,
+			bp.HOST AS blocking_host,
+			r.trx_query AS blocked_query,
+			b.trx_query AS blocking_query,
+			bp.STATE AS blocking_status,
+			ROUND(wp.TIME_MS, 3) AS blocked_query_time_ms,
+			ROUND(bp.TIME_MS, 3) AS blocking_query_time_ms,
+			DATE_FORMAT(CONVERT_TZ(r.trx_started, @@session.time_zone, '+00:00'), '%Y-%m-%dT%H:%i:%sZ') AS blocked_txn_start_time,
+			DATE_FORMAT(CONVERT_TZ(b.trx_started, @@session.time_zone, '+00:00'), '%Y-%m-%dT%H:%i:%sZ') AS blocking_txn_start_time,
+			DATE_FORMAT(UTC_TIMESTAMP(), '%Y-%m-%dT%H:%i:%sZ') AS collection_timestamp
+		FROM information_schema.innodb_lock_waits w
+		JOIN information_schema.innodb_locks l ON l.lock_id = w.requested_lock_id
+		JOIN information_schema.innodb_trx r ON r.trx_id = w.requesting_trx_id
+		JOIN information_schema.innodb_trx b ON b.trx_id = w.blocking_trx_id
+		JOIN information_schema.PROCESSLIST wp ON wp.ID = r.trx_mysql_thread_id
+		JOIN information_schema.PROCESSLIST bp ON bp.ID = b.trx_mysql_thread_id
+		ORDER BY blocked_txn_start_time ASC;
+	`
+
+	/*
+		MetadataLocksQuery: Identifies the sessions waiting for a metadata lock, e.g. an ALTER TABLE waiting for a long
+		running SELECT to release its shared lock on the table, together with the other sessions holding or waiting for
+		a lock on the same object. Whether the locks of the other sessions actually conflict with the waiting lock is
+		decided afterwards from the lock types, so the results aren't limited here but once the conflicting locks are kept.
+		Requires the wait/lock/metadata/sql/mdl instrument, enabled by default since MySQL 8.0. The global locks, like the
+		one of FLUSH TABLES WITH READ LOCK, have no schema and are kept.
+
+		Arguments:
+		1. Excluded databases (STRING): A comma-separated list of database names to exclude from the results.
+	*/
+	MetadataLocksQuery = `
+		SELECT
+			w.OBJECT_TYPE AS object_type,
+			w.OBJECT_SCHEMA AS object_schema,
+			w.OBJECT_NAME AS object_name,
+			wt.THREAD_ID AS waiting_thread_id,
+			wt.PROCESSLIST_ID AS waiting_pid,
+			wt.PROCESSLIST_USER AS waiting_user,
+			wt.PROCESSLIST_HOST AS waiting_host,
+			w.LOCK_TYPE AS waiting_lock_type,
+			w.LOCK_DURATION AS waiting_lock_duration,
+			wesc.DIGEST AS waiting_query_id,
+			wesc.DIGEST_TEXT AS waiting_query,
+			ROUND(wesc.TIMER_WAIT / 1000000000, 3) AS waiting_query_time_ms,
+			bt.THREAD_ID AS blocking_thread_id,
+			bt.PROCESSLIST_ID AS blocking_pid,
+			bt.PROCESSLIST_USER AS blocking_user,
+			bt.PROCESSLIST_HOST AS blocking_host,
+			bt.PROCESSLIST_COMMAND AS blocking_command,
+			b.LOCK_TYPE AS blocking_lock_type,
+			b.LOCK_DURATION AS blocking_lock_duration,
+			b.LOCK_STATUS AS blocking_lock_status,
+			besc.DIGEST AS blocking_query_id,
+			besc.DIGEST_TEXT AS blocking_query,
+			ROUND(besc.TIMER_WAIT / 1000000000, 3) AS blocking_query_time_ms,
+			DATE_FORMAT(UTC_TIMESTAMP(), '%Y-%m-%dT%H:%i:%sZ') AS collection_timestamp
+		FROM performance_schema.metadata_locks w
+		JOIN performance_schema.metadata_locks b
+			ON b.OBJECT_TYPE = w.OBJECT_TYPE
+			AND b.OBJECT_SCHEMA <=> w.OBJECT_SCHEMA
+			AND b.OBJECT_NAME <=> w.OBJECT_NAME
+			AND b.OWNER_THREAD_ID <> w.OWNER_THREAD_ID
+			AND b.LOCK_STATUS IN ('GRANTED', 'PENDING')
+		JOIN performance_schema.threads wt ON wt.THREAD_ID = w.OWNER_THREAD_ID
+		JOIN performance_schema.threads bt ON bt.THREAD_ID = b.OWNER_THREAD_ID
+		LEFT JOIN performance_schema.events_statements_current wesc ON wesc.THREAD_ID = wt.THREAD_ID
+		LEFT JOIN performance_schema.events_statements_current besc ON besc.THREAD_ID = bt.THREAD_ID
+		WHERE w.LOCK_STATUS = 'PENDING'
+			AND (w.OBJECT_SCHEMA IS NULL OR w.OBJECT_SCHEMA NOT IN (?))
+		ORDER BY waiting_query_time_ms DESC;
+	`
+
+	/*
+		LongRunningTransactionsQuery: Identifies the open InnoDB transactions which have been running for long, or whose
+		session sits idle (in Sleep) while keeping the transaction open, even when they don't block anyone yet. These
+		transactions hold their locks and prevent the purge of the undo logs until they end. The rows modified by the
+		transaction tell how much would be rolled back if it were killed, and its undo log entries are taken afterwards
+		from the InnoDB status. The last statement of an idle session is the one it ran last. The statement is the one
+		being run, with its literals, when it has no digest, so the literals are replaced when ingested.
+
+		Arguments:
+		1. Transaction age threshold (INT): The age in seconds from which a transaction is reported.
+		2. Idle threshold (INT): The idle time in seconds from which the session of a transaction is reported.
+		3. Excluded databases (STRING): A comma-separated list of database names to exclude from the results.
+		4. Limit (INT): The maximum number of results to return.
+	*/
+	LongRunningTransactionsQuery = `
+		SELECT
+			t.trx_id AS trx_id,
+			t.trx_mysql_thread_id AS pid,
+			th.THREAD_ID AS thread_id,
+			th.PROCESSLIST_USER AS user,
+			th.PROCESSLIST_HOST AS host,
+			th.PROCESSLIST_DB AS database_name,
+			th.PROCESSLIST_COMMAND AS command,
+			t.trx_state AS trx_state,
+			t.trx_operation_state AS trx_operation_state,
+			t.trx_isolation_level AS trx_isolation_level,
+			IF(th.PROCESSLIST_COMMAND = 'Sleep', 'idle_in_transaction', 'active') AS session_state,
+			DATE_FORMAT(CONVERT_TZ(t.trx_started, @@session.time_zone, '+00:00'), '%Y-%m-%dT%H:%i:%sZ') AS trx_start_time,
+			TIMESTAMPDIFF(SECOND, t.trx_started, NOW()) AS trx_age_sec,
+			IF(th.PROCESSLIST_COMMAND = 'Sleep', th.PROCESSLIST_TIME, 0) AS idle_time_sec,
+			t.trx_rows_locked AS rows_locked,
+			t.trx_lock_structs AS lock_structs,
+			t.trx_tables_locked AS tables_locked,
+			t.trx_rows_modified AS rows_modified,
+			esc.DIGEST AS last_query_id,
+			COALESCE(esc.DIGEST_TEXT, t.trx_query) AS last_query,
+			DATE_FORMAT(UTC_TIMESTAMP(), '%Y-%m-%dT%H:%i:%sZ') AS collection_timestamp
+		FROM information_schema.innodb_trx t
+		JOIN performance_schema.threads th ON th.PROCESSLIST_ID = t.trx_mysql_thread_id
+		LEFT JOIN performance_schema.events_statements_current esc ON esc.THREAD_ID = th.THREAD_ID
+		WHERE (TIMESTAMPDIFF(SECOND, t.trx_started, NOW()) >= ?
+				OR (th.PROCESSLIST_COMMAND = 'Sleep' AND th.PROCESSLIST_TIME >= ?))
+			AND (th.PROCESSLIST_DB IS NULL OR th.PROCESSLIST_DB NOT IN (?))
+		ORDER BY t.trx_started ASC
+		LIMIT ?;
+	`
+
+	/*
+		AccountsQuery: Reports the connections and the statement counters of every account (user@host).
+		The connections of the account are reported along with the ones of its user and its host, which helps
+		identifying the application user or the client host causing a connection storm. The total connections and
+		the statement counters are totals since the server started, aggregated from the statement events of the
+		account, which are subtracted from the ones of the previous execution to report the activity of the interval.
+
+		Arguments:
+		1. Excluded users (STRING): A comma-separated list of user names to exclude from the results.
+	*/
+	AccountsQuery = `
+		SELECT
+			a.USER AS user,
+			a.HOST AS host,
+			a.CURRENT_CONNECTIONS AS current_connections,
+			a.TOTAL_CONNECTIONS AS total_connections,
+			COALESCE(u.CURRENT_CONNECTIONS, 0) AS user_current_connections,
+			COALESCE(u.TOTAL_CONNECTIONS, 0) AS user_total_connections,
+			COALESCE(h.CURRENT_CONNECTIONS, 0) AS host_current_connections,
+			COALESCE(h.TOTAL_CONNECTIONS, 0) AS host_total_connections,
+			COALESCE(s.statement_count, 0) AS statement_count,
+			COALESCE(s.statement_error_count, 0) AS statement_error_count,
+			COALESCE(s.statement_timer, 0) AS statement_timer,
+			COALESCE(s.rows_examined, 0) AS rows_examined
+		FROM performance_schema.accounts a
+		LEFT JOIN performance_schema.users u ON u.USER = a.USER
+		LEFT JOIN performance_schema.hosts h ON h.HOST = a.HOST
+		LEFT JOIN (
+			SELECT
+				USER,
+				HOST,
+				SUM(COUNT_STAR) AS statement_count,
+				SUM(SUM_ERRORS) AS statement_error_count,
+				SUM(SUM_TIMER_WAIT) AS statement_timer,
+				SUM(SUM_ROWS_EXAMINED) AS rows_examined
+			FROM performance_schema.events_statements_summary_by_account_by_event_name
+			GROUP BY USER, HOST
+		) s ON s.USER = a.USER AND s.HOST = a.HOST
+		WHERE a.USER IS NOT NULL
+			AND a.HOST IS NOT NULL
+			AND a.USER NOT IN (?);
 	`
+
+	/*
+		QueryHistorySamplesQuery: Retrieves the most recent texts of the statements of a digest kept in the statements
+		history, run on the default database of the connection. When the sample text of a digest holds placeholders,
+		these texts are searched for one with literals, which can be explained as it is.
+
+		Arguments:
+		1. Digest (STRING): The digest of the query.
+		2. Limit (INT): The maximum number of results to return.
+	*/
+	QueryHistorySamplesQuery = `
+		SELECT
+			SQL_TEXT AS sql_text
+		FROM performance_schema.events_statements_history_long
+		WHERE DIGEST = ?
+			AND CURRENT_SCHEMA = DATABASE()
+			AND SQL_TEXT IS NOT NULL
+		ORDER BY TIMER_START DESC
+		LIMIT ?;
+	`
+
+	/*
+		PreparedStatementExecutionsQuery: Retrieves the most recent EXECUTE statements run by the threads which prepared
+		a statement with the given text. Only the statements prepared with PREPARE are executed with user variables,
+		whose values can be read back, so the statements prepared through the binary protocol are not returned.
+
+		Arguments:
+		1. Query text (STRING): The text of the prepared statement, with its placeholders.
+		2. Limit (INT): The maximum number of results to return.
+	*/
+	PreparedStatementExecutionsQuery = `
+		SELECT
+			psi.OWNER_THREAD_ID AS thread_id,
+			psi.STATEMENT_NAME AS statement_name,
+			h.SQL_TEXT AS execute_text
+		FROM performance_schema.prepared_statements_instances psi
+		JOIN performance_schema.events_statements_history_long h
+			ON h.THREAD_ID = psi.OWNER_THREAD_ID
+			AND h.EVENT_NAME = 'statement/sql/execute_sql'
+		WHERE psi.SQL_TEXT = ?
+			AND psi.STATEMENT_NAME IS NOT NULL
+		ORDER BY h.TIMER_START DESC
+		LIMIT ?;
+	`
+
+	/*
+		UserVariablesQuery: Retrieves the user variables of a thread, such as the ones its prepared statements are executed with.
+
+		Arguments:
+		1. Thread ID (INT): The ID of the thread.
+	*/
+	UserVariablesQuery = `
+		SELECT
+			VARIABLE_NAME AS variable_name,
+			VARIABLE_VALUE AS variable_value
+		FROM performance_schema.user_variables_by_thread
+		WHERE THREAD_ID = ?;
+	`
+
+	/*
+		ColumnTypesQuery: Retrieves the data types of the columns with the given names in the default database of the
+		connection. The types are used to give type-appropriate values to the placeholders the columns are compared with.
+
+		Arguments:
+		1. Column names (STRING): A comma-separated list of column names.
+	*/
+	ColumnTypesQuery = `
+		SELECT
+			TABLE_NAME AS table_name,
+			COLUMN_NAME AS column_name,
+			DATA_TYPE AS data_type,
+			COLUMN_TYPE AS column_type
+		FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE()
+			AND COLUMN_NAME IN (?)
+		ORDER BY TABLE_NAME, ORDINAL_POSITION;
+	`
+
+	/*
+		InnoDBStatusQuery: Reports the state of the InnoDB engine as text, including the latest deadlock it detected
+		and resolved and the undo log entries of the open transactions, which are not kept in any table. It requires
+		the PROCESS privilege.
+	*/
+	InnoDBStatusQuery = `SHOW ENGINE INNODB STATUS;`
 )
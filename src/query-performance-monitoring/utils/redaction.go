@@ -0,0 +1,385 @@
+package utils
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+)
+
+// Redaction policies of the text attributes reported by query performance monitoring.
+const (
+	// RedactionPolicyLiterals replaces every literal of the SQL text by a placeholder and masks the sensitive values of any other text.
+	RedactionPolicyLiterals = "literals"
+	// RedactionPolicySensitive keeps the literals of the SQL text, masking only emails, card numbers and the values of the configured columns.
+	RedactionPolicySensitive = "sensitive"
+	// RedactionPolicyNone reports the text as it is, which is the default.
+	RedactionPolicyNone = "none"
+
+	/*
+		Values of the `redact` tag of the model fields. Attributes without the tag are redacted as free text. The statements
+		are taken as they were run, unlike the digest texts, so their literals are replaced whatever the policy.
+	*/
+	redactSQL       = "sql"
+	redactStatement = "statement"
+	redactNone      = "-"
+
+	literalPlaceholder = "?"
+	redactedValue      = "[redacted]"
+)
+
+var (
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	cardNumberPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+
+	// columnComparisonPattern matches a column compared with, or assigned, a value, e.g. `u.password = 'secret'` or `ssn IN (1, 2)`.
+	columnComparisonPattern = regexp.MustCompile("(?i)([\\w$`]+)(\\s*(?:<=>|<>|!=|<=|>=|=|<|>|\\bNOT\\s+LIKE\\b|\\bLIKE\\b|\\bNOT\\s+IN\\b|\\bIN\\b)\\s*)" +
+		`('(?:[^'\\]|\\.|'')*'|"(?:[^"\\]|\\.|"")*"|0x[0-9a-fA-F]+|[-+]?\d+(?:\.\d+)?(?:[eE][-+]?\d+)?|\([^()]*\))`)
+)
+
+/*
+explainSQLKeys are the keys of the EXPLAIN JSON output whose values are SQL expressions, which hold the literals
+of the explained query. The rest of the values are identifiers, costs or access types, which are redacted as free text.
+*/
+var explainSQLKeys = map[string]bool{
+	"attached_condition":  true,
+	"index_condition":     true,
+	"having_condition":    true,
+	"original_condition":  true,
+	"resulting_condition": true,
+	"lookup_condition":    true,
+	"condition":           true,
+	"expression":          true,
+	"query":               true,
+}
+
+// Redactor masks the literals and sensitive values of the text attributes according to the redaction policy.
+type Redactor struct {
+	policy         string
+	redactedColumn []*regexp.Regexp
+}
+
+/*
+NewRedactor creates the redactor for the redaction policy and the redacted columns of the arguments.
+The text is reported as it is when no policy is configured. An unknown policy falls back to RedactionPolicyLiterals,
+the strictest one, and invalid column patterns are ignored.
+*/
+func NewRedactor(args arguments.ArgumentList) *Redactor {
+	policy := strings.ToLower(strings.TrimSpace(args.QueryMonitoringRedactionPolicy))
+	switch policy {
+	case RedactionPolicyLiterals, RedactionPolicySensitive, RedactionPolicyNone:
+	case "":
+		policy = RedactionPolicyNone
+	default:
+		log.Warn("Unknown query text redaction policy %q, using %q", policy, RedactionPolicyLiterals)
+		policy = RedactionPolicyLiterals
+	}
+
+	redactor := &Redactor{policy: policy}
+
+	var columnPatterns []string
+	if args.QueryMonitoringRedactedColumns != "" {
+		if err := json.Unmarshal([]byte(args.QueryMonitoringRedactedColumns), &columnPatterns); err != nil {
+			log.Warn("Failed to parse redacted columns list: %v", err)
+		}
+	}
+	for _, pattern := range columnPatterns {
+		re, err := regexp.Compile("(?i)^(?:" + pattern + ")$")
+		if err != nil {
+			log.Warn("Ignoring invalid redacted column pattern %q: %v", pattern, err)
+			continue
+		}
+		redactor.redactedColumn = append(redactor.redactedColumn, re)
+	}
+	return redactor
+}
+
+// RedactSQL redacts a SQL statement, such as a query sample text or a digest text.
+func (r *Redactor) RedactSQL(sql string) string {
+	switch r.policy {
+	case RedactionPolicyNone:
+		return sql
+	case RedactionPolicyLiterals:
+		return r.maskSensitive(ObfuscateSQL(sql))
+	default:
+		return r.maskSensitive(r.maskColumns(sql))
+	}
+}
+
+/*
+RedactStatement redacts a statement as it was run, such as a query sample text, whose literals are always replaced,
+even when no policy is configured.
+*/
+func (r *Redactor) RedactStatement(sql string) string {
+	return r.maskSensitive(ObfuscateSQL(sql))
+}
+
+// RedactText redacts a free text attribute, which may hold sensitive values but is not a SQL statement.
+func (r *Redactor) RedactText(text string) string {
+	if r.policy == RedactionPolicyNone {
+		return text
+	}
+	return r.maskSensitive(text)
+}
+
+// redactField redacts the value of a model field according to its `redact` tag.
+func (r *Redactor) redactField(value string, tag string) string {
+	switch tag {
+	case redactNone:
+		return value
+	case redactSQL:
+		return r.RedactSQL(value)
+	case redactStatement:
+		return r.RedactStatement(value)
+	default:
+		return r.RedactText(value)
+	}
+}
+
+// RedactJSON redacts the string values of a JSON document, such as the output of EXPLAIN FORMAT=JSON.
+// The values of the keys holding SQL expressions are redacted as SQL, and the rest as free text.
+func (r *Redactor) RedactJSON(jsonString string) (string, error) {
+	if r.policy == RedactionPolicyNone {
+		return jsonString, nil
+	}
+
+	var jsonData interface{}
+	if err := json.Unmarshal([]byte(jsonString), &jsonData); err != nil {
+		return "", err
+	}
+
+	redacted, err := json.Marshal(r.redactJSONValue(jsonData, false))
+	if err != nil {
+		return "", err
+	}
+	return string(redacted), nil
+}
+
+func (r *Redactor) redactJSONValue(data interface{}, isSQL bool) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = r.redactJSONValue(v, explainSQLKeys[k])
+		}
+		return value
+	case []interface{}:
+		for i, v := range value {
+			value[i] = r.redactJSONValue(v, isSQL)
+		}
+		return value
+	case string:
+		if isSQL {
+			return r.RedactSQL(value)
+		}
+		return r.RedactText(value)
+	default:
+		return value
+	}
+}
+
+// maskColumns replaces the values compared with, or assigned to, the redacted columns.
+func (r *Redactor) maskColumns(sql string) string {
+	if len(r.redactedColumn) == 0 {
+		return sql
+	}
+	return columnComparisonPattern.ReplaceAllStringFunc(sql, func(match string) string {
+		groups := columnComparisonPattern.FindStringSubmatch(match)
+		column := strings.Trim(groups[1], "`")
+		if dot := strings.LastIndex(column, "."); dot >= 0 {
+			column = strings.Trim(column[dot+1:], "`")
+		}
+		for _, re := range r.redactedColumn {
+			if re.MatchString(column) {
+				return groups[1] + groups[2] + literalPlaceholder
+			}
+		}
+		return match
+	})
+}
+
+// maskSensitive masks the emails and the card numbers of a text.
+func (r *Redactor) maskSensitive(text string) string {
+	text = emailPattern.ReplaceAllString(text, redactedValue)
+	return cardNumberPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if isLuhnValid(match) {
+			return redactedValue
+		}
+		return match
+	})
+}
+
+// isLuhnValid checks the digits of a number with the Luhn algorithm, which every card number satisfies.
+func isLuhnValid(number string) bool {
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+/*
+ObfuscateSQL replaces every literal of a SQL statement (strings, numbers, hexadecimal and bit values) by a placeholder
+and removes its comments, keeping the identifiers, keywords and operators. Statements already normalized by the server,
+such as digest texts, are kept as they are.
+*/
+func ObfuscateSQL(sql string) string {
+	var out strings.Builder
+	out.Grow(len(sql))
+
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"':
+			i = skipQuoted(sql, i, c)
+			out.WriteString(literalPlaceholder)
+		case c == '`':
+			end := skipQuoted(sql, i, c)
+			out.WriteString(sql[i:end])
+			i = end
+		case c == '#' || (c == '-' && strings.HasPrefix(sql[i:], "-- ")):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 4
+			}
+		case (c == 'x' || c == 'X' || c == 'b' || c == 'B') && i+1 < len(sql) && sql[i+1] == '\'' && !isIdentifierChar(previousChar(sql, i)):
+			i = skipQuoted(sql, i+1, '\'')
+			out.WriteString(literalPlaceholder)
+		case isDigit(c) && !isIdentifierChar(previousChar(sql, i)):
+			i = skipNumber(sql, i)
+			out.WriteString(literalPlaceholder)
+		case c == '.' && i+1 < len(sql) && isDigit(sql[i+1]) && !isIdentifierChar(previousChar(sql, i)):
+			i = skipNumber(sql, i)
+			out.WriteString(literalPlaceholder)
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.String()
+}
+
+/*
+SplitPlaceholders splits a SQL statement around its placeholders, the question marks which are not within a literal,
+an identifier or a comment. A statement with n placeholders is split into n+1 parts.
+*/
+func SplitPlaceholders(sql string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(sql, i, c)
+		case c == '#' || (c == '-' && strings.HasPrefix(sql[i:], "-- ")):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 4
+			}
+		case c == '?':
+			parts = append(parts, sql[start:i])
+			i++
+			start = i
+		default:
+			i++
+		}
+	}
+	return append(parts, sql[start:])
+}
+
+// HasPlaceholders checks if a SQL statement has placeholders, which have to be given values before it is explained.
+func HasPlaceholders(sql string) bool {
+	return len(SplitPlaceholders(sql)) > 1
+}
+
+// skipQuoted returns the position after the quoted text starting at start, honouring doubled quotes and backslash escapes.
+func skipQuoted(sql string, start int, quote byte) int {
+	for i := start + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+// skipNumber returns the position after the number starting at start, including decimals, exponents and hexadecimal numbers.
+func skipNumber(sql string, start int) int {
+	i := start
+	if strings.HasPrefix(sql[i:], "0x") || strings.HasPrefix(sql[i:], "0X") {
+		i += 2
+		for i < len(sql) && isHexDigit(sql[i]) {
+			i++
+		}
+		return i
+	}
+	for i < len(sql) && (isDigit(sql[i]) || sql[i] == '.') {
+		i++
+	}
+	if i < len(sql) && (sql[i] == 'e' || sql[i] == 'E') {
+		j := i + 1
+		if j < len(sql) && (sql[j] == '+' || sql[j] == '-') {
+			j++
+		}
+		if j < len(sql) && isDigit(sql[j]) {
+			i = j
+			for i < len(sql) && isDigit(sql[i]) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+func previousChar(sql string, i int) byte {
+	if i == 0 {
+		return ' '
+	}
+	return sql[i-1]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func isIdentifierChar(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_' || c == '$' || c >= 0x80
+}
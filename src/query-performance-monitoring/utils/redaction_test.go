@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{"StringsAndNumbers", "SELECT * FROM users WHERE name = 'O''Brien' AND age > 42", "SELECT * FROM users WHERE name = ? AND age > ?"},
+		{"EscapedQuotes", `SELECT * FROM t WHERE a = "say \"hi\"" AND b = 'it\'s'`, "SELECT * FROM t WHERE a = ? AND b = ?"},
+		{"DecimalsAndExponents", "SELECT 1.5, .25, 3e10, -7 FROM dual", "SELECT ?, ?, ?, -? FROM dual"},
+		{"HexAndBitLiterals", "SELECT 0xFF, x'0A1B', B'101' FROM dual", "SELECT ?, ?, ? FROM dual"},
+		{"IdentifiersWithDigits", "SELECT col1, `order 2` FROM t2 JOIN db1.t3 ON t2.id = t3.id", "SELECT col1, `order 2` FROM t2 JOIN db1.t3 ON t2.id = t3.id"},
+		{"InList", "DELETE FROM orders WHERE id IN (1, 2, 3)", "DELETE FROM orders WHERE id IN (?, ?, ?)"},
+		{"Comments", "SELECT /* secret 123 */ a FROM t -- trailing 'x'\nWHERE b = 1 # 456", "SELECT  a FROM t \nWHERE b = ? "},
+		{"DigestText", "SELECT * FROM `users` WHERE `id` = ?", "SELECT * FROM `users` WHERE `id` = ?"},
+		{"UnterminatedString", "SELECT 'abc", "SELECT ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ObfuscateSQL(tt.sql))
+		})
+	}
+}
+
+func TestSplitPlaceholders(t *testing.T) {
+	assert.Equal(t, []string{"SELECT * FROM t WHERE a = ", " AND b IN (", ", ", ")"}, SplitPlaceholders("SELECT * FROM t WHERE a = ? AND b IN (?, ?)"))
+	// Question marks within literals, identifiers and comments are not placeholders
+	assert.Equal(t, []string{"SELECT '?', `a?` FROM t /* ? */ WHERE b = ", " -- ?"}, SplitPlaceholders("SELECT '?', `a?` FROM t /* ? */ WHERE b = ? -- ?"))
+	assert.True(t, HasPlaceholders("SELECT * FROM t LIMIT ?"))
+	assert.False(t, HasPlaceholders("SELECT * FROM t WHERE a = 'why?'"))
+}
+
+func TestNewRedactor(t *testing.T) {
+	t.Run("DefaultPolicy", func(t *testing.T) {
+		// The text is reported as it is unless a policy is configured
+		assert.Equal(t, RedactionPolicyNone, NewRedactor(arguments.ArgumentList{}).policy)
+	})
+
+	t.Run("UnknownPolicy", func(t *testing.T) {
+		redactor := NewRedactor(arguments.ArgumentList{QueryMonitoringRedactionPolicy: "everything"})
+		assert.Equal(t, RedactionPolicyLiterals, redactor.policy)
+	})
+
+	t.Run("InvalidColumnPatterns", func(t *testing.T) {
+		redactor := NewRedactor(arguments.ArgumentList{
+			QueryMonitoringRedactionPolicy: " Sensitive ",
+			QueryMonitoringRedactedColumns: `["password", "(unclosed"]`,
+		})
+		assert.Equal(t, RedactionPolicySensitive, redactor.policy)
+		assert.Len(t, redactor.redactedColumn, 1)
+	})
+}
+
+func TestRedactSQL(t *testing.T) {
+	sql := "UPDATE users SET password = 'hunter2', api_token = 'abc' WHERE email = 'jane@example.com' AND card = '4111 1111 1111 1111' AND id = 7"
+
+	tests := []struct {
+		name     string
+		args     arguments.ArgumentList
+		expected string
+	}{
+		{
+			"Literals",
+			arguments.ArgumentList{QueryMonitoringRedactionPolicy: RedactionPolicyLiterals},
+			"UPDATE users SET password = ?, api_token = ? WHERE email = ? AND card = ? AND id = ?",
+		},
+		{
+			"Sensitive",
+			arguments.ArgumentList{QueryMonitoringRedactionPolicy: RedactionPolicySensitive, QueryMonitoringRedactedColumns: `["password", ".*_token"]`},
+			"UPDATE users SET password = ?, api_token = ? WHERE email = '[redacted]' AND card = '[redacted]' AND id = 7",
+		},
+		{
+			"None",
+			arguments.ArgumentList{QueryMonitoringRedactionPolicy: RedactionPolicyNone, QueryMonitoringRedactedColumns: `["password"]`},
+			sql,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NewRedactor(tt.args).RedactSQL(sql))
+		})
+	}
+}
+
+func TestRedactSQLColumnPatterns(t *testing.T) {
+	redactor := NewRedactor(arguments.ArgumentList{
+		QueryMonitoringRedactionPolicy: RedactionPolicySensitive,
+		QueryMonitoringRedactedColumns: `["ssn"]`,
+	})
+
+	assert.Equal(t, "SELECT * FROM p WHERE p.`ssn` IN ? AND ssn_hash = 'x'", redactor.RedactSQL("SELECT * FROM p WHERE p.`ssn` IN ('1', '2') AND ssn_hash = 'x'"))
+	assert.Equal(t, "SELECT * FROM p WHERE SSN LIKE ?", redactor.RedactSQL("SELECT * FROM p WHERE SSN LIKE '123-%'"))
+}
+
+func TestRedactText(t *testing.T) {
+	redactor := NewRedactor(arguments.ArgumentList{QueryMonitoringRedactionPolicy: RedactionPolicyLiterals})
+
+	assert.Equal(t, "user [redacted] paid with [redacted]", redactor.RedactText("user john.doe+test@mail.example.org paid with 5500-0000-0000-0004"))
+	// Numbers failing the Luhn check are not card numbers
+	assert.Equal(t, "order 1234567890123 shipped", redactor.RedactText("order 1234567890123 shipped"))
+	// Text attributes are not SQL, so their numbers are kept
+	assert.Equal(t, "idle in transaction 42", redactor.RedactText("idle in transaction 42"))
+}
+
+func TestRedactJSON(t *testing.T) {
+	redactor := NewRedactor(arguments.ArgumentList{QueryMonitoringRedactionPolicy: RedactionPolicyLiterals})
+	input := `{"query_block":{"table":{"table_name":"users","attached_condition":"(` + "`db`.`users`.`email`" + ` = 'jane@example.com')","cost_info":{"read_cost":"1.25"}},"message":"contact admin@example.com"}}`
+
+	output, err := redactor.RedactJSON(input)
+	assert.NoError(t, err)
+
+	var plan map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(output), &plan))
+	queryBlock := plan["query_block"].(map[string]interface{})
+	table := queryBlock["table"].(map[string]interface{})
+	assert.Equal(t, "users", table["table_name"])
+	assert.Equal(t, "(`db`.`users`.`email` = ?)", table["attached_condition"])
+	assert.Equal(t, "1.25", table["cost_info"].(map[string]interface{})["read_cost"])
+	assert.Equal(t, "contact [redacted]", queryBlock["message"])
+
+	_, err = redactor.RedactJSON(`{"invalid":`)
+	assert.Error(t, err)
+
+	unchanged, err := NewRedactor(arguments.ArgumentList{QueryMonitoringRedactionPolicy: RedactionPolicyNone}).RedactJSON(input)
+	assert.NoError(t, err)
+	assert.Equal(t, input, unchanged)
+
+	// The plan isn't parsed again when no policy is configured
+	unchanged, err = NewRedactor(arguments.ArgumentList{}).RedactJSON(`{"invalid":`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"invalid":`, unchanged)
+}
+
+func TestProcessModelRedaction(t *testing.T) {
+	type TestModel struct {
+		QueryID   *string `metric_name:"query_id" source_type:"attribute" redact:"-"`
+		QueryText *string `metric_name:"query_text" source_type:"attribute" redact:"sql"`
+		Host      string  `metric_name:"host" source_type:"attribute"`
+	}
+
+	i, _ := integration.New("test", "1.0.0")
+	entity := i.LocalEntity()
+
+	queryID := "4111111111111111"
+	queryText := "SELECT * FROM users WHERE id = 10"
+	model := TestModel{QueryID: &queryID, QueryText: &queryText, Host: "app@example.com"}
+
+	args := arguments.ArgumentList{QueryMonitoringRedactionPolicy: RedactionPolicyLiterals}
+	err := processModel(model, entity, "testEvent", args, NewRedactor(args))
+	assert.NoError(t, err)
+
+	metrics := entity.Metrics[len(entity.Metrics)-1].Metrics
+	assert.Equal(t, queryID, metrics["query_id"])
+	assert.Equal(t, "SELECT * FROM users WHERE id = ?", metrics["query_text"])
+	assert.Equal(t, "[redacted]", metrics["host"])
+
+	// The attributes are reported as they are when no policy is configured
+	err = processModel(model, entity, "testEvent", arguments.ArgumentList{}, NewRedactor(arguments.ArgumentList{}))
+	assert.NoError(t, err)
+
+	metrics = entity.Metrics[len(entity.Metrics)-1].Metrics
+	assert.Equal(t, queryText, metrics["query_text"])
+	assert.Equal(t, "app@example.com", metrics["host"])
+}
+
+func TestRedactStatement(t *testing.T) {
+	statement := "UPDATE users SET password = 'secret' WHERE id = 10"
+	for _, policy := range []string{RedactionPolicyNone, RedactionPolicySensitive, RedactionPolicyLiterals} {
+		redactor := NewRedactor(arguments.ArgumentList{QueryMonitoringRedactionPolicy: policy})
+		assert.Equal(t, "UPDATE users SET password = ? WHERE id = ?", redactor.redactField(statement, redactStatement), policy)
+	}
+}
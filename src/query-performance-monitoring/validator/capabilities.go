@@ -0,0 +1,122 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+/*
+Capabilities holds the features of a server the query performance monitoring relies on, which the collectors and
+their queries are chosen from. The metrics of the features a server lacks are left unset instead of failing the run.
+*/
+type Capabilities struct {
+	// Version is the version reported by the server
+	Version string
+	// MariaDB tells if the server is a MariaDB one
+	MariaDB bool
+	// PerformanceSchema tells if the statement, wait, lock and account instrumentation of performance_schema is collected
+	PerformanceSchema bool
+	// StatementCPUTime tells if the statement events report their CPU time, from MySQL 8.0.28
+	StatementCPUTime bool
+	// LatencyHistograms tells if the digests have latency histograms and quantiles, from MySQL 8.0
+	LatencyHistograms bool
+	// CommonTableExpressions tells if the queries can use WITH clauses, from MySQL 8.0
+	CommonTableExpressions bool
+	// DataLockWaits tells if the InnoDB lock waits are reported by performance_schema.data_lock_waits, from MySQL 8.0
+	DataLockWaits bool
+	// ExplainAnalyze tells if the queries can be profiled with EXPLAIN ANALYZE, from MySQL 8.0.18
+	ExplainAnalyze bool
+}
+
+// serverVersion is the numeric part of a version string, e.g. 8.0.36 for 8.0.36-log.
+type serverVersion struct {
+	major, minor, patch int
+}
+
+// versionPattern matches the numeric part at the start of a version string, where the patch number is optional.
+var versionPattern = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+
+/*
+capabilityTable lists the capabilities of each supported server, from the most recent version of each flavor. A server
+gets the capabilities of the first entry of its flavor whose minimum version it meets. MariaDB servers only report their
+blocking sessions and deadlocks, as their performance_schema is a former one of MySQL and is disabled by default.
+*/
+var capabilityTable = []struct {
+	mariaDB      bool
+	minVersion   serverVersion
+	capabilities Capabilities
+}{
+	{
+		minVersion: serverVersion{8, 0, 28},
+		capabilities: Capabilities{PerformanceSchema: true, StatementCPUTime: true, LatencyHistograms: true,
+			CommonTableExpressions: true, DataLockWaits: true, ExplainAnalyze: true},
+	},
+	{
+		minVersion:   serverVersion{8, 0, 18},
+		capabilities: Capabilities{PerformanceSchema: true, LatencyHistograms: true, CommonTableExpressions: true, DataLockWaits: true, ExplainAnalyze: true},
+	},
+	{
+		minVersion:   serverVersion{8, 0, 0},
+		capabilities: Capabilities{PerformanceSchema: true, LatencyHistograms: true, CommonTableExpressions: true, DataLockWaits: true},
+	},
+	{
+		minVersion:   serverVersion{5, 7, 0},
+		capabilities: Capabilities{PerformanceSchema: true},
+	},
+	{
+		mariaDB:      true,
+		minVersion:   serverVersion{5, 5, 0},
+		capabilities: Capabilities{MariaDB: true},
+	},
+}
+
+// GetCapabilities returns the capabilities of a server from its version, or ErrUnsupportedMySQLVersion when the server is not supported.
+func GetCapabilities(version string) (Capabilities, error) {
+	parsedVersion, err := parseServerVersion(version)
+	if err != nil {
+		return Capabilities{}, err
+	}
+
+	mariaDB := isMariaDB(version)
+	for _, entry := range capabilityTable {
+		if entry.mariaDB == mariaDB && !parsedVersion.lessThan(entry.minVersion) {
+			capabilities := entry.capabilities
+			capabilities.Version = version
+			return capabilities, nil
+		}
+	}
+	return Capabilities{}, fmt.Errorf("%w: MySQL version %s is not supported. Only version 5.7+ is supported", ErrUnsupportedMySQLVersion, version)
+}
+
+// parseServerVersion parses the numeric part of a version string.
+func parseServerVersion(version string) (serverVersion, error) {
+	match := versionPattern.FindStringSubmatch(version)
+	if match == nil {
+		return serverVersion{}, fmt.Errorf("%w: %s", ErrImproperlyFormattedVersion, version)
+	}
+
+	parts := make([]int, 0, len(match)-1)
+	for _, part := range match[1:] {
+		number := 0
+		if part != "" {
+			var err error
+			if number, err = strconv.Atoi(part); err != nil {
+				return serverVersion{}, fmt.Errorf("%w: %s", ErrImproperlyFormattedVersion, version)
+			}
+		}
+		parts = append(parts, number)
+	}
+	return serverVersion{major: parts[0], minor: parts[1], patch: parts[2]}, nil
+}
+
+// lessThan checks if the version is older than the other one.
+func (v serverVersion) lessThan(other serverVersion) bool {
+	if v.major != other.major {
+		return v.major < other.major
+	}
+	if v.minor != other.minor {
+		return v.minor < other.minor
+	}
+	return v.patch < other.patch
+}
@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected Capabilities
+	}{
+		{"9.1.0", Capabilities{PerformanceSchema: true, StatementCPUTime: true, LatencyHistograms: true, CommonTableExpressions: true, DataLockWaits: true, ExplainAnalyze: true}},
+		{"8.0.36-log", Capabilities{PerformanceSchema: true, StatementCPUTime: true, LatencyHistograms: true, CommonTableExpressions: true, DataLockWaits: true, ExplainAnalyze: true}},
+		{"8.0.23", Capabilities{PerformanceSchema: true, LatencyHistograms: true, CommonTableExpressions: true, DataLockWaits: true, ExplainAnalyze: true}},
+		{"8.0.17", Capabilities{PerformanceSchema: true, LatencyHistograms: true, CommonTableExpressions: true, DataLockWaits: true}},
+		{"5.7.44-log", Capabilities{PerformanceSchema: true}},
+		{"5.7", Capabilities{PerformanceSchema: true}},
+		{"10.6.16-MariaDB", Capabilities{MariaDB: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			capabilities, err := GetCapabilities(tt.version)
+			require.NoError(t, err)
+			tt.expected.Version = tt.version
+			assert.Equal(t, tt.expected, capabilities)
+		})
+	}
+
+	for _, version := range []string{"5.6.51", "5.1.73-MariaDB"} {
+		_, err := GetCapabilities(version)
+		assert.ErrorIs(t, err, ErrUnsupportedMySQLVersion)
+	}
+}
+
+func TestParseServerVersion(t *testing.T) {
+	version, err := parseServerVersion("8.0.23")
+	assert.NoError(t, err)
+	assert.Equal(t, serverVersion{8, 0, 23}, version)
+
+	version, err = parseServerVersion("10.11.6-MariaDB-1:10.11.6+maria~ubu2204-log")
+	assert.NoError(t, err)
+	assert.Equal(t, serverVersion{10, 11, 6}, version)
+
+	version, err = parseServerVersion("8.4")
+	assert.NoError(t, err)
+	assert.Equal(t, serverVersion{8, 4, 0}, version)
+
+	for _, invalid := range []string{"5", "invalid.version", ""} {
+		_, err = parseServerVersion(invalid)
+		assert.ErrorIs(t, err, ErrImproperlyFormattedVersion)
+	}
+}
@@ -3,7 +3,6 @@ package validator
 import (
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 
 	"github.com/newrelic/infra-integrations-sdk/v3/log"
@@ -53,30 +52,39 @@ type ConsumerStatus struct {
 	Enabled string `db:"ENABLED"`
 }
 
-// ValidatePreconditions checks if the necessary preconditions are met for performance monitoring.
-func ValidatePreconditions(db utils.DataSource) error {
+/*
+ValidatePreconditions checks if the necessary preconditions are met for performance monitoring, and returns the
+capabilities of the server the collectors are chosen from.
+*/
+func ValidatePreconditions(db utils.DataSource) (Capabilities, error) {
 	// Get the MySQL version
 	version, err := getMySQLVersion(db)
 	if err != nil {
 		log.Error("Failed to get MySQL version: %v", err)
-		return err
+		return Capabilities{}, err
 	}
 
 	// Check if the MySQL version is supported
-	if !isVersion8OrGreater(version) {
-		log.Error("MySQL version %s is not supported. Only version 8.0+ is supported.", version)
-		return fmt.Errorf("%w: MySQL version %s is not supported. Only version 8.0+ is supported", ErrUnsupportedMySQLVersion, version)
+	capabilities, err := GetCapabilities(version)
+	if err != nil {
+		log.Error("MySQL version %s is not supported. Only version 5.7+ is supported.", version)
+		return Capabilities{}, err
+	}
+
+	// The performance_schema isn't required when only the InnoDB tables are queried
+	if !capabilities.PerformanceSchema {
+		return capabilities, nil
 	}
 
 	// Check if Performance Schema is enabled
 	performanceSchemaEnabled, errPerformanceEnabled := isPerformanceSchemaEnabled(db)
 	if errPerformanceEnabled != nil {
-		return errPerformanceEnabled
+		return Capabilities{}, errPerformanceEnabled
 	}
 
 	if !performanceSchemaEnabled {
-		logEnablePerformanceSchemaInstructions(version)
-		return ErrPerformanceSchemaDisabled
+		logEnablePerformanceSchemaInstructions()
+		return Capabilities{}, ErrPerformanceSchemaDisabled
 	}
 
 	// Check if essential consumers are enabled
@@ -84,7 +92,7 @@ func ValidatePreconditions(db utils.DataSource) error {
 	if errEssentialConsumers != nil {
 		log.Warn("Essential consumer check failed: %v", errEssentialConsumers)
 	}
-	return nil
+	return capabilities, nil
 }
 
 // isPerformanceSchemaEnabled checks if the Performance Schema is enabled in the MySQL database.
@@ -223,13 +231,9 @@ func checkAndEnableEssentialConsumers(db utils.DataSource) error {
 }
 
 // logEnablePerformanceSchemaInstructions logs instructions to enable the Performance Schema.
-func logEnablePerformanceSchemaInstructions(version string) {
-	if isVersion8OrGreater(version) {
-		log.Debug("To enable the Performance Schema, add the following lines to your MySQL configuration file (my.cnf or my.ini) in the [mysqld] section and restart the MySQL server:")
-		log.Debug("performance_schema=ON")
-	} else {
-		log.Error("MySQL version %s is not supported. Only version 8.0+ is supported.", version)
-	}
+func logEnablePerformanceSchemaInstructions() {
+	log.Debug("To enable the Performance Schema, add the following lines to your MySQL configuration file (my.cnf or my.ini) in the [mysqld] section and restart the MySQL server:")
+	log.Debug("performance_schema=ON")
 }
 
 // getMySQLVersion retrieves the MySQL version from the database.
@@ -254,30 +258,9 @@ func getMySQLVersion(db utils.DataSource) (string, error) {
 	return version, nil
 }
 
-// isVersion8OrGreater checks if the MySQL version is 8.0 or greater.
-func isVersion8OrGreater(version string) bool {
-	majorVersion, err := extractMajorFromVersion(version)
-	if err != nil {
-		log.Error("Failed to extract major version: %v", err)
-		return false
-	}
-	return (majorVersion >= 8)
-}
-
-// extractMajorFromVersion extracts the major version number from a version string.
-func extractMajorFromVersion(version string) (int, error) {
-	parts := strings.Split(version, ".")
-	if len(parts) < constants.MinVersionParts {
-		return 0, ErrImproperlyFormattedVersion
-	}
-
-	majorVersion, err := strconv.Atoi(parts[0])
-	if err != nil {
-		log.Error("Failed to parse major version '%s': %v", parts[0], err)
-		return 0, err
-	}
-
-	return majorVersion, nil
+// isMariaDB checks if the version is the one of a MariaDB server, e.g. 10.6.16-MariaDB.
+func isMariaDB(version string) bool {
+	return strings.Contains(strings.ToLower(version), "mariadb")
 }
 
 // buildConsumerStatusQuery constructs a SQL query to check the status of essential consumers
@@ -329,3 +312,47 @@ func GetValidQueryCountThreshold(threshold int) int {
 	}
 	return threshold
 }
+
+// GetValidAnalyzeTimeLimit validates and returns the appropriate value
+func GetValidAnalyzeTimeLimit(timeLimit int) int {
+	if timeLimit <= 0 {
+		log.Warn("Analyze time limit is not positive, setting to default value: %d", constants.DefaultAnalyzeTimeLimit)
+		return constants.DefaultAnalyzeTimeLimit
+	} else if timeLimit > constants.MaxAnalyzeTimeLimit {
+		log.Warn("Analyze time limit is greater than max supported value, setting to max supported value: %d", constants.MaxAnalyzeTimeLimit)
+		return constants.MaxAnalyzeTimeLimit
+	}
+	return timeLimit
+}
+
+// GetValidTrxAgeThreshold validates and returns the appropriate value
+func GetValidTrxAgeThreshold(threshold int) int {
+	if threshold < 0 {
+		log.Warn("Transaction age threshold is negative, setting to default value: %d", constants.DefaultTrxAgeThreshold)
+		return constants.DefaultTrxAgeThreshold
+	}
+	return threshold
+}
+
+// GetValidTrxIdleThreshold validates and returns the appropriate value
+func GetValidTrxIdleThreshold(threshold int) int {
+	if threshold < 0 {
+		log.Warn("Transaction idle threshold is negative, setting to default value: %d", constants.DefaultTrxIdleThreshold)
+		return constants.DefaultTrxIdleThreshold
+	}
+	return threshold
+}
+
+// GetValidWaitEventsMode validates and returns the appropriate value
+func GetValidWaitEventsMode(mode string) string {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case constants.WaitEventsModeSample, constants.WaitEventsModeDelta:
+		return mode
+	case "":
+		return constants.WaitEventsModeSample
+	default:
+		log.Warn("Unknown wait events mode %q, setting to default value: %s", mode, constants.WaitEventsModeSample)
+		return constants.WaitEventsModeSample
+	}
+}
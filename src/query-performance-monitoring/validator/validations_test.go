@@ -48,7 +48,7 @@ func TestValidatePreconditions_PerformanceSchemaDisabled(t *testing.T) {
 	mock.ExpectQuery(versionQuery).WillReturnRows(versionRows)
 	mock.ExpectQuery(performanceSchemaQuery).WillReturnRows(rows)
 
-	err = ValidatePreconditions(mockDataSource)
+	_, err = ValidatePreconditions(mockDataSource)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "performance schema is not enabled")
 
@@ -56,6 +56,35 @@ func TestValidatePreconditions_PerformanceSchemaDisabled(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestValidatePreconditions_UnsupportedVersion(t *testing.T) {
+	for _, version := range []string{"5.6.51", "5.1.73-MariaDB"} {
+		t.Run(version, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectQuery(versionQuery).WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow(version))
+			_, err = ValidatePreconditions(&mockDataSource{db: sqlx.NewDb(db, "sqlmock")})
+			assert.ErrorIs(t, err, ErrUnsupportedMySQLVersion)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestValidatePreconditions_MariaDB(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// The performance_schema isn't checked, since only the blocking sessions are collected
+	mock.ExpectQuery(versionQuery).WillReturnRows(sqlmock.NewRows([]string{"VERSION()"}).AddRow("10.6.16-MariaDB"))
+	capabilities, err := ValidatePreconditions(&mockDataSource{db: sqlx.NewDb(db, "sqlmock")})
+	assert.NoError(t, err)
+	assert.True(t, capabilities.MariaDB)
+	assert.False(t, capabilities.PerformanceSchema)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestValidatePreconditions_EssentialChecksFailed(t *testing.T) {
 	testCases := []struct {
 		name            string
@@ -85,7 +114,7 @@ func TestValidatePreconditions_EssentialChecksFailed(t *testing.T) {
 			mock.ExpectQuery(performanceSchemaQuery).WillReturnRows(performanceSchemaRows)
 			tc.expectQueryFunc(mock) // Dynamically call the query expectation function
 
-			err = ValidatePreconditions(mockDataSource)
+			_, err = ValidatePreconditions(mockDataSource)
 			if tc.assertError {
 				assert.Error(t, err)
 			} else {
@@ -366,36 +395,11 @@ func TestGetMySQLVersion(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "8.0.23", version)
 }
-func TestIsVersion8OrGreater(t *testing.T) {
-	assert.True(t, isVersion8OrGreater("8.0.23"))
-	assert.True(t, isVersion8OrGreater("8.4"))
-	assert.False(t, isVersion8OrGreater("5.7.31"))
-	assert.False(t, isVersion8OrGreater("5.6"))
-	assert.False(t, isVersion8OrGreater("5"))
-	assert.False(t, isVersion8OrGreater("invalid.version.string"))
-	assert.False(t, isVersion8OrGreater(""))
-}
-
-func TestExtractMajorFromVersion(t *testing.T) {
-	major, err := extractMajorFromVersion("8.0.23")
-	assert.NoError(t, err)
-	assert.Equal(t, 8, major)
-
-	major, err = extractMajorFromVersion("5.7.31")
-	assert.NoError(t, err)
-	assert.Equal(t, 5, major)
-
-	major, err = extractMajorFromVersion("5")
-	assert.Error(t, err)
-	assert.Equal(t, 0, major)
-
-	major, err = extractMajorFromVersion("invalid.version")
-	assert.Error(t, err)
-	assert.Equal(t, 0, major)
-
-	major, err = extractMajorFromVersion("")
-	assert.Error(t, err)
-	assert.Equal(t, 0, major)
+func TestIsMariaDB(t *testing.T) {
+	assert.True(t, isMariaDB("10.6.16-MariaDB"))
+	assert.True(t, isMariaDB("10.11.6-MariaDB-1:10.11.6+maria~ubu2204-log"))
+	assert.False(t, isMariaDB("8.0.36"))
+	assert.False(t, isMariaDB("5.7.44-log"))
 }
 
 func TestGetValidSlowQueryFetchIntervalThreshold(t *testing.T) {
@@ -456,3 +460,38 @@ func TestGetValidQueryCountThreshold(t *testing.T) {
 		})
 	}
 }
+
+func TestGetValidAnalyzeTimeLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		timeLimit int
+		expected  int
+	}{
+		{"Negative time limit", -1, constants.DefaultAnalyzeTimeLimit},
+		{"Zero time limit", 0, constants.DefaultAnalyzeTimeLimit},
+		{"Time limit greater than max", constants.MaxAnalyzeTimeLimit + 1, constants.MaxAnalyzeTimeLimit},
+		{"Positive time limit", 200, 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := GetValidAnalyzeTimeLimit(tt.timeLimit)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestGetValidTrxThresholds(t *testing.T) {
+	assert.Equal(t, constants.DefaultTrxAgeThreshold, GetValidTrxAgeThreshold(-1))
+	assert.Equal(t, 0, GetValidTrxAgeThreshold(0))
+	assert.Equal(t, 600, GetValidTrxAgeThreshold(600))
+	assert.Equal(t, constants.DefaultTrxIdleThreshold, GetValidTrxIdleThreshold(-5))
+	assert.Equal(t, 10, GetValidTrxIdleThreshold(10))
+}
+
+func TestGetValidWaitEventsMode(t *testing.T) {
+	assert.Equal(t, constants.WaitEventsModeSample, GetValidWaitEventsMode(""))
+	assert.Equal(t, constants.WaitEventsModeSample, GetValidWaitEventsMode("sample"))
+	assert.Equal(t, constants.WaitEventsModeDelta, GetValidWaitEventsMode(" Delta "))
+	assert.Equal(t, constants.WaitEventsModeSample, GetValidWaitEventsMode("history"))
+}
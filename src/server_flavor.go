@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+)
+
+type serverFlavor string
+
+const (
+	flavorMySQL   serverFlavor = "mysql"
+	flavorMariaDB serverFlavor = "mariadb"
+	flavorPercona serverFlavor = "percona"
+	flavorAurora  serverFlavor = "aurora"
+
+	/*
+		Note: The default DB version is 5.7.0 as the earlier codebase was using
+			  replicaQueryBelowVersion8Point4 to populate the metrics irrespective of the version
+	*/
+	defaultDBVersion = "5.7.0"
+
+	// mariaDBChangeBufferRemovalMajorVersion is the MariaDB release which removed the InnoDB change buffer and its status variables
+	mariaDBChangeBufferRemovalMajorVersion = 11
+
+	// MariaDB servers prior to 11.0 may prefix the version with 5.5.5- for compatibility with old replication clients
+	mariaDBCompatibilityVersionPrefix = "5.5.5-"
+)
+
+/*
+dbServer describes the monitored server. Every flavor has its own versioning, so version-gated decisions
+must be taken on the flavor first, and then on the version:
+  - version is the flavor's own version, e.g. 10.11.6 for MariaDB or 3.04.0 for Aurora.
+  - mysqlVersion is the MySQL version the server is compatible with. It is empty for MariaDB, which has diverged from MySQL.
+*/
+type dbServer struct {
+	flavor       serverFlavor
+	version      string
+	mysqlVersion string
+}
+
+func (s dbServer) isMariaDB() bool {
+	return s.flavor == flavorMariaDB
+}
+
+// hasQueryCache reports if the server exposes the query cache status variables, which were removed in MySQL 8.0
+// but are still available in every MariaDB release.
+func (s dbServer) hasQueryCache() bool {
+	return s.isMariaDB() || isDBVersionLessThan8(s.mysqlVersion)
+}
+
+// usesReplicaTerminology reports if the server has replaced the Master/Slave terms with Source/Replica,
+// which is the case from MySQL 8.4. MariaDB keeps the Master/Slave column names in its replica status.
+func (s dbServer) usesReplicaTerminology() bool {
+	return !s.isMariaDB() && !isDBVersionLessThan8Point4(s.mysqlVersion)
+}
+
+// supportsGroupReplication reports if the server may be a member of a MySQL Group Replication / InnoDB Cluster.
+func (s dbServer) supportsGroupReplication() bool {
+	return !s.isMariaDB()
+}
+
+// hasChangeBufferStatus reports if the server exposes the Innodb_ibuf_* status variables of the InnoDB change buffer.
+// Only MariaDB has them, up to 10.11: the change buffer was removed in MariaDB 11.0.
+func (s dbServer) hasChangeBufferStatus() bool {
+	if !s.isMariaDB() {
+		return false
+	}
+	majorVersion, err := strconv.Atoi(strings.Split(s.version, ".")[0])
+	if err != nil {
+		log.Warn("Could not convert major version from str to int. Assuming MariaDB to be 11.0 or above")
+		return false
+	}
+	return majorVersion < mariaDBChangeBufferRemovalMajorVersion
+}
+
+// replicaChannelNameColumn returns the replica status column which names a replication channel.
+// MariaDB calls the channels of a multi-source replica "connections".
+func (s dbServer) replicaChannelNameColumn() string {
+	if s.isMariaDB() {
+		return "Connection_name"
+	}
+	return "Channel_Name"
+}
+
+func (s dbServer) String() string {
+	return fmt.Sprintf("%s %s", s.flavor, s.version)
+}
+
+// getDBServer detects the flavor and version of the server from `SELECT VERSION()` and the global variables.
+func getDBServer(db dataSource, inventory map[string]interface{}) dbServer {
+	rawDBVersion, err := getRawDBVersion(db)
+	if err != nil {
+		log.Warn(err.Error())
+		log.Warn("Assuming the mysql version to be less than 8.4")
+		return dbServer{flavor: flavorMySQL, version: defaultDBVersion, mysqlVersion: defaultDBVersion}
+	}
+
+	flavor := detectServerFlavor(rawDBVersion, inventory)
+	if flavor == flavorMariaDB {
+		rawDBVersion = strings.TrimPrefix(rawDBVersion, mariaDBCompatibilityVersionPrefix)
+	}
+
+	sanitizedDBVersion, err := extractSanitizedDBVersion(rawDBVersion)
+	log.Debug("sanitized version: %v", sanitizedDBVersion)
+	if err != nil {
+		log.Warn(err.Error())
+		log.Warn("Assuming the mysql version to be less than 8.4")
+		sanitizedDBVersion = defaultDBVersion
+	}
+
+	server := dbServer{flavor: flavor, version: sanitizedDBVersion, mysqlVersion: sanitizedDBVersion}
+	switch flavor {
+	case flavorMariaDB:
+		server.mysqlVersion = ""
+	case flavorAurora:
+		if auroraVersion, ok := inventory["aurora_version"]; ok {
+			server.version = fmt.Sprint(auroraVersion)
+		}
+	}
+	log.Debug("Detected the db server is %s", server)
+	return server
+}
+
+// detectServerFlavor detects the flavor of the server from the raw version and the version_comment and aurora_version variables.
+func detectServerFlavor(rawDBVersion string, inventory map[string]interface{}) serverFlavor {
+	versionComment := strings.ToLower(fmt.Sprint(inventory["version_comment"]))
+	_, hasAuroraVersion := inventory["aurora_version"]
+
+	switch {
+	case isMariaDBServer(rawDBVersion) || strings.Contains(versionComment, "mariadb"):
+		return flavorMariaDB
+	case hasAuroraVersion || strings.Contains(strings.ToLower(rawDBVersion), "aurora"):
+		return flavorAurora
+	case strings.Contains(versionComment, "percona"):
+		return flavorPercona
+	default:
+		return flavorMySQL
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDBServer(t *testing.T) {
+	tests := []struct {
+		name      string
+		mockRows  *sqlmock.Rows
+		inventory map[string]interface{}
+		expected  dbServer
+	}{
+		{
+			name:      "Successful dbVersion query",
+			mockRows:  sqlmock.NewRows([]string{"version"}).AddRow("8.0.40-0ubuntu0.22.04.1"),
+			inventory: map[string]interface{}{"version_comment": "(Ubuntu)"},
+			expected:  dbServer{flavor: flavorMySQL, version: "8.0.40", mysqlVersion: "8.0.40"},
+		},
+		{
+			name:      "dbVersion query output with mariadb",
+			mockRows:  sqlmock.NewRows([]string{"version"}).AddRow("11.3.2-MariaDB-log"),
+			inventory: map[string]interface{}{"version_comment": "mariadb.org binary distribution"},
+			expected:  dbServer{flavor: flavorMariaDB, version: "11.3.2", mysqlVersion: ""},
+		},
+		{
+			name:      "dbVersion query output with mariadb 10",
+			mockRows:  sqlmock.NewRows([]string{"version"}).AddRow("10.11.6-MariaDB-1:10.11.6+maria~ubu2204-log"),
+			inventory: map[string]interface{}{"version_comment": "mariadb.org binary distribution"},
+			expected:  dbServer{flavor: flavorMariaDB, version: "10.11.6", mysqlVersion: ""},
+		},
+		{
+			name:      "dbVersion query output with mariadb compatibility prefix",
+			mockRows:  sqlmock.NewRows([]string{"version"}).AddRow("5.5.5-10.6.16-MariaDB"),
+			inventory: map[string]interface{}{},
+			expected:  dbServer{flavor: flavorMariaDB, version: "10.6.16", mysqlVersion: ""},
+		},
+		{
+			name:      "Percona server",
+			mockRows:  sqlmock.NewRows([]string{"version"}).AddRow("8.0.35-27"),
+			inventory: map[string]interface{}{"version_comment": "Percona Server (GPL), Release 27, Revision 2f8eeab2"},
+			expected:  dbServer{flavor: flavorPercona, version: "8.0.35", mysqlVersion: "8.0.35"},
+		},
+		{
+			name:      "Aurora server",
+			mockRows:  sqlmock.NewRows([]string{"version"}).AddRow("8.0.mysql_aurora.3.04.0"),
+			inventory: map[string]interface{}{"aurora_version": "3.04.0"},
+			expected:  dbServer{flavor: flavorAurora, version: "3.04.0", mysqlVersion: "8.0.0"},
+		},
+		{
+			name:      "Error exec dbVersion query",
+			mockRows:  nil,
+			inventory: map[string]interface{}{},
+			expected:  dbServer{flavor: flavorMySQL, version: "5.7.0", mysqlVersion: "5.7.0"},
+		},
+		{
+			name:      "Version not found in result",
+			mockRows:  sqlmock.NewRows([]string{""}).AddRow(nil),
+			inventory: map[string]interface{}{},
+			expected:  dbServer{flavor: flavorMySQL, version: "5.7.0", mysqlVersion: "5.7.0"},
+		},
+		{
+			name:      "Invalid dbVersion output",
+			mockRows:  sqlmock.NewRows([]string{"version"}).AddRow("invalid"),
+			inventory: map[string]interface{}{},
+			expected:  dbServer{flavor: flavorMySQL, version: "5.7.0", mysqlVersion: "5.7.0"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			assert.NoError(t, err)
+			defer db.Close()
+
+			database := &database{source: db}
+			if test.mockRows != nil {
+				mock.ExpectQuery(dbVersionQuery).WillReturnRows(test.mockRows)
+			} else {
+				mock.ExpectQuery(dbVersionQuery).WillReturnError(assert.AnError)
+			}
+
+			actual := getDBServer(database, test.inventory)
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestDetectServerFlavor(t *testing.T) {
+	tests := []struct {
+		name         string
+		rawDBVersion string
+		inventory    map[string]interface{}
+		expected     serverFlavor
+	}{
+		{"MySQL 5.7", "5.7.44-log", map[string]interface{}{"version_comment": "MySQL Community Server (GPL)"}, flavorMySQL},
+		{"MySQL 8.0", "8.0.40-0ubuntu0.22.04.1", map[string]interface{}{"version_comment": "(Ubuntu)"}, flavorMySQL},
+		{"MySQL 8.4 without version comment", "8.4.3", map[string]interface{}{}, flavorMySQL},
+		{"MariaDB 10", "10.6.16-MariaDB", map[string]interface{}{}, flavorMariaDB},
+		{"MariaDB 10 with compatibility prefix", "5.5.5-10.6.16-MariaDB", map[string]interface{}{}, flavorMariaDB},
+		{"MariaDB 11", "11.3.2-MariaDB-log", map[string]interface{}{"version_comment": "mariadb.org binary distribution"}, flavorMariaDB},
+		{"MariaDB detected from the version comment", "11.4.2", map[string]interface{}{"version_comment": "MariaDB Server"}, flavorMariaDB},
+		{"Percona 8.0", "8.0.35-27", map[string]interface{}{"version_comment": "Percona Server (GPL), Release 27, Revision 2f8eeab2"}, flavorPercona},
+		{"Percona 5.7", "5.7.44-48-log", map[string]interface{}{"version_comment": "Percona Server (GPL), Release 48, Revision 497f936a373"}, flavorPercona},
+		{"Aurora", "8.0.mysql_aurora.3.04.0", map[string]interface{}{"aurora_version": "3.04.0"}, flavorAurora},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, detectServerFlavor(test.rawDBVersion, test.inventory))
+		})
+	}
+}
+
+func TestVersionGatedDecisionsForMariaDB(t *testing.T) {
+	mariaDB := dbServer{flavor: flavorMariaDB, version: "10.11.6"}
+
+	assert.Equal(t, replicaQueryForMariaDB, getReplicaQuery(mariaDB))
+	assert.True(t, mariaDB.hasQueryCache())
+	assert.False(t, mariaDB.usesReplicaTerminology())
+	assert.False(t, mariaDB.supportsGroupReplication())
+	assert.Equal(t, "Connection_name", mariaDB.replicaChannelNameColumn())
+
+	defaultMetrics := getDefaultMetrics(mariaDB)
+	assert.Contains(t, defaultMetrics, "db.qCacheHitRatio")
+
+	extendedMetrics := getExtendedMetrics(mariaDB)
+	assert.Equal(t, "Max_statement_time_exceeded", extendedMetrics["db.maxExecutionTimeExceededPerSecond"][0])
+
+	slaveMetrics := getSlaveMetrics(mariaDB)
+	assert.Equal(t, "Seconds_Behind_Master", slaveMetrics["cluster.secondsBehindMaster"][0])
+	assert.Contains(t, slaveMetrics, "cluster.gtidSlavePos")
+
+	// MySQL keeps its own status variable names
+	assert.Equal(t, "Max_execution_time_exceeded", getExtendedMetrics(mysqlServer("8.0.40"))["db.maxExecutionTimeExceededPerSecond"][0])
+}
+
+func TestChangeBufferMetricsByMariaDBVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   dbServer
+		expected bool
+	}{
+		{"MariaDB 10.6", dbServer{flavor: flavorMariaDB, version: "10.6.16"}, true},
+		{"MariaDB 10.11", dbServer{flavor: flavorMariaDB, version: "10.11.6"}, true},
+		{"MariaDB 11.0 removed the change buffer", dbServer{flavor: flavorMariaDB, version: "11.0.2"}, false},
+		{"MariaDB 11.4", dbServer{flavor: flavorMariaDB, version: "11.4.2"}, false},
+		{"MySQL has no change buffer status variables", mysqlServer("8.0.40"), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.server.hasChangeBufferStatus())
+			innodb := getInnodbMetrics(test.server)
+			if test.expected {
+				assert.Equal(t, "Innodb_ibuf_size", innodb["db.innodb.changeBufferSizePages"][0])
+			} else {
+				assert.NotContains(t, innodb, "db.innodb.changeBufferSizePages")
+			}
+			// The other InnoDB status variables are named alike in every release
+			assert.Equal(t, "Innodb_buffer_pool_pages_dirty", innodb["db.innodb.bufferPoolPagesDirty"][0])
+		})
+	}
+}
+
+func TestGetRawDataForMariaDB(t *testing.T) {
+	database := testdb{
+		inventory: map[string]interface{}{
+			"version":         "10.11.6-MariaDB-log",
+			"version_comment": "MariaDB Server",
+		},
+		metrics: map[string]interface{}{},
+		replica: map[string]interface{}{
+			"Connection_name":   "source_1",
+			"Slave_IO_Running":  "Yes",
+			"Slave_SQL_Running": "Yes",
+		},
+		version: map[string]interface{}{"version": "10.11.6-MariaDB-log"},
+	}
+
+	_, metrics, server, err := getRawData(database)
+	assert.NoError(t, err)
+	assert.Equal(t, dbServer{flavor: flavorMariaDB, version: "10.11.6"}, server)
+	assert.Equal(t, "slave", metrics["node_type"])
+	assert.Equal(t, "mariadb", metrics["server_flavor"])
+	assert.Equal(t, "10.11.6", metrics["server_flavor_version"])
+
+	slaveRunning, ok := slaveRunningAsNumber(metrics, server)
+	assert.True(t, ok)
+	assert.Equal(t, 1, slaveRunning)
+}
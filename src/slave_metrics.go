@@ -1,7 +1,13 @@
 package main
 
 import (
+	"fmt"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/attribute"
 	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	infrautils "github.com/newrelic/nri-mysql/src/infrautils"
 )
 
 var slaveMetricsBase = map[string][]interface{}{
@@ -35,18 +41,67 @@ var slaveMetricsForVersion8Point4AndAbove = map[string][]interface{}{
 	"cluster.masterHost":          {"Source_Host", metric.ATTRIBUTE},
 }
 
-// mergeMaps merges two maps, overwriting map1 with any conflicting keys from map2.
+// MariaDB keeps the Master/Slave column names and reports the GTID position of each connection
+var slaveMetricsForMariaDB = map[string][]interface{}{
+	"cluster.gtidSlavePos": {"Gtid_Slave_Pos", metric.ATTRIBUTE},
+}
+
+// replicaChannelMetrics are reported in addition to the slave metrics for every replication channel
+var replicaChannelMetrics = map[string][]interface{}{
+	"cluster.slaveRunning": {slaveRunningAsNumber, metric.GAUGE},
+}
+
+// mergeMaps merges two maps into a new one, map2 taking precedence on any conflicting keys.
+// The metric definition maps are shared, so they must never be modified in place.
 func mergeMaps(map1, map2 map[string][]interface{}) map[string][]interface{} {
+	merged := make(map[string][]interface{}, len(map1)+len(map2))
+	for k, v := range map1 {
+		merged[k] = v
+	}
 	for k, v := range map2 {
-		map1[k] = v
+		merged[k] = v
 	}
-	return map1
+	return merged
 }
 
-func getSlaveMetrics(dbVersion string) map[string][]interface{} {
+func getSlaveMetrics(server dbServer) map[string][]interface{} {
 	// Find the first version definition that's applicable
-	if isDBVersionLessThan8Point4(dbVersion) {
-		return mergeMaps(slaveMetricsBase, slaveMetricsBelowVersion8Point4)
+	if server.isMariaDB() {
+		return mergeMaps(mergeMaps(slaveMetricsBase, slaveMetricsBelowVersion8Point4), slaveMetricsForMariaDB)
+	}
+	if server.usesReplicaTerminology() {
+		return mergeMaps(slaveMetricsBase, slaveMetricsForVersion8Point4AndAbove)
+	}
+	return mergeMaps(slaveMetricsBase, slaveMetricsBelowVersion8Point4)
+}
+
+func getReplicaChannelMetrics(server dbServer) map[string][]interface{} {
+	return mergeMaps(getSlaveMetrics(server), replicaChannelMetrics)
+}
+
+// populateReplicaChannelMetrics reports a MysqlReplicaChannelSample for every replication channel of a replica,
+// so that each source of a multi-source replica is reported separately.
+func populateReplicaChannelMetrics(e *integration.Entity, rawMetrics map[string]interface{}, server dbServer, args arguments.ArgumentList) {
+	replicaChannels, ok := rawMetrics[replicaChannelsKey].([]map[string]interface{})
+	if !ok {
+		return
+	}
+
+	channelMetrics := getReplicaChannelMetrics(server)
+	channelNameColumn := server.replicaChannelNameColumn()
+	for _, channel := range replicaChannels {
+		channelName := ""
+		if name, ok := channel[channelNameColumn]; ok {
+			channelName = fmt.Sprint(name)
+		}
+		ms := infrautils.MetricSet(
+			e,
+			"MysqlReplicaChannelSample",
+			args.Hostname,
+			args.Port,
+			args.RemoteMonitoring,
+			attribute.Attr("cluster.channelName", channelName),
+		)
+		populatePartialMetrics(ms, channel, channelMetrics, server)
 	}
-	return mergeMaps(slaveMetricsBase, slaveMetricsForVersion8Point4AndAbove)
 }
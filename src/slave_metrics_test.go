@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -60,3 +61,93 @@ func TestMergeMaps(t *testing.T) {
 		})
 	}
 }
+
+func TestSlaveRunningAsNumberWithMultipleChannels(t *testing.T) {
+	tests := []struct {
+		name     string
+		channels []map[string]interface{}
+		expected int
+		ok       bool
+	}{
+		{
+			name: "All channels running",
+			channels: []map[string]interface{}{
+				{"Channel_Name": "source_1", "Slave_IO_Running": "Yes", "Slave_SQL_Running": "Yes"},
+				{"Channel_Name": "source_2", "Slave_IO_Running": "Yes", "Slave_SQL_Running": "Yes"},
+			},
+			expected: 1,
+			ok:       true,
+		},
+		{
+			name: "Second channel stopped",
+			channels: []map[string]interface{}{
+				{"Channel_Name": "source_1", "Slave_IO_Running": "Yes", "Slave_SQL_Running": "Yes"},
+				{"Channel_Name": "source_2", "Slave_IO_Running": "No", "Slave_SQL_Running": "Yes"},
+			},
+			expected: 0,
+			ok:       true,
+		},
+		{
+			name: "Channel without thread state",
+			channels: []map[string]interface{}{
+				{"Channel_Name": "source_1", "Slave_IO_Running": "Yes", "Slave_SQL_Running": "Yes"},
+				{"Channel_Name": "source_2"},
+			},
+			expected: 0,
+			ok:       false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metrics := map[string]interface{}{
+				"Slave_IO_Running":  "Yes",
+				"Slave_SQL_Running": "Yes",
+				replicaChannelsKey:  test.channels,
+			}
+			actual, ok := slaveRunningAsNumber(metrics, mysqlServer("8.0.40"))
+			assert.Equal(t, test.expected, actual)
+			assert.Equal(t, test.ok, ok)
+		})
+	}
+}
+
+func TestPopulateReplicaChannelMetrics(t *testing.T) {
+	i, err := integration.New("test", "1.0.0")
+	assert.NoError(t, err)
+	e := i.LocalEntity()
+
+	rawMetrics := map[string]interface{}{
+		replicaChannelsKey: []map[string]interface{}{
+			{"Channel_Name": "source_1", "Replica_IO_Running": "Yes", "Replica_SQL_Running": "Yes", "Seconds_Behind_Source": 0, "Last_IO_Error": ""},
+			{"Channel_Name": "source_2", "Replica_IO_Running": "Connecting", "Replica_SQL_Running": "Yes", "Seconds_Behind_Source": 12, "Last_IO_Error": "error connecting to source"},
+		},
+	}
+	populateReplicaChannelMetrics(e, rawMetrics, mysqlServer("8.4.0"), args)
+
+	assert.Len(t, e.Metrics, 2)
+	for _, ms := range e.Metrics {
+		assert.Equal(t, "MysqlReplicaChannelSample", ms.Metrics["event_type"])
+		switch ms.Metrics["cluster.channelName"] {
+		case "source_1":
+			assert.Equal(t, float64(1), ms.Metrics["cluster.slaveRunning"])
+			assert.Equal(t, float64(0), ms.Metrics["cluster.secondsBehindMaster"])
+		case "source_2":
+			assert.Equal(t, float64(0), ms.Metrics["cluster.slaveRunning"])
+			assert.Equal(t, float64(12), ms.Metrics["cluster.secondsBehindMaster"])
+			assert.Equal(t, "error connecting to source", ms.Metrics["cluster.lastIOError"])
+		default:
+			t.Errorf("unexpected channel %v", ms.Metrics["cluster.channelName"])
+		}
+	}
+}
+
+func TestPopulateReplicaChannelMetricsWithoutChannels(t *testing.T) {
+	i, err := integration.New("test", "1.0.0")
+	assert.NoError(t, err)
+	e := i.LocalEntity()
+
+	populateReplicaChannelMetrics(e, map[string]interface{}{"node_type": "master"}, mysqlServer("8.4.0"), args)
+
+	assert.Empty(t, e.Metrics)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/integration"
+	"github.com/newrelic/infra-integrations-sdk/v3/log"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	infrautils "github.com/newrelic/nri-mysql/src/infrautils"
+	constants "github.com/newrelic/nri-mysql/src/query-performance-monitoring/constants"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+targetsConfig is the content of the file given in TARGETS_CONFIG. Each target lists the arguments which differ
+from the ones given to the integration, with the same names used in the integration config file, e.g.:
+
+	targets:
+	  - HOSTNAME: db-1.example.com
+	    USERNAME: newrelic
+	    PASSWORD: secret
+	    ENABLE_TLS: true
+	  - HOSTNAME: db-2.example.com
+	    PORT: 3307
+	    EXTENDED_METRICS: true
+	    EXCLUDED_PERFORMANCE_DATABASES: ["employees"]
+*/
+type targetsConfig struct {
+	Targets []map[string]interface{} `yaml:"targets"`
+}
+
+// loadTargets reads the targets config and returns the arguments of each target.
+// Every target is reported as a remote entity, as they are not the host the integration runs on.
+func loadTargets(path string, defaults arguments.ArgumentList) ([]arguments.ArgumentList, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading targets config %s: %w", path, err)
+	}
+
+	var config targetsConfig
+	if err = yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("error parsing targets config %s: %w", path, err)
+	}
+	if len(config.Targets) == 0 {
+		return nil, fmt.Errorf("no targets defined in %s", path)
+	}
+
+	targets := make([]arguments.ArgumentList, 0, len(config.Targets))
+	entityNames := map[string]int{}
+	for n, target := range config.Targets {
+		overrides := make(map[string]string, len(target))
+		for name, value := range target {
+			overrides[name], err = targetArgumentValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s of target #%d in %s: %w", name, n+1, path, err)
+			}
+		}
+
+		targetArgs, err := defaults.WithOverrides(overrides)
+		if err != nil {
+			return nil, fmt.Errorf("invalid target #%d in %s: %w", n+1, path, err)
+		}
+		targetArgs.TargetsConfig = ""
+		targetArgs.RemoteMonitoring = true
+
+		entityName := targetName(targetArgs)
+		if previous, ok := entityNames[entityName]; ok {
+			return nil, fmt.Errorf("targets #%d and #%d in %s both monitor %s", previous, n+1, path, entityName)
+		}
+		entityNames[entityName] = n + 1
+		targets = append(targets, targetArgs)
+	}
+	return targets, nil
+}
+
+// targetArgumentValue returns the value of an argument as it would be given in an environment variable.
+// Lists and maps are given as JSON, e.g. for EXCLUDED_PERFORMANCE_DATABASES.
+func targetArgumentValue(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case []interface{}, map[string]interface{}:
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	case nil:
+		return "", nil
+	default:
+		return fmt.Sprint(value), nil
+	}
+}
+
+func targetName(args arguments.ArgumentList) string {
+	return fmt.Sprint(args.Hostname, ":", args.Port)
+}
+
+// lockedWriter serializes the writes of the integrations of the targets, so their payloads are not interleaved.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
+
+/*
+newTargetIntegration creates the integration a target is reported with. Each target has its own integration,
+so it can be published independently of the others, and its own store, so the rates and deltas of a target
+are not computed from the values of another one.
+
+The integration is given the SDK arguments of the target (pretty, verbose, metadata, nri_cluster...). As the SDK
+defines and parses its flags whenever it is given arguments, they are parsed from a command line of their own,
+built from the target's SDK arguments, on a flag set of their own, so the flags of the process are left untouched.
+*/
+func newTargetIntegration(args arguments.ArgumentList, w io.Writer) (*integration.Integration, error) {
+	store, err := infrautils.NewStore(args, "metrics")
+	if err != nil {
+		return nil, err
+	}
+
+	commandLine, processArgs := flag.CommandLine, os.Args
+	flag.CommandLine = flag.NewFlagSet(commandLine.Name(), flag.ContinueOnError)
+	flag.CommandLine.SetOutput(io.Discard)
+	os.Args = append([]string{processArgs[0]}, args.SDKCommandLine()...)
+	defer func() {
+		flag.CommandLine, os.Args = commandLine, processArgs
+	}()
+
+	sdkArgs := args.DefaultArgumentList
+	return integration.New(constants.IntegrationName, integrationVersion, integration.Args(&sdkArgs), integration.Writer(w), integration.Storer(store))
+}
+
+// collectTargets collects every target with at most maxConcurrent targets being collected at the same time.
+// A target which can't be collected is logged and doesn't prevent the others from being reported.
+func collectTargets(targets []arguments.ArgumentList, maxConcurrent int) {
+	w := &lockedWriter{w: os.Stdout}
+	integrations := make([]*integration.Integration, len(targets))
+	for n, target := range targets {
+		// Integrations are created before starting collecting, since creating one swaps the command line flags.
+		i, err := newTargetIntegration(target, w)
+		if err != nil {
+			log.Error("Error creating integration for %s: %v", targetName(target), err)
+			continue
+		}
+		integrations[n] = i
+	}
+
+	runConcurrently(len(targets), maxConcurrent, func(n int) {
+		if integrations[n] == nil {
+			return
+		}
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("Error collecting %s: %v", targetName(targets[n]), r)
+			}
+		}()
+		if err := collect(integrations[n], targets[n]); err != nil {
+			log.Error("Error collecting %s: %v", targetName(targets[n]), err)
+		}
+	})
+}
+
+// runConcurrently runs task for every index in [0, count) with a pool of at most maxConcurrent workers.
+func runConcurrently(count, maxConcurrent int, task func(n int)) {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	if maxConcurrent > count {
+		maxConcurrent = count
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < maxConcurrent; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range indexes {
+				task(n)
+			}
+		}()
+	}
+	for n := 0; n < count; n++ {
+		indexes <- n
+	}
+	close(indexes)
+	wg.Wait()
+}
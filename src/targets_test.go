@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	arguments "github.com/newrelic/nri-mysql/src/args"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTargetsConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "targets.yml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadTargets(t *testing.T) {
+	defaults := arguments.ArgumentList{
+		Hostname:                     "localhost",
+		Port:                         3306,
+		Username:                     "newrelic",
+		Password:                     "secret",
+		ExcludedPerformanceDatabases: "[]",
+		TargetsConfig:                "targets.yml",
+	}
+	path := writeTargetsConfig(t, `
+targets:
+  - HOSTNAME: db-1.example.com
+    ENABLE_TLS: true
+  - hostname: db-2.example.com
+    PORT: 3307
+    PASSWORD: other
+    extended_metrics: true
+    EXCLUDED_PERFORMANCE_DATABASES: ["employees"]
+`)
+	targets, err := loadTargets(path, defaults)
+	assert.NoError(t, err)
+	assert.Len(t, targets, 2)
+
+	assert.Equal(t, "db-1.example.com", targets[0].Hostname)
+	assert.Equal(t, 3306, targets[0].Port)
+	assert.Equal(t, "secret", targets[0].Password)
+	assert.True(t, targets[0].EnableTLS)
+	assert.False(t, targets[0].ExtendedMetrics)
+
+	assert.Equal(t, "db-2.example.com", targets[1].Hostname)
+	assert.Equal(t, 3307, targets[1].Port)
+	assert.Equal(t, "other", targets[1].Password)
+	assert.True(t, targets[1].ExtendedMetrics)
+	assert.Equal(t, `["employees"]`, targets[1].ExcludedPerformanceDatabases)
+
+	for _, target := range targets {
+		assert.True(t, target.RemoteMonitoring)
+		assert.Equal(t, "newrelic", target.Username)
+		assert.Empty(t, target.TargetsConfig)
+	}
+	assert.Equal(t, "localhost", defaults.Hostname)
+}
+
+func TestLoadTargetsErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{"no targets", "targets: []\n"},
+		{"unknown argument", "targets:\n  - HOSTNAME: db-1\n    UNKNOWN: 1\n"},
+		{"invalid value", "targets:\n  - HOSTNAME: db-1\n    PORT: default\n"},
+		{"duplicated target", "targets:\n  - HOSTNAME: db-1\n  - HOSTNAME: db-1\n    PORT: 3306\n"},
+		{"invalid yaml", "targets: [\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := loadTargets(writeTargetsConfig(t, tc.content), arguments.ArgumentList{Port: 3306})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRunConcurrentlyIsBounded(t *testing.T) {
+	var running, maxRunning int32
+	var mu sync.Mutex
+	done := map[int]bool{}
+
+	runConcurrently(20, 3, func(n int) {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			previous := atomic.LoadInt32(&maxRunning)
+			if current <= previous || atomic.CompareAndSwapInt32(&maxRunning, previous, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+
+		mu.Lock()
+		done[n] = true
+		mu.Unlock()
+	})
+
+	assert.Len(t, done, 20)
+	assert.LessOrEqual(t, maxRunning, int32(3))
+}
+
+func TestCollectTargetsIsolatesUnreachableTargets(t *testing.T) {
+	targets := []arguments.ArgumentList{
+		{Hostname: "127.0.0.1", Port: 1, Username: "root", ExtraConnectionURLArgs: "timeout=100ms"},
+		{Hostname: "127.0.0.1", Port: 2, Username: "root", ExtraConnectionURLArgs: "timeout=100ms"},
+	}
+	for n := range targets {
+		targets[n].TempDir = t.TempDir()
+		targets[n].RemoteMonitoring = true
+	}
+
+	// Neither target is reachable, both are attempted without aborting the process
+	collectTargets(targets, 2)
+}
+
+func TestNewTargetIntegrationKeepsSDKArguments(t *testing.T) {
+	target := arguments.ArgumentList{Hostname: "db-1.example.com", Port: 3306, RemoteMonitoring: true}
+	target.TempDir = t.TempDir()
+	target.Pretty = true
+	target.NriCluster = "production"
+	processArgs := os.Args
+
+	var buf bytes.Buffer
+	i, err := newTargetIntegration(target, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, processArgs, os.Args)
+
+	e, err := i.Entity(targetName(target), "node")
+	require.NoError(t, err)
+	require.NoError(t, e.NewMetricSet("MysqlSample").SetMetric("db.connectionsPerSecond", 1, metric.GAUGE))
+	require.NoError(t, i.Publish())
+
+	assert.Contains(t, buf.String(), "\n\t\"name\": \"com.newrelic.mysql\"")
+	assert.Contains(t, buf.String(), "\"cluster_name\": \"production\"")
+}
+
+func TestLockedWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &lockedWriter{w: &buf}
+	runConcurrently(10, 5, func(int) {
+		_, err := w.Write([]byte("payload\n"))
+		assert.NoError(t, err)
+	})
+	assert.Equal(t, 10*len("payload\n"), buf.Len())
+}
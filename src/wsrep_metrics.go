@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+)
+
+// wsrepMetrics are reported by Galera based clusters (MariaDB Galera Cluster, Percona XtraDB Cluster) through the wsrep_* status variables.
+var wsrepMetrics = map[string][]interface{}{
+	"db.wsrep.flowControlPaused":            {"wsrep_flow_control_paused", metric.GAUGE},
+	"db.wsrep.flowControlPausedNsPerSecond": {"wsrep_flow_control_paused_ns", metric.PRATE},
+	"db.wsrep.flowControlSentPerSecond":     {"wsrep_flow_control_sent", metric.PRATE},
+	"db.wsrep.flowControlRecvPerSecond":     {"wsrep_flow_control_recv", metric.PRATE},
+	"db.wsrep.localRecvQueue":               {"wsrep_local_recv_queue", metric.GAUGE},
+	"db.wsrep.localRecvQueueAvg":            {"wsrep_local_recv_queue_avg", metric.GAUGE},
+	"db.wsrep.localSendQueue":               {"wsrep_local_send_queue", metric.GAUGE},
+	"db.wsrep.localSendQueueAvg":            {"wsrep_local_send_queue_avg", metric.GAUGE},
+	"db.wsrep.certDepsDistance":             {"wsrep_cert_deps_distance", metric.GAUGE},
+	"db.wsrep.localCertFailuresPerSecond":   {"wsrep_local_cert_failures", metric.PRATE},
+	"db.wsrep.localBfAbortsPerSecond":       {"wsrep_local_bf_aborts", metric.PRATE},
+	"db.wsrep.localCommitsPerSecond":        {"wsrep_local_commits", metric.PRATE},
+	"db.wsrep.replicatedPerSecond":          {"wsrep_replicated", metric.PRATE},
+	"db.wsrep.replicatedBytesPerSecond":     {"wsrep_replicated_bytes", metric.PRATE},
+	"db.wsrep.receivedPerSecond":            {"wsrep_received", metric.PRATE},
+	"db.wsrep.receivedBytesPerSecond":       {"wsrep_received_bytes", metric.PRATE},
+	"db.wsrep.applyWindow":                  {"wsrep_apply_window", metric.GAUGE},
+	"db.wsrep.commitWindow":                 {"wsrep_commit_window", metric.GAUGE},
+	"db.wsrep.clusterSize":                  {"wsrep_cluster_size", metric.GAUGE},
+	"db.wsrep.clusterStatus":                {"wsrep_cluster_status", metric.ATTRIBUTE},
+	"db.wsrep.localState":                   {"wsrep_local_state", metric.GAUGE},
+	"db.wsrep.localStateComment":            {"wsrep_local_state_comment", metric.ATTRIBUTE},
+	"db.wsrep.ready":                        {"wsrep_ready", metric.ATTRIBUTE},
+	"db.wsrep.connected":                    {"wsrep_connected", metric.ATTRIBUTE},
+}
+
+// isWsrepEnabled checks the wsrep_on variable, which is set on every node of a Galera based cluster.
+func isWsrepEnabled(rawData map[string]interface{}) bool {
+	wsrepOn, ok := rawData["wsrep_on"]
+	if !ok || wsrepOn == nil {
+		return false
+	}
+	switch strings.ToUpper(fmt.Sprint(wsrepOn)) {
+	case "ON", "1", "TRUE":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/newrelic/infra-integrations-sdk/v3/data/metric"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsWsrepEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawData  map[string]interface{}
+		expected bool
+	}{
+		{"wsrep_on ON", map[string]interface{}{"wsrep_on": "ON"}, true},
+		{"wsrep_on lower case", map[string]interface{}{"wsrep_on": "on"}, true},
+		{"wsrep_on numeric", map[string]interface{}{"wsrep_on": 1}, true},
+		{"wsrep_on OFF", map[string]interface{}{"wsrep_on": "OFF"}, false},
+		{"wsrep_on nil", map[string]interface{}{"wsrep_on": nil}, false},
+		{"wsrep_on missing", map[string]interface{}{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, isWsrepEnabled(test.rawData))
+		})
+	}
+}
+
+func TestGetRawDataForGaleraNode(t *testing.T) {
+	database := testdb{
+		inventory: map[string]interface{}{
+			"version":  "10.11.6-MariaDB",
+			"wsrep_on": "ON",
+		},
+		metrics: map[string]interface{}{
+			"wsrep_cluster_size": 3,
+		},
+		replica: map[string]interface{}{},
+		version: map[string]interface{}{"version": "10.11.6-MariaDB"},
+	}
+
+	_, metrics, _, err := getRawData(database)
+	assert.NoError(t, err)
+	assert.Equal(t, "galera", metrics["node_type"])
+	assert.Equal(t, "ON", metrics["wsrep_on"])
+}
+
+func TestPopulateWsrepMetrics(t *testing.T) {
+	rawMetrics := map[string]interface{}{
+		"wsrep_flow_control_paused": 0.25,
+		"wsrep_local_recv_queue":    4,
+		"wsrep_local_send_queue":    1,
+		"wsrep_cert_deps_distance":  12.5,
+		"wsrep_cluster_size":        3,
+		"wsrep_cluster_status":      "Primary",
+		"wsrep_local_state":         4,
+		"wsrep_local_state_comment": "Synced",
+	}
+	ms := metric.NewSet("MysqlSample", nil)
+	populatePartialMetrics(ms, rawMetrics, wsrepMetrics, mysqlServer("5.7.0"))
+
+	assert.Equal(t, 0.25, ms.Metrics["db.wsrep.flowControlPaused"])
+	assert.Equal(t, float64(4), ms.Metrics["db.wsrep.localRecvQueue"])
+	assert.Equal(t, float64(1), ms.Metrics["db.wsrep.localSendQueue"])
+	assert.Equal(t, 12.5, ms.Metrics["db.wsrep.certDepsDistance"])
+	assert.Equal(t, float64(3), ms.Metrics["db.wsrep.clusterSize"])
+	assert.Equal(t, "Primary", ms.Metrics["db.wsrep.clusterStatus"])
+	assert.Equal(t, float64(4), ms.Metrics["db.wsrep.localState"])
+	assert.Equal(t, "Synced", ms.Metrics["db.wsrep.localStateComment"])
+}
@@ -276,3 +276,41 @@ func TestPerfMySQLIntegrationValidArguments(t *testing.T) {
 		runValidMysqlPerfConfigTest(t, testCase.args, testCase.outputMetricsFile, testCase.name)
 	}
 }
+
+/*
+TestPerfExplainDataModifyingStatementsInReadOnlyTransaction checks that the data-modifying statements whose plans can be
+fetched are explained within a read-only transaction, like the integration does, without failing with
+ER_CANT_EXECUTE_IN_READ_ONLY_TRANSACTION (1792), and that explaining them leaves the data unchanged.
+*/
+func TestPerfExplainDataModifyingStatementsInReadOnlyTransaction(t *testing.T) {
+	statements := map[string]string{
+		"UPDATE":        "UPDATE salaries SET salary = salary + 1 WHERE emp_no = 10002",
+		"DELETE":        "DELETE t FROM titles t JOIN employees e ON e.emp_no = t.emp_no WHERE e.emp_no = 10002",
+		"INSERT_SELECT": "INSERT INTO dept_manager (emp_no, dept_no, from_date, to_date) SELECT emp_no, dept_no, from_date, to_date FROM dept_emp WHERE emp_no = 10002",
+	}
+	countQuery := "SELECT (SELECT SUM(salary) FROM employees.salaries WHERE emp_no = 10002), " +
+		"(SELECT COUNT(*) FROM employees.titles WHERE emp_no = 10002), (SELECT COUNT(*) FROM employees.dept_manager);"
+
+	for _, mysqlPerfConfig := range MysqlPerfConfigs {
+		t.Run(mysqlPerfConfig.Version, func(t *testing.T) {
+			countCmd := []string{`mysql`, `-u`, `root`, `-N`, `-e`, countQuery}
+			countsBefore, stderr, err := helpers.ExecInContainer(mysqlPerfConfig.Hostname, countCmd, fmt.Sprintf("MYSQL_PWD=%s", *psw))
+			require.NoError(t, err, stderr)
+
+			for name, statement := range statements {
+				t.Run(name, func(t *testing.T) {
+					explainQuery := fmt.Sprintf("USE employees; START TRANSACTION READ ONLY; EXPLAIN FORMAT=JSON %s; ROLLBACK;", statement)
+					explainCmd := []string{`mysql`, `-u`, `root`, `-N`, `-e`, explainQuery}
+					stdout, stderr, err := helpers.ExecInContainer(mysqlPerfConfig.Hostname, explainCmd, fmt.Sprintf("MYSQL_PWD=%s", *psw))
+					require.NoError(t, err, stderr)
+					assert.NotContains(t, stderr, "ERROR")
+					assert.Contains(t, stdout, "query_block", "The plan of the statement should be returned")
+				})
+			}
+
+			countsAfter, stderr, err := helpers.ExecInContainer(mysqlPerfConfig.Hostname, countCmd, fmt.Sprintf("MYSQL_PWD=%s", *psw))
+			require.NoError(t, err, stderr)
+			assert.Equal(t, countsBefore, countsAfter, "Explaining the statements shouldn't modify the data")
+		})
+	}
+}